@@ -0,0 +1,90 @@
+// Package observability sets up the structured logging and tracing shared by the services and
+// the proxy of the network.
+//
+// Logs are written with log/slog to stderr, as JSON unless LOG_FORMAT=text, from LOG_LEVEL
+// (debug, info, warn or error; info by default). The stdlib log package, still used by some
+// libraries, is redirected to the same handler at the info level. Records logged with a context
+// carry the trace_id and span_id of its span; WithTrace does the same for logr loggers such as the
+// proxy's.
+//
+// Traces are exported with OTLP over gRPC when OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set, e.g. http://otel-collector:4317 for a local collector;
+// the other standard OTEL_* variables (OTEL_SERVICE_NAME, OTEL_TRACES_SAMPLER, ...) apply.
+// Trace context is propagated with the W3C traceparent and baggage headers, in gRPC metadata
+// (GRPCServerOption, GRPCDialOption), HTTP headers (HTTPMiddleware) and NATS message headers
+// (StartPublish, StartReceive).
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SetupLogging installs the default slog logger of service, configured from the environment,
+// and returns it.
+func SetupLogging(service string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(os.Getenv("LOG_LEVEL"))}
+	var handler slog.Handler
+	if strings.EqualFold(os.Getenv("LOG_FORMAT"), "text") {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	logger := slog.New(traceHandler{handler}).With("service", service)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// Logr returns a logr.Logger writing to logger, for libraries logging through logr.
+func Logr(logger *slog.Logger) logr.Logger {
+	return logr.FromSlogHandler(logger.Handler())
+}
+
+func parseLevel(value string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// traceHandler adds the trace and span IDs of the record's context.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
+}
+
+// WithTrace adds the trace and span IDs of ctx to log, so that log lines can be matched to spans.
+func WithTrace(ctx context.Context, log logr.Logger) logr.Logger {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return log.WithValues("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+	}
+	return log
+}
+
+// TraceID returns the ID of the trace ctx belongs to, or "" outside of a sampled or remote trace.
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
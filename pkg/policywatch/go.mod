@@ -0,0 +1,18 @@
+module github.com/bafbi/minecraft-network/pkg/policywatch
+
+go 1.23.2
+
+require (
+	github.com/casbin/casbin/v2 v2.105.0
+	github.com/nats-io/nats.go v1.41.1
+)
+
+require (
+	github.com/bmatcuk/doublestar/v4 v4.6.1 // indirect
+	github.com/casbin/govaluate v1.3.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/nats-io/nkeys v0.4.9 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	golang.org/x/crypto v0.31.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+)
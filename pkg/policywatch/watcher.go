@@ -0,0 +1,222 @@
+// Package policywatch keeps the Casbin enforcers of several replicas in sync over NATS.
+//
+// Every policy change made through the enforcer's management API is published as a
+// structured delta (PolicyUpdate). Other replicas apply the delta to their in-memory model
+// only: the replica making the change already saved it to the shared adapter.
+package policywatch
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/nats-io/nats.go"
+)
+
+// Operations carried by a PolicyUpdate.
+const (
+	OpAddPolicies          = "add"
+	OpRemovePolicies       = "remove"
+	OpRemoveFilteredPolicy = "remove_filtered"
+	OpReload               = "reload" // Full reload from the adapter (SavePolicy, legacy notifications)
+)
+
+// PolicyUpdate is the message published on the policy update subject.
+type PolicyUpdate struct {
+	Origin      string     `json:"origin"`                 // ID of the publishing watcher, used to ignore our own messages
+	Op          string     `json:"op"`                     // One of the Op* constants
+	Sec         string     `json:"sec,omitempty"`          // Model section, e.g. "p" or "g"
+	Ptype       string     `json:"ptype,omitempty"`        // Policy type, e.g. "p" or "g2"
+	Rules       [][]string `json:"rules,omitempty"`        // Rules added or removed
+	FieldIndex  int        `json:"field_index,omitempty"`  // For OpRemoveFilteredPolicy
+	FieldValues []string   `json:"field_values,omitempty"` // For OpRemoveFilteredPolicy
+}
+
+// Options configures a NATSWatcher.
+type Options struct {
+	Logger *slog.Logger // slog.Default() when nil
+	// ObservePublish, when set, is called after every publish with the time it started,
+	// e.g. to record its duration.
+	ObservePublish func(subject string, start time.Time)
+}
+
+// NATSWatcher implements persist.WatcherEx on top of a NATS subject.
+type NATSWatcher struct {
+	nc       *nats.Conn
+	subject  string
+	origin   string
+	opts     Options
+	sub      *nats.Subscription
+	mu       sync.RWMutex
+	callback func(string)
+}
+
+var _ persist.WatcherEx = (*NATSWatcher)(nil)
+
+// NewNATSWatcher subscribes to subject and returns a watcher publishing to it.
+// Messages published by this watcher are not passed to the update callback.
+func NewNATSWatcher(nc *nats.Conn, subject string, opts Options) (*NATSWatcher, error) {
+	if nc == nil {
+		return nil, fmt.Errorf("NATS connection is nil")
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	originBytes := make([]byte, 8)
+	if _, err := rand.Read(originBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate watcher ID: %w", err)
+	}
+
+	w := &NATSWatcher{nc: nc, subject: subject, origin: hex.EncodeToString(originBytes), opts: opts}
+	sub, err := nc.Subscribe(subject, w.handleMessage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+	w.sub = sub
+	return w, nil
+}
+
+func (w *NATSWatcher) handleMessage(msg *nats.Msg) {
+	var update PolicyUpdate
+	if err := json.Unmarshal(msg.Data, &update); err == nil && update.Origin == w.origin {
+		return // Our own change, already applied locally
+	}
+
+	w.mu.RLock()
+	callback := w.callback
+	w.mu.RUnlock()
+	if callback != nil {
+		callback(string(msg.Data))
+	}
+}
+
+// SetUpdateCallback sets the function called with the raw payload of every policy update
+// published by other instances. Use DefaultUpdateCallback to apply the deltas to an enforcer.
+func (w *NATSWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+// Update asks other instances to reload the whole policy.
+func (w *NATSWatcher) Update() error {
+	return w.publish(PolicyUpdate{Op: OpReload})
+}
+
+// Close unsubscribes from the update subject. The NATS connection is left open.
+func (w *NATSWatcher) Close() {
+	if w.sub != nil {
+		if err := w.sub.Unsubscribe(); err != nil {
+			w.opts.Logger.Error("Failed to unsubscribe policy watcher", "subject", w.subject, "error", err)
+		}
+	}
+}
+
+func (w *NATSWatcher) UpdateForAddPolicy(sec, ptype string, params ...string) error {
+	return w.publish(PolicyUpdate{Op: OpAddPolicies, Sec: sec, Ptype: ptype, Rules: [][]string{params}})
+}
+
+func (w *NATSWatcher) UpdateForRemovePolicy(sec, ptype string, params ...string) error {
+	return w.publish(PolicyUpdate{Op: OpRemovePolicies, Sec: sec, Ptype: ptype, Rules: [][]string{params}})
+}
+
+func (w *NATSWatcher) UpdateForRemoveFilteredPolicy(sec, ptype string, fieldIndex int, fieldValues ...string) error {
+	return w.publish(PolicyUpdate{Op: OpRemoveFilteredPolicy, Sec: sec, Ptype: ptype, FieldIndex: fieldIndex, FieldValues: fieldValues})
+}
+
+func (w *NATSWatcher) UpdateForSavePolicy(_ model.Model) error {
+	return w.publish(PolicyUpdate{Op: OpReload})
+}
+
+func (w *NATSWatcher) UpdateForAddPolicies(sec string, ptype string, rules ...[]string) error {
+	return w.publish(PolicyUpdate{Op: OpAddPolicies, Sec: sec, Ptype: ptype, Rules: rules})
+}
+
+func (w *NATSWatcher) UpdateForRemovePolicies(sec string, ptype string, rules ...[]string) error {
+	return w.publish(PolicyUpdate{Op: OpRemovePolicies, Sec: sec, Ptype: ptype, Rules: rules})
+}
+
+func (w *NATSWatcher) publish(update PolicyUpdate) error {
+	start := time.Now()
+	err := Publish(w.nc, w.subject, w.origin, update)
+	if w.opts.ObservePublish != nil {
+		w.opts.ObservePublish(w.subject, start)
+	}
+	return err
+}
+
+// Publish publishes update on subject on behalf of origin. It tells the enforcers of a policy
+// store about a change made to its adapter without an enforcer of that store, e.g. when the
+// grant reaper removes expired rules.
+func Publish(nc *nats.Conn, subject, origin string, update PolicyUpdate) error {
+	update.Origin = origin
+	data, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to encode policy update: %w", err)
+	}
+	if err := nc.Publish(subject, data); err != nil {
+		return fmt.Errorf("failed to publish policy update to %s: %w", subject, err)
+	}
+	return nil
+}
+
+// DefaultUpdateCallback returns an update callback applying policy deltas to e
+// without notifying the watcher again. Payloads that are not a PolicyUpdate
+// (e.g. legacy "reload_policies" strings) trigger a full reload.
+func DefaultUpdateCallback(e *casbin.SyncedEnforcer, log *slog.Logger) func(string) {
+	if log == nil {
+		log = slog.Default()
+	}
+	return func(payload string) {
+		var update PolicyUpdate
+		if err := json.Unmarshal([]byte(payload), &update); err != nil {
+			update = PolicyUpdate{Op: OpReload}
+		}
+		if err := ApplyUpdate(e, update); err != nil {
+			log.Warn("Failed to apply policy update, reloading all policies", "op", update.Op, "error", err)
+			if err := e.LoadPolicy(); err != nil {
+				log.Error("Failed to reload policies", "error", err)
+			}
+			return
+		}
+		log.Debug("Applied policy update", "op", update.Op, "ptype", update.Ptype, "rules", len(update.Rules))
+	}
+}
+
+// ApplyUpdate applies a single PolicyUpdate to the in-memory model of e. The adapter is left
+// alone: the publisher already saved the change, and saving it again from every replica would
+// duplicate it in the shared storage. e must have auto-save enabled (the default), as it is
+// re-enabled once the update is applied.
+func ApplyUpdate(e *casbin.SyncedEnforcer, update PolicyUpdate) error {
+	if update.Op == OpReload {
+		return e.LoadPolicy()
+	}
+
+	// The Self* functions skip the watcher but still save to the adapter while auto-save is on.
+	// Holding the enforcer's lock keeps other changes from running with auto-save disabled.
+	e.GetLock().Lock()
+	defer e.GetLock().Unlock()
+	e.Enforcer.EnableAutoSave(false)
+	defer e.Enforcer.EnableAutoSave(true)
+
+	var err error
+	switch update.Op {
+	case OpAddPolicies:
+		_, err = e.Enforcer.SelfAddPolicies(update.Sec, update.Ptype, update.Rules)
+	case OpRemovePolicies:
+		_, err = e.Enforcer.SelfRemovePolicies(update.Sec, update.Ptype, update.Rules)
+	case OpRemoveFilteredPolicy:
+		_, err = e.Enforcer.SelfRemoveFilteredPolicy(update.Sec, update.Ptype, update.FieldIndex, update.FieldValues...)
+	default:
+		err = fmt.Errorf("unknown policy update operation %q", update.Op)
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+package policywatch
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/nats-io/nats.go"
+)
+
+const testModel = `
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act
+`
+
+// recordingAdapter holds the initial rules and counts every write made to it.
+type recordingAdapter struct {
+	rules  [][]string // ptype followed by the rule
+	writes int
+}
+
+var _ persist.BatchAdapter = (*recordingAdapter)(nil)
+
+func (a *recordingAdapter) LoadPolicy(m model.Model) error {
+	for _, rule := range a.rules {
+		if err := persist.LoadPolicyArray(rule, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *recordingAdapter) SavePolicy(model.Model) error { a.writes++; return nil }
+func (a *recordingAdapter) AddPolicy(string, string, []string) error {
+	a.writes++
+	return nil
+}
+func (a *recordingAdapter) RemovePolicy(string, string, []string) error {
+	a.writes++
+	return nil
+}
+func (a *recordingAdapter) RemoveFilteredPolicy(string, string, int, ...string) error {
+	a.writes++
+	return nil
+}
+func (a *recordingAdapter) AddPolicies(string, string, [][]string) error {
+	a.writes++
+	return nil
+}
+func (a *recordingAdapter) RemovePolicies(string, string, [][]string) error {
+	a.writes++
+	return nil
+}
+
+func newEnforcer(t *testing.T, a *recordingAdapter) *casbin.SyncedEnforcer {
+	t.Helper()
+	m, err := model.NewModelFromString(testModel)
+	if err != nil {
+		t.Fatalf("model: %v", err)
+	}
+	e, err := casbin.NewSyncedEnforcer(m, a)
+	if err != nil {
+		t.Fatalf("enforcer: %v", err)
+	}
+	return e
+}
+
+func TestApplyUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update PolicyUpdate
+		want   map[string]bool // "sub obj act" => decision after the update
+	}{
+		{
+			name:   "add",
+			update: PolicyUpdate{Op: OpAddPolicies, Sec: "p", Ptype: "p", Rules: [][]string{{"admin", "server:lobby", "join"}}},
+			want:   map[string]bool{"alice server:lobby join": true, "alice server:survival join": true},
+		},
+		{
+			name:   "add grouping",
+			update: PolicyUpdate{Op: OpAddPolicies, Sec: "g", Ptype: "g", Rules: [][]string{{"bob", "admin"}}},
+			want:   map[string]bool{"bob server:survival join": true},
+		},
+		{
+			name:   "remove",
+			update: PolicyUpdate{Op: OpRemovePolicies, Sec: "p", Ptype: "p", Rules: [][]string{{"admin", "server:survival", "join"}}},
+			want:   map[string]bool{"alice server:survival join": false},
+		},
+		{
+			name:   "remove filtered",
+			update: PolicyUpdate{Op: OpRemoveFilteredPolicy, Sec: "g", Ptype: "g", FieldIndex: 0, FieldValues: []string{"alice"}},
+			want:   map[string]bool{"alice server:survival join": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &recordingAdapter{rules: [][]string{
+				{"p", "admin", "server:survival", "join"},
+				{"g", "alice", "admin"},
+			}}
+			e := newEnforcer(t, a)
+
+			if err := ApplyUpdate(e, tt.update); err != nil {
+				t.Fatalf("ApplyUpdate: %v", err)
+			}
+			for req, want := range tt.want {
+				r := strings.Fields(req)
+				got, err := e.Enforce(r[0], r[1], r[2])
+				if err != nil {
+					t.Fatalf("Enforce(%s): %v", req, err)
+				}
+				if got != want {
+					t.Errorf("Enforce(%s) = %v, want %v", req, got, want)
+				}
+			}
+			if a.writes != 0 {
+				t.Errorf("ApplyUpdate wrote %d times to the adapter, want 0", a.writes)
+			}
+
+			// Changes made through the enforcer itself are still saved
+			if _, err := e.AddPolicy("helper", "server:lobby", "kick"); err != nil {
+				t.Fatalf("AddPolicy: %v", err)
+			}
+			if a.writes != 1 {
+				t.Errorf("AddPolicy after ApplyUpdate wrote %d times to the adapter, want 1", a.writes)
+			}
+		})
+	}
+}
+
+func TestApplyUpdateUnknownOp(t *testing.T) {
+	e := newEnforcer(t, &recordingAdapter{})
+	if err := ApplyUpdate(e, PolicyUpdate{Op: "rename"}); err == nil {
+		t.Fatal("ApplyUpdate accepted an unknown operation")
+	}
+}
+
+func TestHandleMessageSkipsOwnUpdates(t *testing.T) {
+	w := &NATSWatcher{subject: "policy.updated", origin: "self"}
+	var received []string
+	_ = w.SetUpdateCallback(func(payload string) { received = append(received, payload) })
+
+	send := func(update PolicyUpdate) {
+		data, err := json.Marshal(update)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.handleMessage(&nats.Msg{Subject: w.subject, Data: data})
+	}
+	send(PolicyUpdate{Origin: "self", Op: OpAddPolicies, Sec: "p", Ptype: "p", Rules: [][]string{{"a", "b", "c"}}})
+	if len(received) != 0 {
+		t.Fatalf("own update was passed to the callback: %v", received)
+	}
+
+	send(PolicyUpdate{Origin: "other", Op: OpAddPolicies, Sec: "p", Ptype: "p", Rules: [][]string{{"a", "b", "c"}}})
+	w.handleMessage(&nats.Msg{Subject: w.subject, Data: []byte("reload_policies")})
+	if len(received) != 2 {
+		t.Fatalf("got %d updates from other instances, want 2", len(received))
+	}
+}
@@ -35,6 +35,9 @@ IMAGE_TAG_REMOTE_DEV="${IMAGE_REGISTRY}/${IMAGE_TAG_BASE}:dev" # Also push a :de
 
 KIND_CLUSTER_NAME="minecraft-net" # As defined in your kind-setup.sh
 
+# Go modules shared by the services (pkg/), available to Dockerfiles as the "pkg" build context
+PKG_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")/../pkg" && pwd)"
+
 echo "🔨 Building Docker image for service '${SERVICE_NAME}' from '${SERVICE_DIR}/${DOCKERFILE_NAME}'..."
 echo "DEV_MODE: ${DEV_MODE}"
 
@@ -50,6 +53,7 @@ if [[ "$DEV_MODE" == "local" ]]; then
     --load \
     -t "${IMAGE_TAG_DEV}" \
     -f "${SERVICE_DIR}/${DOCKERFILE_NAME}" \
+    --build-context pkg="${PKG_DIR}" \
     "${SERVICE_DIR}"
 
   echo "📦 Loading image '${IMAGE_TAG_DEV}' into Kind cluster '${KIND_CLUSTER_NAME}'..."
@@ -66,6 +70,7 @@ elif [[ "$DEV_MODE" == "remote" ]]; then
     -t "${IMAGE_TAG_REMOTE_LATEST}" \
     -t "${IMAGE_TAG_REMOTE_DEV}" \
     -f "${SERVICE_DIR}/${DOCKERFILE_NAME}" \
+    --build-context pkg="${PKG_DIR}" \
     "${SERVICE_DIR}"
   echo "✅ Done: Images pushed to ${IMAGE_REGISTRY}."
   echo "   ${IMAGE_TAG_REMOTE_LATEST}"
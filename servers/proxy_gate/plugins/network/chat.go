@@ -2,6 +2,9 @@ package network
 
 import (
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/chat"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/constants"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/permissions"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/util/metadata"
 	"github.com/go-logr/logr"
 	"github.com/nats-io/nats.go"
 	"github.com/robinbraemer/event"
@@ -10,6 +13,9 @@ import (
 
 // InitChatSystem initializes the chat system: registers the chat event handler and starts the NATS chat subscriber.
 func InitChatSystem(p *proxy.Proxy, nc *nats.Conn, log logr.Logger) error {
+	// Expose the chat channels to policies as "channel:<name>" objects
+	permissions.ChannelRegistry.Set(constants.ChatGlobalChannel, metadata.Metadata{Labels: map[string]string{"channel/scope": "network"}})
+
 	// Register chat event handler
 	event.Subscribe(p.Event(), 0, chat.CreatePlayerChatEventHandler(nc, log.WithName("Handler")))
 
@@ -1,14 +1,19 @@
 package chat
 
 import (
+	"context"
 	"encoding/json"
+	"time"
 
+	"github.com/bafbi/minecraft-network/pkg/observability"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/constants"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
 	"github.com/go-logr/logr"
 	"github.com/nats-io/nats.go"
 	"go.minekube.com/common/minecraft/color"
 	c "go.minekube.com/common/minecraft/component"
 	"go.minekube.com/gate/pkg/edition/java/proxy"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // CreatePlayerChatEventHandler creates an event handler for player chat messages.
@@ -44,11 +49,20 @@ func CreatePlayerChatEventHandler(nc *nats.Conn, log logr.Logger) func(*proxy.Pl
 			return
 		}
 
-		if err := nc.Publish(constants.ChatChannelSubject, data); err != nil {
-			log.Error(err, "Failed to publish chat message to NATS", "player", player.Username())
+		// The trace continues in the subscribers broadcasting the message on every proxy
+		msg := &nats.Msg{Subject: constants.ChatChannelSubject, Data: data}
+		ctx, span := observability.StartPublish(context.Background(), msg)
+		span.SetAttributes(attribute.String("chat.server", serverName))
+		start := time.Now()
+		err = nc.PublishMsg(msg)
+		metrics.ObservePublish(msg.Subject, start)
+		observability.EndSpan(span, err)
+		if err != nil {
+			observability.WithTrace(ctx, log).Error(err, "Failed to publish chat message to NATS", "player", player.Username())
 			_ = player.SendMessage(&c.Text{Content: "Error sending message (publish failed).", S: c.Style{Color: color.Red}})
 			return
 		}
-		log.V(1).Info("Published chat message to NATS", "player", player.Username(), "message", e.Message())
+		metrics.ChatMessage(metrics.ChatPublished)
+		observability.WithTrace(ctx, log).V(1).Info("Published chat message to NATS", "player", player.Username(), "message", e.Message())
 	}
 }
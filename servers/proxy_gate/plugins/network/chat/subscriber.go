@@ -1,15 +1,20 @@
 package chat
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
+	"github.com/bafbi/minecraft-network/pkg/observability"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/constants"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/players"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/servers"
 	"github.com/go-logr/logr"
 	"github.com/nats-io/nats.go"
 	"go.minekube.com/gate/pkg/edition/java/proxy"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // StartNatsSubscriber subscribes to NATS chat messages and broadcasts them to relevant local players.
@@ -22,12 +27,22 @@ func StartNatsSubscriber(
 		return nil, fmt.Errorf("NATS connection (nc) is not initialized for chat subscriber")
 	}
 
+	baseLog := log
 	subscription, err := nc.Subscribe(constants.ChatChannelSubject, func(msg *nats.Msg) {
+		// Continues the trace of the proxy the message was sent on
+		ctx, span := observability.StartReceive(context.Background(), msg)
+		defer span.End()
+		log := observability.WithTrace(ctx, baseLog)
+
 		var payload NetworkChatMessagePayload
 		if err := json.Unmarshal(msg.Data, &payload); err != nil {
 			log.Error(err, "Failed to unmarshal chat message from NATS", "data", string(msg.Data))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid chat payload")
 			return
 		}
+		span.SetAttributes(attribute.String("chat.server", payload.Server))
+		metrics.ChatMessage(metrics.ChatReceived)
 
 		log.V(1).Info("Received chat message from NATS", "payload", payload)
 
@@ -114,6 +129,7 @@ func StartNatsSubscriber(
 			}
 		}
 
+		span.SetAttributes(attribute.Int("chat.listeners", len(listeners)))
 		if len(listeners) > 0 {
 			chatMsgComponent := formatChatMessage(payload.Username, payload.Message)
 			proxy.BroadcastMessage(listeners, chatMsgComponent)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/permissions"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/players"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/servers"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/util/metadata"
@@ -18,6 +19,10 @@ import (
 func registerCommands(p *proxy.Proxy, log logr.Logger) {
 	p.Command().Register(registerJoinCommand(p, log))
 	p.Command().Register(metadataCommand(p, log))
+
+	// Expose command metadata to policies as "command:<name>" objects
+	permissions.CommandRegistry.Set("join", metadata.Metadata{Labels: map[string]string{"command/category": "network"}})
+	permissions.CommandRegistry.Set("metadata", metadata.Metadata{Labels: map[string]string{"command/category": "admin"}})
 	// p.Command().Register(registerServerSelectCommand(p, log))
 	// p.Command().Register(registerListServersCommand(p, log))
 }
@@ -45,6 +50,10 @@ func registerJoinCommand(p *proxy.Proxy, log logr.Logger) brigodier.LiteralNodeB
 			return sender.SendMessage(mini.Parse(fmt.Sprintf("<red>Server %s is not registered in the network</red>", serverName)))
 		}
 
+		if allowed, err := permissions.HasPermission(sender.ID().String(), "server:"+serverName, "connect", log); err != nil || !allowed {
+			return sender.SendMessage(mini.Parse(fmt.Sprintf("<red>You are not allowed to join %s's server (<yellow>%s</yellow>)</red>", targetName, serverName)))
+		}
+
 		// Notify the user
 		sender.SendMessage(mini.Parse(fmt.Sprintf("<green>Connecting you to %s's server (<yellow>%s</yellow>)...</green>", targetName, serverName)))
 
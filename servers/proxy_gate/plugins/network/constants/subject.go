@@ -2,4 +2,5 @@ package constants
 
 const (
 	ChatChannelSubject = "chat.channel" // Subject for chat channel messages
+	ChatGlobalChannel  = "global"       // Channel of the messages sent on ChatChannelSubject, "channel:global" in policies
 )
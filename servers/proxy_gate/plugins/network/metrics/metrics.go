@@ -0,0 +1,202 @@
+// Package metrics exposes Prometheus metrics of the network plugin on /metrics.
+//
+// Player gauges count the players connected through this proxy, so that network-wide figures are
+// sums over the proxies' series. Every series carries the proxy label set by Init.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.minekube.com/gate/pkg/edition/java/proxy"
+)
+
+const namespace = "network"
+
+var (
+	playerConnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "player_connects_total",
+		Help:      "Player connections to backend servers, including switches between servers.",
+	}, []string{"server"})
+	playerKicks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "player_kicks_total",
+		Help:      "Players kicked from a backend server, by the server they were kicked from.",
+	}, []string{"server"})
+	playerRedirects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "player_redirects_total",
+		Help:      "Kicked players redirected to another server, by the server they were sent to.",
+	}, []string{"server"})
+	chatMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chat_messages_total",
+		Help:      "Chat messages published by this proxy and received from NATS.",
+	}, []string{"direction"})
+	enforceDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "permission_check_duration_seconds",
+		Help:      "Time taken by authorization decisions, by backend (embedded Casbin or the remote checker) and result.",
+		Buckets:   []float64{.00005, .0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"backend", "result"})
+	natsPublishDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "nats_publish_duration_seconds",
+		Help:      "Time taken to publish NATS messages, by subject.",
+		Buckets:   []float64{.00005, .0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1},
+	}, []string{"subject"})
+	kvWatcherLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "kv_watcher_lag",
+		Help:      "Revisions of the NATS KV bucket not yet delivered to the watcher when it handled its last update.",
+	}, []string{"bucket"})
+	kvWatcherRevision = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "kv_watcher_revision",
+		Help:      "Revision of the last NATS KV update handled by the watcher.",
+	}, []string{"bucket"})
+	kvWatcherUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kv_watcher_updates_total",
+		Help:      "NATS KV updates handled by the watcher, by operation.",
+	}, []string{"bucket", "operation"})
+)
+
+// Chat message directions.
+const (
+	ChatPublished = "published"
+	ChatReceived  = "received"
+)
+
+var registry = prometheus.NewRegistry()
+
+// Init registers the metrics of p under the proxy label, the hostname (the pod name on Kubernetes).
+func Init(p *proxy.Proxy) error {
+	reg := prometheus.WrapRegistererWith(prometheus.Labels{"proxy": proxyID()}, registry)
+	return errors.Join(
+		reg.Register(playerConnects),
+		reg.Register(playerKicks),
+		reg.Register(playerRedirects),
+		reg.Register(chatMessages),
+		reg.Register(enforceDuration),
+		reg.Register(natsPublishDuration),
+		reg.Register(kvWatcherLag),
+		reg.Register(kvWatcherRevision),
+		reg.Register(kvWatcherUpdates),
+		reg.Register(playersCollector{p: p}),
+		registry.Register(collectors.NewGoCollector()),
+		registry.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})),
+	)
+}
+
+// Serve serves /metrics on addr until ctx is cancelled.
+func Serve(ctx context.Context, addr string, log logr.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	log.Info("Serving Prometheus metrics", "address", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error(err, "Metrics server failed", "address", addr)
+	}
+}
+
+func proxyID() string {
+	if hostname, err := os.Hostname(); err == nil {
+		return hostname
+	}
+	return "proxy"
+}
+
+// PlayerConnected counts a connection to server.
+func PlayerConnected(server string) {
+	playerConnects.WithLabelValues(server).Inc()
+}
+
+// PlayerKicked counts a kick from server.
+func PlayerKicked(server string) {
+	playerKicks.WithLabelValues(server).Inc()
+}
+
+// PlayerRedirected counts a kicked player sent to server.
+func PlayerRedirected(server string) {
+	playerRedirects.WithLabelValues(server).Inc()
+}
+
+// ChatMessage counts a chat message in direction.
+func ChatMessage(direction string) {
+	chatMessages.WithLabelValues(direction).Inc()
+}
+
+// ObserveCheck records an authorization decision made by backend since start.
+func ObserveCheck(backend string, start time.Time, allowed bool, err error) {
+	result := "deny"
+	switch {
+	case err != nil:
+		result = "error"
+	case allowed:
+		result = "allow"
+	}
+	enforceDuration.WithLabelValues(backend, result).Observe(time.Since(start).Seconds())
+}
+
+// ObservePublish records a NATS publish on subject that started at start.
+func ObservePublish(subject string, start time.Time) {
+	natsPublishDuration.WithLabelValues(subject).Observe(time.Since(start).Seconds())
+}
+
+// ObserveKVEntry records an update handled by the watcher of bucket. Delta is the number of
+// updates pending after entry when the watcher received it, 0 once it has caught up.
+func ObserveKVEntry(bucket string, entry nats.KeyValueEntry) {
+	kvWatcherLag.WithLabelValues(bucket).Set(float64(entry.Delta()))
+	kvWatcherRevision.WithLabelValues(bucket).Set(float64(entry.Revision()))
+	kvWatcherUpdates.WithLabelValues(bucket, entry.Operation().String()).Inc()
+}
+
+// playersCollector counts the players connected through the proxy when scraped.
+type playersCollector struct {
+	p *proxy.Proxy
+}
+
+var (
+	proxyPlayersDesc = prometheus.NewDesc(namespace+"_proxy_players",
+		"Players connected to this proxy.", nil, nil)
+	serverPlayersDesc = prometheus.NewDesc(namespace+"_server_players",
+		"Players connected through this proxy, by backend server.", []string{"server"}, nil)
+)
+
+func (c playersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- proxyPlayersDesc
+	ch <- serverPlayersDesc
+}
+
+func (c playersCollector) Collect(ch chan<- prometheus.Metric) {
+	players := c.p.Players()
+	perServer := make(map[string]int)
+	for _, server := range c.p.Servers() {
+		perServer[server.ServerInfo().Name()] = 0 // Report empty servers too
+	}
+	for _, player := range players {
+		if current := player.CurrentServer(); current != nil {
+			perServer[current.Server().ServerInfo().Name()]++
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(proxyPlayersDesc, prometheus.GaugeValue, float64(len(players)))
+	for server, count := range perServer {
+		ch <- prometheus.MustNewConstMetric(serverPlayersDesc, prometheus.GaugeValue, float64(count), server)
+	}
+}
@@ -5,13 +5,14 @@ import (
 	"fmt"
 
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/permissions"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/servers"
 	"github.com/go-logr/logr"
 	"github.com/nats-io/nats.go"
 	"go.minekube.com/gate/pkg/edition/java/proxy"
 )
 
-// InitPermissionSystem initializes the permission system (Casbin, commands, etc).
-func InitPermissionSystem(ctx context.Context, p *proxy.Proxy, nc *nats.Conn, log logr.Logger) error {
+// InitPermissionSystem initializes the permission system (Casbin, audit log, authorization backend, commands, etc).
+func InitPermissionSystem(ctx context.Context, p *proxy.Proxy, nc *nats.Conn, js nats.JetStreamContext, log logr.Logger) error {
 
 	if err := permissions.InitCasbin(
 		ctx,
@@ -22,9 +23,44 @@ func InitPermissionSystem(ctx context.Context, p *proxy.Proxy, nc *nats.Conn, lo
 		return fmt.Errorf("failed to initialize Casbin: %w", err)
 	}
 
+	if err := permissions.InitGrants(js, log.WithName("Grants")); err != nil {
+		// Permanent changes still work; time-bound ones are refused
+		log.Error(err, "Failed to initialize time-bound permission grants")
+	}
+
+	auditCfg, err := permissions.LoadAuditConfig()
+	if err != nil {
+		return fmt.Errorf("invalid audit configuration: %w", err)
+	}
+	if err := permissions.InitAudit(nc, js, auditCfg, log.WithName("Audit")); err != nil {
+		// Decisions are still made without the audit log
+		log.Error(err, "Failed to initialize decision audit log")
+	}
+
+	backendCfg, err := permissions.LoadBackendConfig()
+	if err != nil {
+		return fmt.Errorf("invalid authorization backend configuration: %w", err)
+	}
+	if err := permissions.InitBackend(ctx, backendCfg, log.WithName("Backend")); err != nil {
+		log.Error(err, "Failed to initialize authorization backend")
+		return err
+	}
+
 	// Register permission commands
 	permissions.RegisterCommands(p, log.WithName("Commands"))
 
 	log.Info("Permission system initialized")
 	return nil
 }
+
+// canConnectToServer returns a servers.ConnectAuthorizer checking the "connect"
+// action on "server:<name>" through Casbin. Errors deny the connection.
+func canConnectToServer(log logr.Logger) servers.ConnectAuthorizer {
+	return func(player proxy.Player, serverName string) bool {
+		allowed, err := permissions.HasPermission(player.ID().String(), "server:"+serverName, "connect", log)
+		if err != nil {
+			return false
+		}
+		return allowed
+	}
+}
@@ -0,0 +1,149 @@
+package permissions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// auditSubjectPrefix matches the subjects of the permissions-checker audit stream:
+	// permissions.audit.<source>.<allow|deny>
+	auditSubjectPrefix = "permissions.audit."
+	auditSource        = "proxy"
+)
+
+// AuditDecision is a decision record published to the audit stream.
+// It has the same JSON shape as the records of permissions-checker.
+type AuditDecision struct {
+	Time          time.Time `json:"time"`
+	Source        string    `json:"source"`
+	Caller        string    `json:"caller,omitempty"`
+	Subject       string    `json:"subject"`
+	SubjectName   string    `json:"subject_name,omitempty"`
+	Object        string    `json:"object"`
+	Action        string    `json:"action"`
+	Allowed       bool      `json:"allowed"`
+	MatchedPolicy []string  `json:"matched_policy,omitempty"`
+	LatencyMicros int64     `json:"latency_us"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// AuditConfig configures the decision audit log.
+type AuditConfig struct {
+	Enabled         bool
+	Stream          string        // JetStream stream name, shared with permissions-checker
+	MaxAge          time.Duration // Retention used when the stream has to be created
+	AllowSampleRate float64       // Fraction of allowed decisions recorded (0..1); denies are always recorded
+}
+
+// LoadAuditConfig reads the audit configuration from the environment.
+func LoadAuditConfig() (AuditConfig, error) {
+	cfg := AuditConfig{
+		Enabled:         os.Getenv("AUDIT_ENABLED") != "false",
+		Stream:          os.Getenv("AUDIT_STREAM"),
+		MaxAge:          72 * time.Hour,
+		AllowSampleRate: 0.1,
+	}
+	if cfg.Stream == "" {
+		cfg.Stream = "PERMISSION_AUDIT"
+	}
+	if v := os.Getenv("AUDIT_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid AUDIT_MAX_AGE %q: %w", v, err)
+		}
+		cfg.MaxAge = d
+	}
+	if v := os.Getenv("AUDIT_ALLOW_SAMPLE_RATE"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 1 {
+			return cfg, fmt.Errorf("invalid AUDIT_ALLOW_SAMPLE_RATE %q: expected a number between 0 and 1", v)
+		}
+		cfg.AllowSampleRate = f
+	}
+	return cfg, nil
+}
+
+// auditRecorder publishes decisions made by the embedded enforcer.
+// A nil *auditRecorder records nothing.
+type auditRecorder struct {
+	nc              *nats.Conn
+	caller          string
+	allowSampleRate float64
+	log             logr.Logger
+}
+
+// auditInstance is the recorder used by the embedded backend; nil when auditing is disabled.
+var auditInstance *auditRecorder
+
+// InitAudit creates the audit stream if needed and enables decision recording.
+func InitAudit(nc *nats.Conn, js nats.JetStreamContext, cfg AuditConfig, log logr.Logger) error {
+	if !cfg.Enabled {
+		log.Info("Decision audit log disabled")
+		return nil
+	}
+	if _, err := js.StreamInfo(cfg.Stream); errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:     cfg.Stream,
+			Subjects: []string{auditSubjectPrefix + ">"},
+			Storage:  nats.FileStorage,
+			MaxAge:   cfg.MaxAge,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create audit stream %s: %w", cfg.Stream, err)
+		}
+		log.Info("Created JetStream audit stream", "stream", cfg.Stream)
+	} else if err != nil {
+		return fmt.Errorf("failed to get audit stream %s: %w", cfg.Stream, err)
+	}
+
+	auditInstance = &auditRecorder{nc: nc, caller: proxyCallerID(), allowSampleRate: cfg.AllowSampleRate, log: log}
+	log.Info("Recording decisions to audit stream", "stream", cfg.Stream, "allowSampleRate", cfg.AllowSampleRate)
+	return nil
+}
+
+// record publishes d unless it is an allow that is not sampled.
+func (r *auditRecorder) record(d AuditDecision) {
+	if r == nil {
+		return
+	}
+	if d.Allowed && rand.Float64() >= r.allowSampleRate {
+		return
+	}
+	d.Source = auditSource
+	d.Caller = r.caller
+
+	result := "deny"
+	if d.Allowed {
+		result = "allow"
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		r.log.Error(err, "Failed to encode audit decision")
+		return
+	}
+	subject := auditSubjectPrefix + auditSource + "." + result
+	start := time.Now()
+	err = r.nc.Publish(subject, data)
+	metrics.ObservePublish(subject, start)
+	if err != nil {
+		r.log.Error(err, "Failed to publish audit decision")
+	}
+}
+
+// proxyCallerID identifies this proxy instance in audit records and checker requests.
+func proxyCallerID() string {
+	if hostname, err := os.Hostname(); err == nil {
+		return "proxy/" + hostname
+	}
+	return "proxy"
+}
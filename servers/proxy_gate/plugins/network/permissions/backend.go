@@ -0,0 +1,202 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
+	"github.com/go-logr/logr"
+)
+
+// Backend makes the authorization decisions returned by HasPermission.
+type Backend interface {
+	// Authorize reports whether subjectID may perform action on objectResource.
+	Authorize(ctx context.Context, subjectID, objectResource, action string) (bool, error)
+	// AuthorizeBatch evaluates several checks at once; decisions are returned in request order.
+	AuthorizeBatch(ctx context.Context, checks []Check) ([]bool, error)
+	// Name identifies the backend in logs.
+	Name() string
+	// Close releases resources held by the backend.
+	Close() error
+}
+
+// Check is a single (subject, object, action) request evaluated by AuthorizeBatch.
+type Check struct {
+	Subject string
+	Object  string
+	Action  string
+}
+
+const (
+	BackendEmbedded = "embedded" // Decisions from the Casbin enforcer embedded in the proxy
+	BackendRemote   = "remote"   // Decisions from the permissions-checker AuthService over gRPC
+)
+
+// Failure policies of the remote backend when the checker is unavailable.
+const (
+	FailClosed   = "closed"   // Deny the requests
+	FailOpen     = "open"     // Allow the requests
+	FailEmbedded = "embedded" // Decide with the embedded enforcer, which is kept in sync with the policies
+)
+
+// BackendConfig selects and configures the authorization backend.
+type BackendConfig struct {
+	Backend string // BackendEmbedded or BackendRemote
+
+	// Remote backend settings
+	CheckerAddr      string        // Address of the permissions-checker gRPC service
+	Timeout          time.Duration // Deadline of a single CheckPermission call
+	FailMode         string        // FailClosed, FailOpen or FailEmbedded when the checker is unavailable
+	BreakerThreshold int           // Consecutive failures before the circuit opens
+	BreakerCooldown  time.Duration // Time the circuit stays open before a trial request
+
+	// Remote backend credentials; TLS is used when CheckerTLSCA is set
+	CheckerTLSCA         string // CA of the checker's certificate
+	CheckerTLSCert       string // Client certificate for mutual TLS, optional
+	CheckerTLSKey        string
+	CheckerTLSServerName string // Overrides the server name verified in the checker's certificate
+	CheckerAPIKey        string // Sent as "x-api-key"; the key should have the "check" role
+	CheckerToken         string // JWT sent as "authorization: Bearer", when no API key is set
+}
+
+// LoadBackendConfig reads the backend configuration from the environment.
+func LoadBackendConfig() (BackendConfig, error) {
+	cfg := BackendConfig{
+		Backend:              os.Getenv("PERMISSIONS_BACKEND"),
+		CheckerAddr:          os.Getenv("PERMISSIONS_CHECKER_ADDR"),
+		Timeout:              500 * time.Millisecond,
+		FailMode:             FailClosed,
+		BreakerThreshold:     5,
+		BreakerCooldown:      10 * time.Second,
+		CheckerTLSCA:         os.Getenv("PERMISSIONS_CHECKER_TLS_CA"),
+		CheckerTLSCert:       os.Getenv("PERMISSIONS_CHECKER_TLS_CERT"),
+		CheckerTLSKey:        os.Getenv("PERMISSIONS_CHECKER_TLS_KEY"),
+		CheckerTLSServerName: os.Getenv("PERMISSIONS_CHECKER_TLS_SERVER_NAME"),
+		CheckerAPIKey:        os.Getenv("PERMISSIONS_CHECKER_API_KEY"),
+		CheckerToken:         os.Getenv("PERMISSIONS_CHECKER_TOKEN"),
+	}
+	if cfg.Backend == "" {
+		cfg.Backend = BackendEmbedded
+	}
+	if cfg.CheckerAddr == "" {
+		cfg.CheckerAddr = "permissions-checker:50051"
+	}
+
+	if v := os.Getenv("PERMISSIONS_CHECK_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid PERMISSIONS_CHECK_TIMEOUT %q: %w", v, err)
+		}
+		cfg.Timeout = d
+	}
+	switch v := os.Getenv("PERMISSIONS_FAIL_MODE"); v {
+	case "":
+	case FailClosed, FailOpen, FailEmbedded:
+		cfg.FailMode = v
+	default:
+		return cfg, fmt.Errorf("invalid PERMISSIONS_FAIL_MODE %q: expected \"closed\", \"open\" or \"embedded\"", v)
+	}
+	if v := os.Getenv("PERMISSIONS_BREAKER_THRESHOLD"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("invalid PERMISSIONS_BREAKER_THRESHOLD %q", v)
+		}
+		cfg.BreakerThreshold = n
+	}
+	if v := os.Getenv("PERMISSIONS_BREAKER_COOLDOWN"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid PERMISSIONS_BREAKER_COOLDOWN %q: %w", v, err)
+		}
+		cfg.BreakerCooldown = d
+	}
+	return cfg, nil
+}
+
+// backendInstance is the backend used by HasPermission; access via GetBackend().
+var backendInstance Backend
+
+// InitBackend creates the authorization backend selected by cfg.
+// The embedded backend, also the fallback of the remote one, requires InitCasbin to have been called.
+func InitBackend(ctx context.Context, cfg BackendConfig, log logr.Logger) error {
+	var b Backend
+	switch cfg.Backend {
+	case BackendEmbedded:
+		b = newEnforcerBackend()
+	case BackendRemote:
+		remote, err := newRemoteBackend(cfg, log.WithName("Remote"))
+		if err != nil {
+			return fmt.Errorf("failed to create remote authorization backend: %w", err)
+		}
+		b = remote
+	default:
+		return fmt.Errorf("unknown authorization backend %q", cfg.Backend)
+	}
+	backendInstance = b
+
+	go func() {
+		<-ctx.Done()
+		if err := b.Close(); err != nil {
+			log.Error(err, "Failed to close authorization backend", "backend", b.Name())
+		}
+	}()
+
+	log.Info("Authorization backend initialized", "backend", b.Name())
+	return nil
+}
+
+// GetBackend returns the authorization backend used by HasPermission.
+func GetBackend() Backend {
+	return backendInstance
+}
+
+// enforcerBackend evaluates requests against the embedded Casbin enforcer.
+type enforcerBackend struct{}
+
+func newEnforcerBackend() *enforcerBackend {
+	return &enforcerBackend{}
+}
+
+func (b *enforcerBackend) Name() string { return BackendEmbedded }
+
+func (b *enforcerBackend) Close() error { return nil }
+
+func (b *enforcerBackend) Authorize(_ context.Context, subjectID, objectResource, action string) (bool, error) {
+	e := GetEnforcer()
+	if e == nil {
+		return false, fmt.Errorf("casbin enforcer not initialized")
+	}
+
+	start := time.Now()
+	allowed, matched, err := e.EnforceEx(subjectID, objectResource, action)
+	decision := AuditDecision{
+		Time:          start,
+		Subject:       subjectID,
+		Object:        objectResource,
+		Action:        action,
+		Allowed:       allowed && err == nil,
+		MatchedPolicy: matched,
+		LatencyMicros: time.Since(start).Microseconds(),
+	}
+	if err != nil {
+		decision.Error = err.Error()
+	}
+	metrics.ObserveCheck(BackendEmbedded, start, allowed, err)
+	auditInstance.record(decision)
+	return allowed, err
+}
+
+func (b *enforcerBackend) AuthorizeBatch(ctx context.Context, checks []Check) ([]bool, error) {
+	decisions := make([]bool, len(checks))
+	for i, c := range checks {
+		allowed, err := b.Authorize(ctx, c.Subject, c.Object, c.Action)
+		if err != nil {
+			return nil, err
+		}
+		decisions[i] = allowed
+	}
+	return decisions, nil
+}
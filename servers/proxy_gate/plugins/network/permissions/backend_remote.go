@@ -0,0 +1,231 @@
+package permissions
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bafbi/minecraft-network/pkg/observability"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/permissions/auth"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/players"
+	"github.com/go-logr/logr"
+	"go.minekube.com/gate/pkg/util/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// remoteBackend delegates decisions to the permissions-checker AuthService.
+type remoteBackend struct {
+	conn     *grpc.ClientConn
+	client   auth.AuthServiceClient
+	timeout  time.Duration
+	failMode string
+	fallback Backend // Decides when the checker is unavailable and failMode is FailEmbedded
+	breaker  *circuitBreaker
+	caller   string // Sent as "x-caller" so that the checker's audit log identifies this proxy
+	log      logr.Logger
+}
+
+func newRemoteBackend(cfg BackendConfig, log logr.Logger) (*remoteBackend, error) {
+	dialOpts, err := remoteDialOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Checks are traced and their trace context sent to the checker
+	dialOpts = append(dialOpts, observability.GRPCDialOption())
+	conn, err := grpc.NewClient(cfg.CheckerAddr, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client for %s: %w", cfg.CheckerAddr, err)
+	}
+	log.Info("Created permissions-checker client", "address", cfg.CheckerAddr,
+		"timeout", cfg.Timeout, "failMode", cfg.FailMode, "tls", cfg.CheckerTLSCA != "",
+		"apiKey", cfg.CheckerAPIKey != "", "token", cfg.CheckerToken != "")
+	return &remoteBackend{
+		conn:     conn,
+		client:   auth.NewAuthServiceClient(conn),
+		timeout:  cfg.Timeout,
+		failMode: cfg.FailMode,
+		fallback: newEnforcerBackend(),
+		breaker:  newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		caller:   proxyCallerID(),
+		log:      log,
+	}, nil
+}
+
+// remoteDialOptions returns the transport and call credentials identifying the proxy to the checker.
+func remoteDialOptions(cfg BackendConfig) ([]grpc.DialOption, error) {
+	var opts []grpc.DialOption
+	secure := cfg.CheckerTLSCA != ""
+	if secure {
+		pem, err := os.ReadFile(cfg.CheckerTLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PERMISSIONS_CHECKER_TLS_CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", cfg.CheckerTLSCA)
+		}
+		tlsCfg := &tls.Config{RootCAs: pool, ServerName: cfg.CheckerTLSServerName, MinVersion: tls.VersionTLS12}
+		if cfg.CheckerTLSCert != "" || cfg.CheckerTLSKey != "" {
+			cert, err := tls.LoadX509KeyPair(cfg.CheckerTLSCert, cfg.CheckerTLSKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load proxy client certificate: %w", err)
+			}
+			tlsCfg.Certificates = []tls.Certificate{cert}
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	switch {
+	case cfg.CheckerAPIKey != "":
+		opts = append(opts, grpc.WithPerRPCCredentials(callCredentials{key: "x-api-key", value: cfg.CheckerAPIKey, secure: secure}))
+	case cfg.CheckerToken != "":
+		opts = append(opts, grpc.WithPerRPCCredentials(callCredentials{key: "authorization", value: "Bearer " + cfg.CheckerToken, secure: secure}))
+	}
+	return opts, nil
+}
+
+// callCredentials sends an API key or token with every call.
+type callCredentials struct {
+	key, value string
+	secure     bool
+}
+
+func (c callCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{c.key: c.value}, nil
+}
+
+func (c callCredentials) RequireTransportSecurity() bool { return c.secure }
+
+func (b *remoteBackend) Name() string { return BackendRemote }
+
+func (b *remoteBackend) Close() error { return b.conn.Close() }
+
+func (b *remoteBackend) Authorize(ctx context.Context, subjectID, objectResource, action string) (bool, error) {
+	if !b.breaker.Allow() {
+		return b.unavailable(ctx, ErrCircuitOpen, subjectID, objectResource, action)
+	}
+
+	ctx, cancel := context.WithTimeout(metadata.AppendToOutgoingContext(ctx, "x-caller", b.caller), b.timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := b.client.CheckPermission(ctx, newAuthRequest(subjectID, objectResource, action))
+	metrics.ObserveCheck(BackendRemote, start, resp.GetAllowed(), err)
+	if b.record(err) {
+		return b.unavailable(ctx, err, subjectID, objectResource, action)
+	}
+	if err != nil {
+		return false, fmt.Errorf("permissions-checker rejected the check: %w", err)
+	}
+	return resp.GetAllowed(), nil
+}
+
+func (b *remoteBackend) AuthorizeBatch(ctx context.Context, checks []Check) ([]bool, error) {
+	if len(checks) == 0 {
+		return nil, nil
+	}
+	if !b.breaker.Allow() {
+		return b.unavailableBatch(ctx, ErrCircuitOpen, checks)
+	}
+
+	ctx, cancel := context.WithTimeout(metadata.AppendToOutgoingContext(ctx, "x-caller", b.caller), b.timeout)
+	defer cancel()
+
+	req := &auth.BatchAuthRequest{Requests: make([]*auth.AuthRequest, len(checks))}
+	for i, c := range checks {
+		req.Requests[i] = newAuthRequest(c.Subject, c.Object, c.Action)
+	}
+	resp, err := b.client.CheckPermissions(ctx, req)
+	if b.record(err) {
+		return b.unavailableBatch(ctx, err, checks)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("permissions-checker rejected the checks: %w", err)
+	}
+
+	if len(resp.GetResponses()) != len(checks) {
+		return nil, fmt.Errorf("permissions-checker returned %d decisions for %d checks", len(resp.GetResponses()), len(checks))
+	}
+	decisions := make([]bool, len(checks))
+	for i, r := range resp.GetResponses() {
+		decisions[i] = r.GetAllowed()
+	}
+	return decisions, nil
+}
+
+// record reports the outcome of a call to the breaker and whether the checker was unreachable.
+// Other errors (unauthenticated, invalid request...) are answers of the checker: they close the
+// circuit and deny, the failure policy only covering an unavailable checker.
+func (b *remoteBackend) record(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		b.breaker.Failure()
+		return true
+	}
+	b.breaker.Success()
+	return false
+}
+
+// unavailableBatch applies the failure policy to every check of a batch.
+func (b *remoteBackend) unavailableBatch(ctx context.Context, err error, checks []Check) ([]bool, error) {
+	switch b.failMode {
+	case FailEmbedded:
+		b.log.Error(err, "permissions-checker unavailable, using the embedded enforcer", "checks", len(checks))
+		return b.fallback.AuthorizeBatch(ctx, checks)
+	case FailOpen:
+		b.log.Error(err, "permissions-checker unavailable, allowing batch (fail-open)", "checks", len(checks))
+		decisions := make([]bool, len(checks))
+		for i := range decisions {
+			decisions[i] = true
+		}
+		return decisions, nil
+	}
+	return nil, fmt.Errorf("permissions-checker unavailable: %w", err)
+}
+
+// unavailable applies the failure policy when the checker cannot be reached.
+func (b *remoteBackend) unavailable(ctx context.Context, err error, subjectID, objectResource, action string) (bool, error) {
+	switch b.failMode {
+	case FailEmbedded:
+		b.log.Error(err, "permissions-checker unavailable, using the embedded enforcer",
+			"subject", subjectID, "object", objectResource, "action", action)
+		return b.fallback.Authorize(ctx, subjectID, objectResource, action)
+	case FailOpen:
+		b.log.Error(err, "permissions-checker unavailable, allowing request (fail-open)",
+			"subject", subjectID, "object", objectResource, "action", action)
+		return true, nil
+	}
+	return false, fmt.Errorf("permissions-checker unavailable: %w", err)
+}
+
+// newAuthRequest maps a proxy permission check to an AuthService request.
+// The server is taken from "server:<name>" objects, otherwise from the player's current location.
+func newAuthRequest(subjectID, objectResource, action string) *auth.AuthRequest {
+	req := &auth.AuthRequest{
+		PlayerUuid: subjectID,
+		Action:     action,
+		Resource:   objectResource,
+	}
+	if playerID, err := uuid.Parse(subjectID); err == nil {
+		if meta, found := players.GetMetadataByUUID(playerID); found {
+			req.PlayerName, _ = meta.GetAnnotation("player/name")
+			req.ServerName, _ = meta.GetAnnotation("network/location")
+		}
+	}
+	if serverName, ok := strings.CutPrefix(objectResource, "server:"); ok {
+		req.ServerName = serverName
+	}
+	return req
+}
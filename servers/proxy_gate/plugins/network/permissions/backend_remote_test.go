@@ -0,0 +1,162 @@
+package permissions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/permissions/auth"
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeChecker answers the remote backend's calls with err, or allows everything.
+type fakeChecker struct {
+	auth.AuthServiceClient
+	err   error
+	calls int
+}
+
+func (c *fakeChecker) CheckPermission(context.Context, *auth.AuthRequest, ...grpc.CallOption) (*auth.AuthResponse, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &auth.AuthResponse{Allowed: true}, nil
+}
+
+func (c *fakeChecker) CheckPermissions(_ context.Context, req *auth.BatchAuthRequest, _ ...grpc.CallOption) (*auth.BatchAuthResponse, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	resp := &auth.BatchAuthResponse{}
+	for range req.GetRequests() {
+		resp.Responses = append(resp.Responses, &auth.AuthResponse{Allowed: true})
+	}
+	return resp, nil
+}
+
+func newTestRemoteBackend(checker *fakeChecker, failMode string) *remoteBackend {
+	return &remoteBackend{
+		client:   checker,
+		timeout:  time.Second,
+		failMode: failMode,
+		fallback: newEnforcerBackend(),
+		breaker:  newCircuitBreaker(2, time.Hour),
+		log:      logr.Discard(),
+	}
+}
+
+// useTestEnforcer makes the embedded enforcer allow only "alice" to "join" "server:lobby".
+func useTestEnforcer(t *testing.T) {
+	t.Helper()
+	m, err := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+[policy_definition]
+p = sub, obj, act
+[policy_effect]
+e = some(where (p.eft == allow))
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := casbin.NewSyncedEnforcer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.AddPolicy("alice", "server:lobby", "join"); err != nil {
+		t.Fatal(err)
+	}
+	previous := enforcerInstance
+	enforcerInstance = e
+	t.Cleanup(func() { enforcerInstance = previous })
+}
+
+func TestRemoteBackendFailurePolicy(t *testing.T) {
+	useTestEnforcer(t)
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	tests := []struct {
+		name        string
+		err         error
+		failMode    string
+		subject     string
+		wantAllowed bool
+		wantErr     bool
+	}{
+		{"checker decides", nil, FailClosed, "bob", true, false},
+		{"fail closed denies", unavailable, FailClosed, "alice", false, true},
+		{"fail open allows", unavailable, FailOpen, "bob", true, false},
+		{"embedded enforcer allows", unavailable, FailEmbedded, "alice", true, false},
+		{"embedded enforcer denies", unavailable, FailEmbedded, "bob", false, false},
+		{"deadline falls back", status.Error(codes.DeadlineExceeded, "timeout"), FailEmbedded, "alice", true, false},
+		{"checker answers are not a failure", status.Error(codes.PermissionDenied, "role"), FailEmbedded, "alice", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestRemoteBackend(&fakeChecker{err: tt.err}, tt.failMode)
+			allowed, err := b.Authorize(context.Background(), tt.subject, "server:lobby", "join")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authorize error = %v, want error %v", err, tt.wantErr)
+			}
+			if allowed != tt.wantAllowed {
+				t.Errorf("Authorize = %v, want %v", allowed, tt.wantAllowed)
+			}
+
+			decisions, err := b.AuthorizeBatch(context.Background(), []Check{{tt.subject, "server:lobby", "join"}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AuthorizeBatch error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && decisions[0] != tt.wantAllowed {
+				t.Errorf("AuthorizeBatch = %v, want [%v]", decisions, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestRemoteBackendBreaker(t *testing.T) {
+	useTestEnforcer(t)
+	checker := &fakeChecker{err: status.Error(codes.Unavailable, "connection refused")}
+	b := newTestRemoteBackend(checker, FailEmbedded)
+
+	// Answers of the checker close the circuit instead of tripping it
+	checker.err = status.Error(codes.InvalidArgument, "bad request")
+	for range 3 {
+		_, _ = b.Authorize(context.Background(), "alice", "server:lobby", "join")
+	}
+	if b.breaker.state != breakerClosed {
+		t.Fatalf("breaker state = %d after checker errors, want closed", b.breaker.state)
+	}
+
+	// Two unreachable calls open the circuit; the checker is then no longer called
+	checker.err = status.Error(codes.Unavailable, "connection refused")
+	checker.calls = 0
+	for range 2 {
+		_, _ = b.Authorize(context.Background(), "alice", "server:lobby", "join")
+	}
+	allowed, err := b.Authorize(context.Background(), "alice", "server:lobby", "join")
+	if err != nil || !allowed {
+		t.Fatalf("Authorize with an open circuit = %v, %v; want the embedded decision", allowed, err)
+	}
+	if checker.calls != 2 {
+		t.Errorf("checker called %d times, want 2", checker.calls)
+	}
+
+	// Once the cooldown elapsed, a successful trial call recovers
+	b.breaker.cooldown = 0
+	checker.err = nil
+	if allowed, err := b.Authorize(context.Background(), "bob", "server:lobby", "join"); err != nil || !allowed {
+		t.Fatalf("trial call = %v, %v; want the checker's decision", allowed, err)
+	}
+	if b.breaker.state != breakerClosed {
+		t.Errorf("breaker state = %d after a successful trial call, want closed", b.breaker.state)
+	}
+}
@@ -0,0 +1,83 @@
+package permissions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	type step struct {
+		allow     bool // Expected result of Allow before the call
+		succeeded bool // Outcome recorded when the call is allowed
+	}
+	tests := []struct {
+		name      string
+		cooldown  time.Duration
+		steps     []step
+		wantState breakerState
+	}{
+		{
+			name:      "failures below the threshold keep the circuit closed",
+			cooldown:  time.Hour,
+			steps:     []step{{true, false}, {true, false}, {true, true}, {true, false}, {true, false}},
+			wantState: breakerClosed,
+		},
+		{
+			name:      "consecutive failures open the circuit",
+			cooldown:  time.Hour,
+			steps:     []step{{true, false}, {true, false}, {true, false}, {allow: false}},
+			wantState: breakerOpen,
+		},
+		{
+			name:      "a successful trial call closes the circuit",
+			cooldown:  0,
+			steps:     []step{{true, false}, {true, false}, {true, false}, {true, true}, {true, false}, {true, false}},
+			wantState: breakerClosed,
+		},
+		{
+			name:      "a failed trial call opens the circuit again",
+			cooldown:  0,
+			steps:     []step{{true, false}, {true, false}, {true, false}, {true, false}},
+			wantState: breakerOpen,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newCircuitBreaker(3, tt.cooldown)
+			for i, s := range tt.steps {
+				if got := b.Allow(); got != s.allow {
+					t.Fatalf("step %d: Allow() = %v, want %v", i, got, s.allow)
+				}
+				if !s.allow {
+					continue
+				}
+				if s.succeeded {
+					b.Success()
+				} else {
+					b.Failure()
+				}
+			}
+			if b.state != tt.wantState {
+				t.Errorf("state = %d, want %d", b.state, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	b := newCircuitBreaker(1, 0)
+	b.Failure()
+	if !b.Allow() {
+		t.Fatal("trial call rejected after the cooldown")
+	}
+	if b.state != breakerHalfOpen {
+		t.Fatalf("state = %d, want half-open", b.state)
+	}
+	if b.Allow() {
+		t.Fatal("second call allowed while the trial call is in flight")
+	}
+	b.Success()
+	if !b.Allow() || !b.Allow() {
+		t.Fatal("calls rejected after a successful trial call")
+	}
+}
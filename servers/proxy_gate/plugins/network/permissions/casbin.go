@@ -1,11 +1,14 @@
 package permissions
 
 import (
-	"context" // Keep context if needed for future adapter/enforcer options
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 
+	"github.com/bafbi/minecraft-network/pkg/policywatch"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/util" // For KeyMatchFunc
 	redisadapter "github.com/casbin/redis-adapter/v3"
@@ -22,9 +25,10 @@ const (
 var (
 	// enforcerInstance is the singleton Casbin enforcer.
 	// It's private to this package; access via GetEnforcer().
-	enforcerInstance *casbin.Enforcer
-	// Store the NATS connection if PublishPolicyUpdate needs it and it's not passed every time.
-	// For now, let's pass it to PublishPolicyUpdate.
+	// Event handlers, commands and the policy watcher use it concurrently, so it is synchronized.
+	enforcerInstance *casbin.SyncedEnforcer
+	// watcherInstance publishes policy changes made through enforcerInstance.
+	watcherInstance *policywatch.NATSWatcher
 )
 
 // InitCasbin initializes the Casbin enforcer, adapter, custom functions,
@@ -68,7 +72,7 @@ func InitCasbin(
 	log.Info("Casbin Redis adapter created")
 
 	var err error
-	enforcerInstance, err = casbin.NewEnforcer(modelPath, adapter)
+	enforcerInstance, err = casbin.NewSyncedEnforcer(modelPath, adapter)
 	if err != nil {
 		return fmt.Errorf("failed to create Casbin enforcer: %w", err)
 	}
@@ -77,7 +81,8 @@ func InitCasbin(
 	// Initialize dependencies for custom Casbin functions (from custom_functions.go)
 	InitCustomFunctionDeps(
 		func() logr.Logger { return log.WithName("CasbinCustomFunc") },
-		func() *casbin.Enforcer { return enforcerInstance },
+		// Custom functions run within Enforce, which already holds the enforcer's lock
+		func() *casbin.Enforcer { return enforcerInstance.Enforcer },
 	)
 	log.Info("Custom Casbin function dependencies initialized")
 
@@ -93,23 +98,21 @@ func InitCasbin(
 	log.Info("Casbin policies loaded successfully from Redis")
 
 	if nc != nil {
-		_, errSub := nc.Subscribe(casbinPolicyUpdateSubject, func(msg *nats.Msg) {
-			log.Info("Received Casbin policy update notification via NATS, reloading policies",
-				"subject", msg.Subject)
-			if enforcerInstance == nil {
-				log.Error(nil, "Enforcer instance is nil, cannot reload policies.")
-				return
-			}
-			if errReload := enforcerInstance.LoadPolicy(); errReload != nil {
-				log.Error(errReload, "Error reloading Casbin policies after NATS notification")
-			} else {
-				log.Info("Successfully reloaded Casbin policies after NATS notification")
-			}
+		// Policy changes are exchanged as deltas with the webapp and the other proxy replicas.
+		watcherLog := slog.New(logr.ToSlogHandler(log.WithName("PolicyWatcher")))
+		watcher, errWatcher := policywatch.NewNATSWatcher(nc, casbinPolicyUpdateSubject, policywatch.Options{
+			Logger:         watcherLog,
+			ObservePublish: metrics.ObservePublish,
 		})
-		if errSub != nil {
+		if errWatcher != nil {
 			// Log as warning, don't make it fatal for plugin init
-			log.Error(errSub, "Failed to subscribe to Casbin policy updates via NATS. Live policy updates may not be received.")
+			log.Error(errWatcher, "Failed to create Casbin policy watcher. Live policy updates may not be received.")
+		} else if errWatcher = enforcerInstance.SetWatcher(watcher); errWatcher != nil {
+			watcher.Close()
+			log.Error(errWatcher, "Failed to set Casbin policy watcher. Live policy updates may not be received.")
 		} else {
+			_ = watcher.SetUpdateCallback(policywatch.DefaultUpdateCallback(enforcerInstance, watcherLog))
+			watcherInstance = watcher
 			log.Info("Subscribed to Casbin policy updates via NATS", "subject", casbinPolicyUpdateSubject)
 		}
 	} else {
@@ -122,17 +125,19 @@ func InitCasbin(
 
 // GetEnforcer returns the global Casbin enforcer instance.
 // Ensure InitCasbin has been called and was successful.
-func GetEnforcer() *casbin.Enforcer {
+func GetEnforcer() *casbin.SyncedEnforcer {
 	return enforcerInstance
 }
 
-// PublishPolicyUpdate sends a notification via NATS that Casbin policies might have changed.
-func PublishPolicyUpdate(log logr.Logger, nc *nats.Conn) {
-	if nc == nil {
-		log.Error(nil, "NATS connection is nil, cannot publish Casbin policy update")
+// PublishPolicyUpdate asks all replicas to reload their policies from the adapter.
+// Changes made through the enforcer's management API are published automatically as deltas;
+// this is only needed after writing to the adapter directly.
+func PublishPolicyUpdate(log logr.Logger) {
+	if watcherInstance == nil {
+		log.Error(nil, "Casbin policy watcher not initialized, cannot publish policy update")
 		return
 	}
-	if err := nc.Publish(casbinPolicyUpdateSubject, []byte("reload_policies")); err != nil {
+	if err := watcherInstance.Update(); err != nil {
 		log.Error(err, "Failed to publish Casbin policy update notification to NATS")
 	} else {
 		log.V(1).Info("Published Casbin policy update notification to NATS")
@@ -140,29 +145,47 @@ func PublishPolicyUpdate(log logr.Logger, nc *nats.Conn) {
 }
 
 // HasPermission checks if a subject (player ID string) has a specific permission.
+// The decision is made by the configured authorization backend (see InitBackend).
 func HasPermission(subjectID string, objectResource string, action string, log logr.Logger) (bool, error) {
-	e := GetEnforcer()
-	if e == nil {
+	b := GetBackend()
+	if b == nil {
 		// Log with the passed-in logger for better context
-		log.Error(nil, "Casbin enforcer not initialized, denying permission check.",
+		log.Error(nil, "Authorization backend not initialized, denying permission check.",
 			"subject", subjectID, "object", objectResource, "action", action)
-		return false, errors.New("casbin enforcer not initialized")
+		return false, errors.New("authorization backend not initialized")
 	}
 
-	allowed, err := e.Enforce(subjectID, objectResource, action)
+	allowed, err := b.Authorize(context.Background(), subjectID, objectResource, action)
 	if err != nil {
-		log.Error(err, "Error during Casbin Enforce check",
-			"subject", subjectID, "object", objectResource, "action", action)
+		log.Error(err, "Error during permission check",
+			"backend", b.Name(), "subject", subjectID, "object", objectResource, "action", action)
 		return false, err
 	}
 
 	// Logging verbosity can be controlled by the logger's configuration
 	if !allowed {
-		log.V(1).Info("Permission denied by Casbin",
-			"subject", subjectID, "object", objectResource, "action", action)
+		log.V(1).Info("Permission denied",
+			"backend", b.Name(), "subject", subjectID, "object", objectResource, "action", action)
 	} else {
-		log.V(1).Info("Permission allowed by Casbin",
-			"subject", subjectID, "object", objectResource, "action", action)
+		log.V(1).Info("Permission allowed",
+			"backend", b.Name(), "subject", subjectID, "object", objectResource, "action", action)
 	}
 	return allowed, nil
 }
+
+// HasPermissions evaluates several checks in a single backend call, e.g. to filter
+// command suggestions or server lists. Decisions are returned in the order of checks.
+func HasPermissions(checks []Check, log logr.Logger) ([]bool, error) {
+	b := GetBackend()
+	if b == nil {
+		log.Error(nil, "Authorization backend not initialized, denying permission checks.", "checks", len(checks))
+		return nil, errors.New("authorization backend not initialized")
+	}
+
+	decisions, err := b.AuthorizeBatch(context.Background(), checks)
+	if err != nil {
+		log.Error(err, "Error during batch permission check", "backend", b.Name(), "checks", len(checks))
+		return nil, err
+	}
+	return decisions, nil
+}
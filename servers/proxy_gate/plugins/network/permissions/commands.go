@@ -3,8 +3,10 @@ package permissions
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/players"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/util/metadata"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/util/mini"
 	"github.com/go-logr/logr"
 	"go.minekube.com/brigodier"
@@ -18,6 +20,7 @@ import (
 func RegisterCommands(p *proxy.Proxy, log logr.Logger) {
 	log.Info("Registering permission-specific commands")
 	p.Command().Register(registerPermissionTestCommand(p, log))
+	CommandRegistry.Set("permission", metadata.Metadata{Labels: map[string]string{"command/category": "admin"}})
 }
 
 func registerPermissionTestCommand(p *proxy.Proxy, log logr.Logger) brigodier.LiteralNodeBuilder {
@@ -25,42 +28,13 @@ func registerPermissionTestCommand(p *proxy.Proxy, log logr.Logger) brigodier.Li
 		sender := ctx.Source
 		currentEnforcer := GetEnforcer()
 
-		// Protect this command (same logic as before)
-		if playerSender, ok := sender.(proxy.Player); ok {
-			if currentEnforcer == nil {
-				return sender.SendMessage(mini.Parse("<red>Casbin enforcer is not initialized. Cannot check command permission.</red>"))
-			}
-			isAdmin, err := currentEnforcer.HasRoleForUser(playerSender.ID().String(), "group:admin")
-			if err != nil {
-				log.Error(err, "Error checking admin role for permission check command", "player", playerSender.Username())
-				return sender.SendMessage(mini.Parse("<red>Error checking your permissions to run this command.</red>"))
-			}
-			if !isAdmin {
-				return sender.SendMessage(mini.Parse("<red>You must be in 'group:admin' to use this command.</red>"))
-			}
+		if !checkAdmin(sender, log) {
+			return nil
 		}
 
-		subjectArg := ctx.String("subject_id")
+		subjectID := resolveSubject(ctx.String("subject_id"))
 		objectResource := ctx.String("object_resource")
 		action := ctx.String("action")
-		var subjectID string
-
-		// Resolve subjectArg using players API
-		parsedUUID, parseErr := uuid.Parse(subjectArg)
-		if parseErr == nil {
-			subjectID = parsedUUID.String()
-		} else {
-			// Try to resolve by name using players API
-			if meta, found := players.GetMetadataByName(subjectArg); found {
-				if uuidStr, ok := meta.Annotations["player/uuid"]; ok && uuidStr != "" {
-					subjectID = uuidStr
-				} else {
-					subjectID = subjectArg
-				}
-			} else {
-				subjectID = subjectArg
-			}
-		}
 
 		if currentEnforcer == nil {
 			return sender.SendMessage(mini.Parse("<red>Casbin enforcer is not initialized.</red>"))
@@ -90,7 +64,155 @@ func registerPermissionTestCommand(p *proxy.Proxy, log logr.Logger) brigodier.Li
 			Then(brigodier.Argument("subject_id", brigodier.String).Suggests(suggestSubjectsDirect())).
 			Then(brigodier.Argument("object_resource", brigodier.StringPhrase)).
 			Then(brigodier.Argument("action", brigodier.StringWord).
-				Executes(executor)))
+				Executes(executor))).
+		Then(brigodier.Literal("group").
+			Then(brigodier.Literal("add").
+				Then(brigodier.Argument("subject_id", brigodier.StringWord).Suggests(suggestSubjectsDirect()).
+					Then(brigodier.Argument("group", brigodier.StringWord).
+						Executes(groupAddCommand(log)).
+						Then(brigodier.Argument("duration", brigodier.StringWord).
+							Executes(groupAddCommand(log)))))).
+			Then(brigodier.Literal("remove").
+				Then(brigodier.Argument("subject_id", brigodier.StringWord).Suggests(suggestSubjectsDirect()).
+					Then(brigodier.Argument("group", brigodier.StringWord).
+						Executes(groupRemoveCommand(log))))))
+}
+
+// groupAddCommand handles `/permission group add <player> <group> [duration]`. With a duration
+// (e.g. 2h or 7d) the assignment is time-bound: the permissions-checker removes it once expired.
+// Adding an existing assignment again replaces its expiry.
+func groupAddCommand(log logr.Logger) brigodier.Command {
+	return command.Command(func(ctx *command.Context) error {
+		sender := ctx.Source
+		if !checkAdmin(sender, log) {
+			return nil
+		}
+		e := GetEnforcer()
+		if e == nil {
+			return sender.SendMessage(mini.Parse("<red>Casbin enforcer is not initialized.</red>"))
+		}
+
+		subjectID := resolveSubject(ctx.String("subject_id"))
+		group := ctx.String("group")
+		if !strings.HasPrefix(group, "group:") {
+			group = "group:" + group
+		}
+		var expiresAt time.Time
+		if durationArg := ctx.String("duration"); durationArg != "" {
+			duration, err := ParseGrantDuration(durationArg)
+			if err != nil {
+				return sender.SendMessage(mini.Parse(fmt.Sprintf("<red>%s</red>", err.Error())))
+			}
+			expiresAt = time.Now().Add(duration)
+		}
+
+		rule := []string{subjectID, group}
+		// Record the expiry first: an assignment must not outlive a grant that failed to be stored
+		if expiresAt.IsZero() {
+			if err := ClearGrantExpiry("g", "g", rule); err != nil {
+				log.Error(err, "Failed to make group assignment permanent", "subject", subjectID, "group", group)
+				return sender.SendMessage(mini.Parse("<red>Error updating the assignment's expiry.</red>"))
+			}
+		} else if err := SetGrantExpiry("g", "g", rule, expiresAt, commandAuthor(sender)); err != nil {
+			log.Error(err, "Failed to record group assignment expiry", "subject", subjectID, "group", group)
+			return sender.SendMessage(mini.Parse(fmt.Sprintf("<red>Error recording the expiry: %s</red>", err.Error())))
+		}
+		added, err := e.AddGroupingPolicy(subjectID, group)
+		if err != nil {
+			log.Error(err, "Failed to add group assignment", "subject", subjectID, "group", group)
+			return sender.SendMessage(mini.Parse(fmt.Sprintf("<red>Error adding the assignment: %s</red>", err.Error())))
+		}
+		log.Info("Group assignment added from command", "sender", commandAuthor(sender), "subject", subjectID, "group", group, "expires_at", expiresAt, "new", added)
+
+		until := "permanently"
+		if !expiresAt.IsZero() {
+			until = "until " + expiresAt.Format("2006-01-02 15:04 MST")
+		}
+		verb := "Added"
+		if !added {
+			verb = "Updated"
+		}
+		return sender.SendMessage(mini.Parse(fmt.Sprintf("<green>%s '<yellow>%s</yellow>' to '<yellow>%s</yellow>' %s.</green>", verb, subjectID, group, until)))
+	})
+}
+
+// groupRemoveCommand handles `/permission group remove <player> <group>`.
+func groupRemoveCommand(log logr.Logger) brigodier.Command {
+	return command.Command(func(ctx *command.Context) error {
+		sender := ctx.Source
+		if !checkAdmin(sender, log) {
+			return nil
+		}
+		e := GetEnforcer()
+		if e == nil {
+			return sender.SendMessage(mini.Parse("<red>Casbin enforcer is not initialized.</red>"))
+		}
+
+		subjectID := resolveSubject(ctx.String("subject_id"))
+		group := ctx.String("group")
+		if !strings.HasPrefix(group, "group:") {
+			group = "group:" + group
+		}
+		removed, err := e.RemoveGroupingPolicy(subjectID, group)
+		if err != nil {
+			log.Error(err, "Failed to remove group assignment", "subject", subjectID, "group", group)
+			return sender.SendMessage(mini.Parse(fmt.Sprintf("<red>Error removing the assignment: %s</red>", err.Error())))
+		}
+		if err := ClearGrantExpiry("g", "g", []string{subjectID, group}); err != nil {
+			log.Error(err, "Failed to delete group assignment expiry", "subject", subjectID, "group", group)
+		}
+		if !removed {
+			return sender.SendMessage(mini.Parse(fmt.Sprintf("<yellow>'%s' is not directly in '%s'.</yellow>", subjectID, group)))
+		}
+		log.Info("Group assignment removed from command", "sender", commandAuthor(sender), "subject", subjectID, "group", group)
+		return sender.SendMessage(mini.Parse(fmt.Sprintf("<green>Removed '<yellow>%s</yellow>' from '<yellow>%s</yellow>'.</green>", subjectID, group)))
+	})
+}
+
+// checkAdmin reports whether sender may run permission commands: the console and players in
+// group:admin. Other senders are told why they cannot.
+func checkAdmin(sender command.Source, log logr.Logger) bool {
+	playerSender, ok := sender.(proxy.Player)
+	if !ok {
+		return true
+	}
+	if GetEnforcer() == nil {
+		_ = sender.SendMessage(mini.Parse("<red>Casbin enforcer is not initialized. Cannot check command permission.</red>"))
+		return false
+	}
+	isAdmin, err := IsMember(playerSender.ID().String(), "group:admin", "")
+	if err != nil {
+		log.Error(err, "Error checking admin role for permission command", "player", playerSender.Username())
+		_ = sender.SendMessage(mini.Parse("<red>Error checking your permissions to run this command.</red>"))
+		return false
+	}
+	if !isAdmin {
+		_ = sender.SendMessage(mini.Parse("<red>You must be in 'group:admin' to use this command.</red>"))
+		return false
+	}
+	return true
+}
+
+// resolveSubject returns the UUID of the player named by arg (a UUID or a known player name),
+// or arg itself for other subjects such as groups.
+func resolveSubject(arg string) string {
+	if parsedUUID, err := uuid.Parse(arg); err == nil {
+		return parsedUUID.String()
+	}
+	if meta, found := players.GetMetadataByName(arg); found {
+		if uuidStr, ok := meta.Annotations["player/uuid"]; ok && uuidStr != "" {
+			return uuidStr
+		}
+	}
+	return arg
+}
+
+// commandAuthor identifies the sender of a command as the author of the changes it makes.
+func commandAuthor(sender command.Source) string {
+	if playerSender, ok := sender.(proxy.Player); ok {
+		return "player:" + playerSender.Username()
+	}
+	return "console"
 }
 
 func suggestSubjectsDirect() brigodier.SuggestionProvider {
@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/players"
-	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/servers"
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/govaluate"
 	"github.com/go-logr/logr"
@@ -15,6 +14,7 @@ import (
 
 // --- Getter Interfaces (remain the same) ---
 
+// CasbinEnforcerGetter returns the enforcer without its lock, for functions called while enforcing.
 type CasbinEnforcerGetter func() *casbin.Enforcer
 
 var (
@@ -72,8 +72,7 @@ func EvalSubjectAttributes(args ...interface{}) (interface{}, error) {
 		annotations = make(map[string]string)
 	}
 
-	enforcer := getEnforcer()
-	if enforcer == nil {
+	if getEnforcer() == nil {
 		log.Error(nil, "Casbin enforcer is not available")
 		return false, errors.New("enforcer not available")
 	}
@@ -82,22 +81,24 @@ func EvalSubjectAttributes(args ...interface{}) (interface{}, error) {
 		"uuid":        playerUUIDStr, // The player's UUID
 		"labels":      labels,        // Player's labels map
 		"annotations": annotations,   // Player's annotations map
-		// Add a helper function for group checks within govaluate expressions
-		"inGroup": func(groupName string) bool {
-			// Note: govaluate function arguments are passed as []interface{}
-			// This is a simplified example; real group check might need more robust arg handling.
-			// For HasRoleForUser, we need playerUUIDStr and groupName.
-			// The `groupName` comes from the expression, e.g., `inGroup('admin')`
-			res, errGH := enforcer.HasRoleForUser(playerUUIDStr, groupName)
-			if errGH != nil {
-				log.Error(errGH, "Error checking Casbin group membership in govaluate", "group", groupName)
-				return false
-			}
-			return res
-		},
 	}
 
-	expression, err := govaluate.NewEvaluableExpression(evalLogic)
+	functions := metadataFunctions(labels, annotations)
+	// inGroup checks the player's group membership (see IsMember), e.g. `inGroup('group:admin')`
+	functions["inGroup"] = groupFunction("inGroup", playerUUIDStr, "")
+	// inGroupIn also considers assignments within a server group, e.g. `inGroupIn('group:mod', 'survival')`
+	functions["inGroupIn"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("inGroupIn: expected 2 arguments, got %d", len(args))
+		}
+		domain, ok := args[1].(string)
+		if !ok {
+			return nil, errors.New("inGroupIn: arguments must be strings")
+		}
+		return groupFunction("inGroupIn", playerUUIDStr, domain)(args[0])
+	}
+
+	expression, err := govaluate.NewEvaluableExpressionWithFunctions(evalLogic, functions)
 	if err != nil {
 		log.Error(err, "Failed to parse subject evaluation logic as govaluate expression")
 		return false, fmt.Errorf("failed to parse subject eval logic '%s': %w", evalLogic, err)
@@ -120,6 +121,7 @@ func EvalSubjectAttributes(args ...interface{}) (interface{}, error) {
 }
 
 // EvalObjectAttributes evaluates if the object matches the given govaluate expression.
+// Object metadata is fetched through the ObjectResolver registered for the object type.
 // args[0]: r_sub (string) - The requesting subject's UUID. Needed if obj logic refers to subject.
 // args[1]: r_obj (string) - The object identifier (e.g., "server:lobby-1", "command:kick")
 // args[2]: p_obj_eval_logic (string) - The govaluate expression from the policy
@@ -163,27 +165,34 @@ func EvalObjectAttributes(args ...interface{}) (interface{}, error) {
 		"type":       objType,     // The object's type (e.g., "server", "command")
 		"name":       objName,     // The object's name (e.g., "lobby-1", "kick")
 		"r_sub_uuid": rSubUUIDStr, // Requesting subject's UUID, for policies like "labels.owner_uuid == r_sub_uuid"
-		// Initialize metadata maps to empty to prevent nil access if not found
-		"labels":      make(map[string]string),
-		"annotations": make(map[string]string),
-	}
-
-	// Populate object-specific metadata
-	switch objType {
-	case "server":
-		if objName != "" {
-			meta, found := servers.GetMetadataByName(objName)
-			if found {
-				parameters["labels"] = meta.Labels
-				parameters["annotations"] = meta.Annotations
+	}
+
+	// Populate object-specific metadata from the resolver registered for its type
+	labels := make(map[string]string)
+	annotations := make(map[string]string)
+	if resolver, ok := getObjectResolver(objType); ok {
+		if meta, found := resolver(objName); found {
+			if meta.Labels != nil {
+				labels = meta.Labels
 			}
+			if meta.Annotations != nil {
+				annotations = meta.Annotations
+			}
+		} else {
+			log.V(1).Info("Object metadata not found for evaluation", "objType", objType, "objName", objName)
 		}
-	// Other object types can be handled here if needed
-	default:
+	} else {
 		log.V(1).Info("Unknown object type for metadata fetching", "objType", objType)
 	}
+	parameters["labels"] = labels
+	parameters["annotations"] = annotations
 
-	expression, err := govaluate.NewEvaluableExpression(evalLogic)
+	functions := metadataFunctions(labels, annotations)
+	// subjectInGroup checks the requesting player's group membership within the object's
+	// server group (its server/group label), e.g. `type == 'server' && subjectInGroup('group:mod')`
+	functions["subjectInGroup"] = groupFunction("subjectInGroup", rSubUUIDStr, labels[ServerGroupLabel])
+
+	expression, err := govaluate.NewEvaluableExpressionWithFunctions(evalLogic, functions)
 	if err != nil {
 		log.Error(err, "Failed to parse object evaluation logic as govaluate expression")
 		return false, fmt.Errorf("failed to parse object eval logic '%s': %w", evalLogic, err)
@@ -202,3 +211,64 @@ func EvalObjectAttributes(args ...interface{}) (interface{}, error) {
 	log.V(1).Info("Object logic expression did not return a boolean", "result", result)
 	return false, fmt.Errorf("object eval logic '%s' did not return a boolean", evalLogic)
 }
+
+// groupFunction returns a govaluate function checking whether subject is in the group given
+// as its argument, within domain. Membership errors are logged and treated as a non-member.
+func groupFunction(fnName, subject, domain string) govaluate.ExpressionFunction {
+	return func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s: expected 1 argument, got %d", fnName, len(args))
+		}
+		group, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: argument must be a string", fnName)
+		}
+		e := getEnforcer()
+		if e == nil {
+			return false, errors.New("enforcer not available")
+		}
+		member, err := isMember(e, subject, group, domain)
+		if err != nil {
+			getLog().Error(err, "Error checking group membership in govaluate", "func", fnName, "subject", subject, "group", group, "domain", domain)
+			return false, nil
+		}
+		return member, nil
+	}
+}
+
+// metadataFunctions returns govaluate helper functions bound to the given metadata maps.
+// They allow expressions to test keys that are missing or not valid identifiers,
+// e.g. `!hasLabel('staff')` or `annotation('player/online') == 'true'`.
+func metadataFunctions(labels, annotations map[string]string) map[string]govaluate.ExpressionFunction {
+	lookup := func(fnName string, values map[string]string) govaluate.ExpressionFunction {
+		return func(args ...interface{}) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("%s: expected 1 argument, got %d", fnName, len(args))
+			}
+			key, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: argument must be a string", fnName)
+			}
+			return values[key], nil
+		}
+	}
+	exists := func(fnName string, values map[string]string) govaluate.ExpressionFunction {
+		return func(args ...interface{}) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("%s: expected 1 argument, got %d", fnName, len(args))
+			}
+			key, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: argument must be a string", fnName)
+			}
+			_, found := values[key]
+			return found, nil
+		}
+	}
+	return map[string]govaluate.ExpressionFunction{
+		"label":         lookup("label", labels),
+		"annotation":    lookup("annotation", annotations),
+		"hasLabel":      exists("hasLabel", labels),
+		"hasAnnotation": exists("hasAnnotation", annotations),
+	}
+}
@@ -0,0 +1,105 @@
+package permissions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats.go"
+)
+
+// Time-bound rules are recorded as grants in a NATS KV bucket shared with the permissions-checker,
+// whose reaper removes the rules once they expire (see services/permissions-checker/grants).
+// The reaper sends the removal to the policy watchers of this store, which apply it to the adapter.
+const grantsBucket = "permission_grants"
+
+// grantsKV is the grants bucket, nil when grants are unavailable.
+var grantsKV nats.KeyValue
+
+// Grant is the expiry of a single rule, in the format of the permissions-checker grants package.
+type Grant struct {
+	Subject   string    `json:"subject"`          // Policy update subject of the store holding the rule
+	Sec       string    `json:"sec"`              // Model section, "p" or "g"
+	Ptype     string    `json:"ptype"`            // Policy type, e.g. "p" or "g2"
+	Rule      []string  `json:"rule"`             // Rule as stored by the adapter
+	ExpiresAt time.Time `json:"expires_at"`       // Time after which the rule is removed
+	Author    string    `json:"author,omitempty"` // User or client that granted the rule
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InitGrants binds to the grants bucket, creating it if it does not exist.
+func InitGrants(js nats.JetStreamContext, log logr.Logger) error {
+	kv, err := js.KeyValue(grantsBucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		log.Info("Grants bucket not found, creating it", "bucket", grantsBucket)
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: grantsBucket, Description: "Expiry of time-bound permissions"})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get NATS KV bucket '%s': %w", grantsBucket, err)
+	}
+	grantsKV = kv
+	return nil
+}
+
+func grantKey(sec, ptype string, rule []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(append([]string{casbinPolicyUpdateSubject, sec, ptype}, rule...), "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// SetGrantExpiry makes a rule of this store expire at expiresAt.
+func SetGrantExpiry(sec, ptype string, rule []string, expiresAt time.Time, author string) error {
+	if grantsKV == nil {
+		return errors.New("time-bound permissions are not available")
+	}
+	data, err := json.Marshal(Grant{
+		Subject:   casbinPolicyUpdateSubject,
+		Sec:       sec,
+		Ptype:     ptype,
+		Rule:      rule,
+		ExpiresAt: expiresAt.UTC(),
+		Author:    author,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode grant: %w", err)
+	}
+	if _, err := grantsKV.Put(grantKey(sec, ptype, rule), data); err != nil {
+		return fmt.Errorf("failed to store grant: %w", err)
+	}
+	return nil
+}
+
+// ClearGrantExpiry makes a rule of this store permanent. It is not an error if the rule has no expiry.
+func ClearGrantExpiry(sec, ptype string, rule []string) error {
+	if grantsKV == nil {
+		return nil
+	}
+	err := grantsKV.Delete(grantKey(sec, ptype, rule))
+	if err != nil && !errors.Is(err, nats.ErrKeyNotFound) {
+		return fmt.Errorf("failed to delete grant: %w", err)
+	}
+	return nil
+}
+
+// ParseGrantDuration parses the duration of a time-bound grant, e.g. "30m", "2h" or "7d".
+func ParseGrantDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q (e.g. 30m, 2h or 7d)", s)
+	}
+	return d, nil
+}
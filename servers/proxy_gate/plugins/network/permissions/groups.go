@@ -0,0 +1,152 @@
+package permissions
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/players"
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/govaluate"
+	"go.minekube.com/gate/pkg/util/uuid"
+)
+
+// Group membership is described by three kinds of rules in the Casbin model
+// (deployment/network/common/permissions_model.conf):
+//
+//	g  = member, group          // Global assignment. Groups can be members of groups (inheritance).
+//	g2 = member, group, domain  // Assignment within a server group, e.g. "mod" only on "survival".
+//	p2 = member_eval_logic, grp, dom
+//	                            // Dynamic membership: players whose metadata matches the expression
+//	                            // (same variables as sub_eval_logic, without inGroup) are in grp,
+//	                            // within dom or everywhere when dom is "*".
+//
+// For example `p2, labels.vip == 'true', group:vip, *` puts every player labelled vip=true in group:vip.
+const (
+	// ServerGroupLabel is the server label naming its server group, used as the group domain.
+	ServerGroupLabel = "server/group"
+	// AnyDomain is the domain of membership rules that apply on every server group.
+	AnyDomain = "*"
+
+	domainGroupPType    = "g2"
+	membershipRulePType = "p2"
+)
+
+// IsMember reports whether subject (a player UUID or a group) is in group, either directly,
+// through group inheritance or through a dynamic membership rule. When domain (a server group)
+// is not empty, assignments and rules scoped to that domain are taken into account as well.
+func IsMember(subject, group, domain string) (bool, error) {
+	e := GetEnforcer()
+	if e == nil {
+		return false, errors.New("enforcer not available")
+	}
+	lock := e.GetLock()
+	lock.RLock()
+	defer lock.RUnlock()
+	return isMember(e.Enforcer, subject, group, domain)
+}
+
+// isMember is IsMember on e, whose lock must be held (it is while enforcing).
+func isMember(e *casbin.Enforcer, subject, group, domain string) (bool, error) {
+	// inherits reports whether group is reached from candidate through g (and g2 within domain).
+	inherits := func(candidate string) (bool, error) {
+		if candidate == group {
+			return true, nil
+		}
+		ok, err := e.GetRoleManager().HasLink(candidate, group)
+		if err != nil || ok {
+			return ok, err
+		}
+		if domain != "" && hasDomainGroups(e) {
+			return e.GetNamedRoleManager(domainGroupPType).HasLink(candidate, group, domain)
+		}
+		return false, nil
+	}
+
+	if ok, err := inherits(subject); err != nil || ok {
+		return ok, err
+	}
+
+	// Groups assigned within the domain may inherit the requested group globally.
+	if domain != "" && hasDomainGroups(e) {
+		roles, err := e.GetNamedRoleManager(domainGroupPType).GetRoles(subject, domain)
+		if err != nil {
+			return false, err
+		}
+		for _, role := range roles {
+			if ok, err := inherits(role); err != nil || ok {
+				return ok, err
+			}
+		}
+	}
+
+	rules, err := membershipRules(e)
+	if err != nil {
+		return false, err
+	}
+	for _, rule := range rules {
+		ruleGroup, ruleDomain := rule[1], rule[2]
+		if ruleDomain != AnyDomain && ruleDomain != domain {
+			continue
+		}
+		if ok, err := inherits(ruleGroup); err != nil || !ok {
+			if err != nil {
+				return false, err
+			}
+			continue
+		}
+		matched, err := evalMembershipRule(subject, rule[0])
+		if err != nil {
+			return false, fmt.Errorf("membership rule for %s: %w", ruleGroup, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasDomainGroups reports whether the model defines the g2 role type.
+func hasDomainGroups(e *casbin.Enforcer) bool {
+	_, ok := e.GetModel()["g"][domainGroupPType]
+	return ok
+}
+
+// membershipRules returns the p2 rules, or none if the model does not define them.
+func membershipRules(e *casbin.Enforcer) ([][]string, error) {
+	if _, ok := e.GetModel()["p"][membershipRulePType]; !ok {
+		return nil, nil
+	}
+	return e.GetNamedPolicy(membershipRulePType)
+}
+
+// evalMembershipRule evaluates a dynamic membership expression against a player's metadata.
+// Subjects that are not players (e.g. groups) never match.
+func evalMembershipRule(subject, logic string) (bool, error) {
+	playerID, err := uuid.Parse(subject)
+	if err != nil {
+		return false, nil
+	}
+	meta, _ := players.GetMetadataByUUID(playerID)
+	labels, annotations := meta.Labels, meta.Annotations
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	expression, err := govaluate.NewEvaluableExpressionWithFunctions(logic, metadataFunctions(labels, annotations))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse '%s': %w", logic, err)
+	}
+	result, err := expression.Evaluate(map[string]interface{}{
+		"uuid":        subject,
+		"labels":      labels,
+		"annotations": annotations,
+	})
+	if err != nil {
+		return false, nil // Missing metadata is a non-match, as for sub_eval_logic
+	}
+	matched, _ := result.(bool)
+	return matched, nil
+}
@@ -0,0 +1,107 @@
+package permissions
+
+import (
+	"sync"
+
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/players"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/servers"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/util/metadata"
+	"go.minekube.com/gate/pkg/util/uuid"
+)
+
+// ObjectResolver returns the metadata exposed to policies for an object.
+// name is the part of the object identifier after the type prefix
+// (e.g. "lobby-1" for "server:lobby-1").
+type ObjectResolver func(name string) (metadata.Metadata, bool)
+
+var (
+	resolversMu     sync.RWMutex
+	objectResolvers = map[string]ObjectResolver{
+		"server":  resolveServerObject,
+		"player":  resolvePlayerObject,
+		"command": CommandRegistry.Resolve,
+		"channel": ChannelRegistry.Resolve,
+	}
+)
+
+// RegisterObjectResolver registers (or replaces) the resolver used for objects of the given type.
+// It can be used by other plugins to expose custom object types to policies.
+func RegisterObjectResolver(objType string, resolver ObjectResolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	objectResolvers[objType] = resolver
+}
+
+// UnregisterObjectResolver removes the resolver for the given object type.
+func UnregisterObjectResolver(objType string) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	delete(objectResolvers, objType)
+}
+
+func getObjectResolver(objType string) (ObjectResolver, bool) {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	resolver, ok := objectResolvers[objType]
+	return resolver, ok
+}
+
+// resolveServerObject resolves "server:<name>" objects from the server cache.
+func resolveServerObject(name string) (metadata.Metadata, bool) {
+	if name == "" {
+		return metadata.Metadata{}, false
+	}
+	return servers.GetMetadataByName(name)
+}
+
+// resolvePlayerObject resolves "player:<uuid>" and "player:<name>" objects from the player cache.
+func resolvePlayerObject(name string) (metadata.Metadata, bool) {
+	if name == "" {
+		return metadata.Metadata{}, false
+	}
+	if playerID, err := uuid.Parse(name); err == nil {
+		return players.GetMetadataByUUID(playerID)
+	}
+	return players.GetMetadataByName(name)
+}
+
+// MetadataRegistry is an in-memory store of object metadata for object types
+// that are not backed by a KV store, such as commands and chat channels.
+type MetadataRegistry struct {
+	mu      sync.RWMutex
+	entries map[string]metadata.Metadata
+}
+
+// NewMetadataRegistry creates an empty MetadataRegistry.
+func NewMetadataRegistry() *MetadataRegistry {
+	return &MetadataRegistry{entries: make(map[string]metadata.Metadata)}
+}
+
+var (
+	// CommandRegistry holds the metadata of "command:<name>" objects.
+	CommandRegistry = NewMetadataRegistry()
+	// ChannelRegistry holds the metadata of "channel:<name>" objects.
+	ChannelRegistry = NewMetadataRegistry()
+)
+
+// Set stores the metadata for the given object name, replacing any previous value.
+func (r *MetadataRegistry) Set(name string, meta metadata.Metadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[name] = meta
+}
+
+// Delete removes the metadata for the given object name.
+func (r *MetadataRegistry) Delete(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, name)
+}
+
+// Resolve implements ObjectResolver.
+func (r *MetadataRegistry) Resolve(name string) (metadata.Metadata, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, ok := r.entries[name]
+	return meta, ok
+}
@@ -0,0 +1,48 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/util/metadata"
+	"github.com/go-logr/logr"
+)
+
+func TestChannelResolver(t *testing.T) {
+	ChannelRegistry.Set("staff", metadata.Metadata{Labels: map[string]string{"channel/scope": "staff"}})
+	t.Cleanup(func() { ChannelRegistry.Delete("staff") })
+	InitCustomFunctionDeps(logr.Discard, nil)
+
+	resolver, ok := getObjectResolver("channel")
+	if !ok {
+		t.Fatal("no resolver registered for channel objects")
+	}
+	meta, found := resolver("staff")
+	if !found || meta.Labels["channel/scope"] != "staff" {
+		t.Fatalf("resolver(staff) = %v, %v; want the registered metadata", meta, found)
+	}
+	if _, found := resolver("unknown"); found {
+		t.Fatal("resolver found an unregistered channel")
+	}
+
+	tests := []struct {
+		object string
+		logic  string
+		want   bool
+	}{
+		{"channel:staff", "type == 'channel' && name == 'staff'", true},
+		{"channel:staff", "label('channel/scope') == 'staff'", true},
+		{"channel:staff", "hasLabel('channel/scope')", true},
+		{"channel:unknown", "hasLabel('channel/scope')", false},
+		{"channel:staff", "label('channel/scope') == 'network'", false},
+	}
+	for _, tt := range tests {
+		got, err := EvalObjectAttributes("00000000-0000-0000-0000-000000000001", tt.object, tt.logic)
+		if err != nil {
+			t.Errorf("EvalObjectAttributes(%s, %q): %v", tt.object, tt.logic, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("EvalObjectAttributes(%s, %q) = %v, want %v", tt.object, tt.logic, got, tt.want)
+		}
+	}
+}
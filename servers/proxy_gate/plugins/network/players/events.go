@@ -3,6 +3,7 @@ package players
 import (
 	"fmt"
 
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/util/metadata"
 	"github.com/go-logr/logr"
 	"go.minekube.com/gate/pkg/edition/java/proxy"
@@ -26,6 +27,7 @@ func TrackServerConnection(
 		server := e.Server()
 		serverInfo := server.ServerInfo()
 		playerID := player.ID()
+		metrics.PlayerConnected(serverInfo.Name())
 
 		serverMeta, exists := fnGetServerMeta(serverInfo.Name())
 		if !exists {
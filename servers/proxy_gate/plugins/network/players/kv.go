@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/util/metadata"
 	"github.com/go-logr/logr"
 	"github.com/nats-io/nats.go"
@@ -51,6 +52,7 @@ func WatchKVStore() {
 		if entry == nil { // Should not happen with WatchAll but good practice
 			continue
 		}
+		metrics.ObserveKVEntry("players", entry)
 		playerUUID, err := uuid.Parse(entry.Key())
 		if err != nil {
 			kvLog.Error(err, "Failed to parse player UUID from KV key", "key", entry.Key())
@@ -4,8 +4,12 @@ import (
 	"context"
 	// "errors" // No longer needed for HasPermission here
 	"fmt"
+	"log/slog"
 	"os"
+	"time"
 
+	"github.com/bafbi/minecraft-network/pkg/observability"
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/permissions"
 
 	"github.com/go-logr/logr"
@@ -30,13 +34,36 @@ var Plugin = proxy.Plugin{
 		pluginLog = baseLog.WithName("NetworkPlugin")
 		pluginLog.Info("Network plugin starting")
 
+		shutdownTracing, err := observability.SetupTracing(ctx, "proxy", slog.New(logr.ToSlogHandler(pluginLog.WithName("Tracing"))))
+		if err != nil {
+			return err
+		}
+		go func() {
+			<-ctx.Done()
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(flushCtx); err != nil {
+				pluginLog.Error(err, "Failed to flush traces")
+			}
+		}()
+
+		// Prometheus metrics on METRICS_ADDR (default ":9090", "off" disables the endpoint)
+		if err := metrics.Init(p); err != nil {
+			return fmt.Errorf("failed to register metrics: %w", err)
+		}
+		if metricsAddr := os.Getenv("METRICS_ADDR"); metricsAddr != "off" {
+			if metricsAddr == "" {
+				metricsAddr = ":9090"
+			}
+			go metrics.Serve(ctx, metricsAddr, pluginLog.WithName("Metrics"))
+		}
+
 		// NATS Connection (remains the same)
 		natsURL := os.Getenv("NATS_URL")
 		if natsURL == "" {
 			natsURL = "nats://network-nats:4222"
 			pluginLog.Info("NATS_URL not set, using default", "url", natsURL)
 		}
-		var err error
 		nc, err = nats.Connect(natsURL)
 		if err != nil {
 			return fmt.Errorf("failed to connect to NATS: %w", err)
@@ -68,7 +95,7 @@ var Plugin = proxy.Plugin{
 		}
 
 		// Initialize Permission System (Casbin, commands, etc.)
-		if err := InitPermissionSystem(ctx, p, nc, pluginLog.WithName("Permissions")); err != nil {
+		if err := InitPermissionSystem(ctx, p, nc, js, pluginLog.WithName("Permissions")); err != nil {
 			return err
 		}
 
@@ -90,7 +117,7 @@ var Plugin = proxy.Plugin{
 	},
 }
 
-// publishPolicyUpdate function removed, use permissions.PublishPolicyUpdate directly
+// publishPolicyUpdate function removed; policy changes are published by the permissions watcher
 
 // registerEventHandlers - update PostLoginEvent example
 func registerEventHandlers(p *proxy.Proxy, log logr.Logger) error {
@@ -113,12 +140,8 @@ func registerEventHandlers(p *proxy.Proxy, log logr.Logger) error {
 		// 	if errAdd != nil {
 		// 		log.Error(errAdd, "Failed to add default group to player", "player", playerIDStr)
 		// 	} else if added {
+		// 		// Saved by the adapter and published to the other replicas by the policy watcher
 		// 		log.Info("Added default group to player", "player", playerIDStr)
-		//      if errSave := casbinEnforcer.SavePolicy(); errSave != nil {
-		//          log.Error(errSave, "Failed to save policy after adding default group")
-		//      } else {
-		//          permissions.PublishPolicyUpdate(log, nc) // Use new function
-		//      }
 		// 	}
 		// }
 		log.V(1).Info("Player post-login event processed for permissions", "player", playerIDStr)
@@ -13,7 +13,7 @@ func InitServerSystem(p *proxy.Proxy, js nats.JetStreamContext, log logr.Logger)
 	if err := servers.InitializeKVStore(js, log.WithName("KV")); err != nil {
 		return err
 	}
-	servers.InitEvents(p, log.WithName("Events"))
+	servers.InitEvents(p, log.WithName("Events"), canConnectToServer(log.WithName("ConnectAuthorizer")))
 	go servers.WatchKVStore(p)
 	log.Info("Server system initialized")
 	return nil
@@ -76,6 +76,24 @@ func GetRandomDefaultServer() (proxy.RegisteredServer, bool) {
 	return defaultServers[rand.Intn(len(defaultServers))], true
 }
 
+// GetRandomDefaultServerFor returns a random server matching the default selector
+// that the player is allowed to connect to, skipping the excluded server name.
+// Candidates are tried in random order so a denied server falls through to the next one.
+func GetRandomDefaultServerFor(player proxy.Player, exclude string) (proxy.RegisteredServer, bool) {
+	defaultServers := FindServersByLabels(DefaultServerSelector)
+	for _, i := range rand.Perm(len(defaultServers)) {
+		candidate := defaultServers[i]
+		name := candidate.ServerInfo().Name()
+		if name == exclude {
+			continue
+		}
+		if canConnect(player, name) {
+			return candidate, true
+		}
+	}
+	return nil, false
+}
+
 // GetAllRegisteredServers returns all servers currently registered with Gate.
 func GetAllRegisteredServers() []proxy.RegisteredServer {
 	return getAllRegisteredServersFromCache() // from cache.go
@@ -1,6 +1,9 @@
 package servers
 
 import (
+	"fmt"
+
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
 	"github.com/go-logr/logr"
 	"github.com/robinbraemer/event"
 	"go.minekube.com/common/minecraft/color"
@@ -8,13 +11,25 @@ import (
 	"go.minekube.com/gate/pkg/edition/java/proxy"
 )
 
-var eventsLog logr.Logger
+// ConnectAuthorizer reports whether a player is allowed to connect to the named server.
+type ConnectAuthorizer func(player proxy.Player, serverName string) bool
+
+var (
+	eventsLog logr.Logger
+	// canConnect allows every connection until InitEvents is given an authorizer.
+	canConnect ConnectAuthorizer = func(proxy.Player, string) bool { return true }
+)
 
 // InitEvents initializes the server events module.
-func InitEvents(p *proxy.Proxy, log logr.Logger) {
+// authorize is consulted for every server connection (initial, command, kick redirect).
+func InitEvents(p *proxy.Proxy, log logr.Logger, authorize ConnectAuthorizer) {
 	eventsLog = log.WithName("ServerEvents")
+	if authorize != nil {
+		canConnect = authorize
+	}
 
 	event.Subscribe(p.Event(), 0, playerChooseInitialServer(p, log.WithName("PlayerChooseInitialServer")))
+	event.Subscribe(p.Event(), 0, handleServerPreConnectEvent(log.WithName("ServerPreConnect")))
 	event.Subscribe(p.Event(), 0, handleKickedFromServerEvent(p, log.WithName("KickedFromServer")))
 }
 
@@ -22,7 +37,7 @@ func InitEvents(p *proxy.Proxy, log logr.Logger) {
 func playerChooseInitialServer(p *proxy.Proxy, log logr.Logger) func(*proxy.PlayerChooseInitialServerEvent) {
 	return func(e *proxy.PlayerChooseInitialServerEvent) {
 		player := e.Player()
-		chosen, found := GetRandomDefaultServer() // Uses API
+		chosen, found := GetRandomDefaultServerFor(player, "") // Uses API
 
 		if !found {
 			log.Info("No default servers available for initial connection", "player", player.Username())
@@ -39,8 +54,13 @@ func playerChooseInitialServer(p *proxy.Proxy, log logr.Logger) func(*proxy.Play
 
 func handleKickedFromServerEvent(p *proxy.Proxy, log logr.Logger) func(e *proxy.KickedFromServerEvent) {
 	return func(e *proxy.KickedFromServerEvent) {
-		// Find any server with type=lobby label as fallback
-		fallbackServers, some := GetRandomDefaultServer()
+		// Find any server with type=lobby label the player may join as fallback
+		var kickedFrom string
+		if e.Server() != nil {
+			kickedFrom = e.Server().ServerInfo().Name()
+		}
+		metrics.PlayerKicked(kickedFrom)
+		fallbackServers, some := GetRandomDefaultServerFor(e.Player(), kickedFrom)
 		if !some {
 			e.SetResult(&proxy.DisconnectPlayerKickResult{
 				Reason: &c.Text{Content: "No available server to redirect to."},
@@ -49,6 +69,7 @@ func handleKickedFromServerEvent(p *proxy.Proxy, log logr.Logger) func(e *proxy.
 			return
 		}
 
+		metrics.PlayerRedirected(fallbackServers.ServerInfo().Name())
 		e.SetResult(&proxy.RedirectPlayerKickResult{
 			Server: fallbackServers,
 			Message: &c.Text{
@@ -65,3 +86,24 @@ func handleKickedFromServerEvent(p *proxy.Proxy, log logr.Logger) func(e *proxy.
 
 	}
 }
+
+// handleServerPreConnectEvent denies connections the player is not authorized for.
+func handleServerPreConnectEvent(log logr.Logger) func(e *proxy.ServerPreConnectEvent) {
+	return func(e *proxy.ServerPreConnectEvent) {
+		if !e.Allowed() || e.Server() == nil {
+			return
+		}
+		player := e.Player()
+		serverName := e.Server().ServerInfo().Name()
+		if canConnect(player, serverName) {
+			return
+		}
+
+		e.Deny()
+		log.Info("Denied server connection", "player", player.Username(), "server", serverName)
+		_ = player.SendMessage(&c.Text{
+			Content: fmt.Sprintf("You are not allowed to connect to %s.", serverName),
+			S:       c.Style{Color: color.Red},
+		})
+	}
+}
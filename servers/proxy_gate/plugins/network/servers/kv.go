@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/bafbi/minecraft-network/servers/proxy_gate/plugins/network/metrics"
 	"github.com/bafbi/minecraft-network/servers/proxy_gate/util/metadata"
 	"go.minekube.com/gate/pkg/edition/java/proxy"
 
@@ -52,6 +53,7 @@ func WatchKVStore(p *proxy.Proxy) { // Needs proxy.Proxy to pass to registry fun
 		if entry == nil {
 			continue
 		}
+		metrics.ObserveKVEntry("servers", entry)
 		serverName := entry.Key()
 
 		if entry.Operation() == nats.KeyValueDelete {
@@ -15,6 +15,7 @@ docker buildx build \
   --platform linux/amd64 \
   --load \
   -t "${IMAGE_NAME}" \
+  --build-context pkg="${PROJECT_DIR}/../../pkg" \
   "${PROJECT_DIR}"
 
 echo "📦 Loading image into kind cluster '${KIND_CLUSTER_NAME}'..."
@@ -0,0 +1,157 @@
+// Package attributes builds the request attributes condition expressions are evaluated against.
+//
+// Player and server metadata are normalized into the labels/annotations model shared with the
+// proxy (see servers/proxy_gate/util/metadata): string labels for selection, and string
+// annotations for everything else, lists being JSON-encoded. Metadata written in that model
+// ({"Labels": {...}, "Annotations": {...}}) is taken as is; other top-level values of older,
+// flat metadata become labels (scalars) or annotations (lists and objects), and also stay
+// available under their own name so that existing expressions such as r.player.role still work.
+//
+// Expressions see:
+//
+//	r.player: uuid, name, labels, annotations
+//	r.server: name, labels, annotations
+//	r.ctx:    server, proxy, request_id, time (RFC 3339), unix, hour, weekday (UTC)
+//
+// and may call the functions of Functions, e.g. hasLabel(r.server, "server/group", "survival")
+// for keys that are not valid identifiers.
+package attributes
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Well-known annotations, as written by the proxy.
+const (
+	PlayerNameAnnotation = "player/name"
+	OnlineAnnotation     = "player/online"      // @type bool
+	LocationAnnotation   = "network/location"   // Server the player is connected to
+	GroupsAnnotation     = "permissions/groups" // @type []string, groups the player is a member of
+)
+
+// Attribute names of the normalized model.
+const (
+	LabelsKey      = "labels"
+	AnnotationsKey = "annotations"
+)
+
+// Metadata is player or server metadata in the labels/annotations model.
+type Metadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// Normalize converts metadata read from NATS KV (see the package documentation).
+func Normalize(raw map[string]any) Metadata {
+	m := Metadata{Labels: make(map[string]string), Annotations: make(map[string]string)}
+	for key, value := range raw {
+		switch {
+		case isModelKey(key, LabelsKey):
+			copyStrings(m.Labels, value)
+		case isModelKey(key, AnnotationsKey):
+			copyStrings(m.Annotations, value)
+		}
+	}
+	for key, value := range raw {
+		if isModelKey(key, LabelsKey) || isModelKey(key, AnnotationsKey) || value == nil {
+			continue
+		}
+		switch value.(type) {
+		case []any, map[string]any:
+			if _, ok := m.Annotations[key]; !ok {
+				m.Annotations[key] = stringify(value)
+			}
+		default:
+			if _, ok := m.Labels[key]; !ok {
+				m.Labels[key] = stringify(value)
+			}
+		}
+	}
+	return m
+}
+
+// Player returns the r.player attributes of a player.
+func Player(uuid, name string, raw map[string]any) map[string]any {
+	m := Normalize(raw)
+	if name == "" {
+		name = m.Annotations[PlayerNameAnnotation]
+	}
+	return object(raw, m, map[string]any{"uuid": uuid, "player_uuid": uuid, "name": name})
+}
+
+// Server returns the r.server attributes of a server.
+func Server(name string, raw map[string]any) map[string]any {
+	return object(raw, Normalize(raw), map[string]any{"name": name})
+}
+
+// Context returns the r.ctx attributes of a request made by proxy for server at now.
+func Context(server, proxy, requestID string, now time.Time) map[string]any {
+	now = now.UTC()
+	return map[string]any{
+		"server":     server,
+		"proxy":      proxy,
+		"request_id": requestID,
+		"time":       now.Format(time.RFC3339),
+		"unix":       float64(now.Unix()),
+		"hour":       float64(now.Hour()),
+		"weekday":    strings.ToLower(now.Weekday().String()),
+	}
+}
+
+// object merges the legacy flat values of raw, the normalized model and fields.
+func object(raw map[string]any, m Metadata, fields map[string]any) map[string]any {
+	attrs := make(map[string]any, len(raw)+len(fields)+2)
+	for key, value := range raw {
+		if !isModelKey(key, LabelsKey) && !isModelKey(key, AnnotationsKey) {
+			attrs[key] = value
+		}
+	}
+	attrs[LabelsKey] = m.Labels
+	attrs[AnnotationsKey] = m.Annotations
+	for key, value := range fields {
+		attrs[key] = value
+	}
+	return attrs
+}
+
+// isModelKey matches the normalized key and the field name of the proxy's Metadata struct.
+func isModelKey(key, name string) bool {
+	return key == name || key == strings.ToUpper(name[:1])+name[1:]
+}
+
+func copyStrings(dst map[string]string, value any) {
+	switch values := value.(type) {
+	case map[string]any:
+		for k, v := range values {
+			if v != nil {
+				dst[k] = stringify(v)
+			}
+		}
+	case map[string]string:
+		for k, v := range values {
+			dst[k] = v
+		}
+	}
+}
+
+func stringify(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
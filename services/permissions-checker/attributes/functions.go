@@ -0,0 +1,150 @@
+package attributes
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/govaluate"
+)
+
+// GroupPrefix is the prefix of group subjects, optional in inGroup.
+const GroupPrefix = "group:"
+
+// Functions returns the functions condition expressions may call on r.player and r.server:
+//
+//	hasLabel(obj, key)         the label is set
+//	hasLabel(obj, key, value)  the label is set to value
+//	label(obj, key)            the label value, "" when unset
+//	hasAnnotation(obj, key)    the annotation is set
+//	annotation(obj, key)       the annotation value, "" when unset
+//	inGroup(player, group)     the player's permissions/groups annotation lists group ("vip" or "group:vip")
+//	online(player)             the player is connected to the network
+func Functions() map[string]govaluate.ExpressionFunction {
+	return map[string]govaluate.ExpressionFunction{
+		"hasLabel":      hasLabel,
+		"label":         lookup("label", LabelsKey),
+		"hasAnnotation": exists("hasAnnotation", AnnotationsKey),
+		"annotation":    lookup("annotation", AnnotationsKey),
+		"inGroup":       inGroup,
+		"online":        online,
+	}
+}
+
+// Register adds Functions to e.
+func Register(e *casbin.Enforcer) {
+	for name, fn := range Functions() {
+		e.AddFunction(name, fn)
+	}
+}
+
+func hasLabel(args ...any) (any, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, fmt.Errorf("hasLabel: expected 2 or 3 arguments, got %d", len(args))
+	}
+	labels, key, err := entry("hasLabel", LabelsKey, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	value, ok := labels[key]
+	if len(args) == 2 || !ok {
+		return ok, nil
+	}
+	expected, isString := args[2].(string)
+	if !isString {
+		return nil, fmt.Errorf("hasLabel: value must be a string")
+	}
+	return value == expected, nil
+}
+
+func lookup(fnName, section string) govaluate.ExpressionFunction {
+	return func(args ...any) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s: expected 2 arguments, got %d", fnName, len(args))
+		}
+		values, key, err := entry(fnName, section, args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+		return values[key], nil
+	}
+}
+
+func exists(fnName, section string) govaluate.ExpressionFunction {
+	return func(args ...any) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s: expected 2 arguments, got %d", fnName, len(args))
+		}
+		values, key, err := entry(fnName, section, args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+		_, ok := values[key]
+		return ok, nil
+	}
+}
+
+func inGroup(args ...any) (any, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("inGroup: expected 2 arguments, got %d", len(args))
+	}
+	annotations, group, err := entry("inGroup", AnnotationsKey, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := annotations[GroupsAnnotation]
+	if !ok || raw == "" {
+		return false, nil
+	}
+	var groups []string
+	if err := json.Unmarshal([]byte(raw), &groups); err != nil {
+		return nil, fmt.Errorf("inGroup: annotation %q is not a JSON string list: %w", GroupsAnnotation, err)
+	}
+	group = strings.TrimPrefix(group, GroupPrefix)
+	return slices.ContainsFunc(groups, func(g string) bool { return strings.TrimPrefix(g, GroupPrefix) == group }), nil
+}
+
+func online(args ...any) (any, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("online: expected 1 argument, got %d", len(args))
+	}
+	annotations, err := section("online", AnnotationsKey, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return annotations[OnlineAnnotation] == "true" || annotations[LocationAnnotation] != "", nil
+}
+
+// entry returns the labels or annotations of obj and the key argument.
+func entry(fnName, name string, obj, key any) (map[string]string, string, error) {
+	values, err := section(fnName, name, obj)
+	if err != nil {
+		return nil, "", err
+	}
+	k, ok := key.(string)
+	if !ok {
+		return nil, "", fmt.Errorf("%s: key must be a string", fnName)
+	}
+	return values, k, nil
+}
+
+// section returns the labels or annotations of r.player or r.server.
+func section(fnName, name string, obj any) (map[string]string, error) {
+	attrs, ok := obj.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: first argument must be r.player or r.server", fnName)
+	}
+	switch values := attrs[name].(type) {
+	case map[string]string:
+		return values, nil
+	case nil:
+		return map[string]string{}, nil
+	default:
+		// Attributes that were not normalized, e.g. written by hand
+		converted := make(map[string]string)
+		copyStrings(converted, values)
+		return converted, nil
+	}
+}
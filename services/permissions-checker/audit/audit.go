@@ -0,0 +1,126 @@
+// Package audit records permission decisions to a JetStream stream and reads them back.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// DefaultStream is the JetStream stream holding decision records.
+	DefaultStream = "PERMISSION_AUDIT"
+	// SubjectPrefix prefixes the subjects decisions are published on:
+	// permissions.audit.<source>.<allow|deny>
+	SubjectPrefix = "permissions.audit."
+)
+
+// Decision is a single authorization decision.
+type Decision struct {
+	Time          time.Time `json:"time"`
+	Source        string    `json:"source"`                   // Component that made the decision, e.g. "permissions-checker" or "proxy"
+	Caller        string    `json:"caller,omitempty"`         // Client that asked for the decision
+	RequestID     string    `json:"request_id,omitempty"`     // Request ID given by the caller, if any
+	TraceID       string    `json:"trace_id,omitempty"`       // Trace the decision was made in, if traced
+	Subject       string    `json:"subject"`                  // Player UUID
+	SubjectName   string    `json:"subject_name,omitempty"`   // Player name, if known
+	Object        string    `json:"object"`                   // Resource, e.g. "server:lobby-1"
+	Server        string    `json:"server,omitempty"`         // Server the request was evaluated against
+	Action        string    `json:"action"`                   // Action, e.g. "connect"
+	Allowed       bool      `json:"allowed"`                  // Result of the decision
+	MatchedPolicy []string  `json:"matched_policy,omitempty"` // Policy rule that decided, as reported by Casbin
+	LatencyMicros int64     `json:"latency_us"`               // Time spent evaluating the request
+	Error         string    `json:"error,omitempty"`          // Evaluation error, if any
+}
+
+// Result returns "allow" or "deny", as used in the decision subject.
+func (d Decision) Result() string {
+	if d.Allowed {
+		return "allow"
+	}
+	return "deny"
+}
+
+// Latency returns the evaluation time of the decision.
+func (d Decision) Latency() time.Duration {
+	return time.Duration(d.LatencyMicros) * time.Microsecond
+}
+
+// Config configures a Recorder.
+type Config struct {
+	Enabled         bool
+	Stream          string        // JetStream stream name
+	MaxAge          time.Duration // Retention of decision records
+	AllowSampleRate float64       // Fraction of allowed decisions recorded (0..1); denies are always recorded
+}
+
+// Recorder publishes decisions to the audit stream.
+// A nil *Recorder is valid and records nothing.
+type Recorder struct {
+	nc              *nats.Conn
+	source          string
+	allowSampleRate float64
+}
+
+// NewRecorder ensures the audit stream exists and returns a Recorder publishing decisions
+// made by source. It returns nil when auditing is disabled.
+func NewRecorder(nc *nats.Conn, js nats.JetStreamContext, source string, cfg Config) (*Recorder, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if err := EnsureStream(js, cfg.Stream, cfg.MaxAge); err != nil {
+		return nil, err
+	}
+	return &Recorder{nc: nc, source: source, allowSampleRate: cfg.AllowSampleRate}, nil
+}
+
+// EnsureStream creates the audit stream if it does not exist yet.
+func EnsureStream(js nats.JetStreamContext, stream string, maxAge time.Duration) error {
+	_, err := js.StreamInfo(stream)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, nats.ErrStreamNotFound) {
+		return fmt.Errorf("failed to get audit stream %s: %w", stream, err)
+	}
+	_, err = js.AddStream(&nats.StreamConfig{
+		Name:     stream,
+		Subjects: []string{SubjectPrefix + ">"},
+		Storage:  nats.FileStorage,
+		MaxAge:   maxAge,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create audit stream %s: %w", stream, err)
+	}
+	slog.Info("Created JetStream audit stream", "stream", stream)
+	return nil
+}
+
+// Record publishes d unless it is an allow that is not sampled.
+// Publishing is fire-and-forget so that it never delays a decision.
+func (r *Recorder) Record(d Decision) {
+	if r == nil {
+		return
+	}
+	if d.Allowed && rand.Float64() >= r.allowSampleRate {
+		return
+	}
+	if d.Time.IsZero() {
+		d.Time = time.Now()
+	}
+	d.Source = r.source
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		slog.Error("Failed to encode audit decision", "error", err)
+		return
+	}
+	if err := r.nc.Publish(SubjectPrefix+r.source+"."+d.Result(), data); err != nil {
+		slog.Error("Failed to publish audit decision", "error", err)
+	}
+}
@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Query filters decisions read from the audit stream. Empty fields match everything.
+type Query struct {
+	Subject string    // Substring of the player UUID or name
+	Object  string    // Substring of the object
+	Action  string    // Exact action
+	Result  string    // "allow", "deny" or empty
+	Source  string    // Exact source
+	Since   time.Time // Only decisions at or after this time
+	Limit   int       // Maximum number of decisions returned (default 100)
+	Scan    int       // Maximum number of records read from the stream (default 10000)
+}
+
+// Matches reports whether d satisfies the in-memory filters of q.
+func (q Query) Matches(d Decision) bool {
+	if q.Subject != "" && !strings.Contains(d.Subject, q.Subject) && !strings.Contains(strings.ToLower(d.SubjectName), strings.ToLower(q.Subject)) {
+		return false
+	}
+	if q.Object != "" && !strings.Contains(d.Object, q.Object) {
+		return false
+	}
+	if q.Action != "" && d.Action != q.Action {
+		return false
+	}
+	if !q.Since.IsZero() && d.Time.Before(q.Since) {
+		return false
+	}
+	return true
+}
+
+// filterSubject narrows the stream subjects read for q.
+func (q Query) filterSubject() string {
+	source, result := "*", "*"
+	if q.Source != "" {
+		source = q.Source
+	}
+	if q.Result != "" {
+		result = q.Result
+	}
+	return SubjectPrefix + source + "." + result
+}
+
+// QueryDecisions returns the most recent decisions matching q, newest first.
+// At most q.Scan records are read from the end of the stream.
+func QueryDecisions(ctx context.Context, js nats.JetStreamContext, stream string, q Query) ([]Decision, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+	if q.Scan <= 0 {
+		q.Scan = 10000
+	}
+	if q.Result != "" && q.Result != "allow" && q.Result != "deny" {
+		return nil, fmt.Errorf("invalid result %q: expected \"allow\" or \"deny\"", q.Result)
+	}
+
+	info, err := js.StreamInfo(stream, nats.Context(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit stream %s: %w", stream, err)
+	}
+	last := info.State.LastSeq
+	if info.State.Msgs == 0 {
+		return nil, nil
+	}
+
+	// Read from the latest of Since and the first of the last q.Scan records, so that the most
+	// recent decisions are the ones returned when more of them match (Matches filters out the
+	// records older than Since when starting from the sequence)
+	start := info.State.FirstSeq
+	if last >= uint64(q.Scan) && last-uint64(q.Scan)+1 > start {
+		start = last - uint64(q.Scan) + 1
+	}
+	startOpt := nats.StartSequence(start)
+	if !q.Since.IsZero() && q.Since.After(info.State.FirstTime) {
+		first, err := js.GetMsg(stream, start, nats.Context(ctx))
+		if err == nil && q.Since.After(first.Time) {
+			startOpt = nats.StartTime(q.Since)
+		}
+	}
+
+	sub, err := js.SubscribeSync(q.filterSubject(), nats.BindStream(stream), nats.OrderedConsumer(), startOpt, nats.Context(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit stream %s: %w", stream, err)
+	}
+	defer sub.Unsubscribe()
+
+	if ci, err := sub.ConsumerInfo(); err == nil && ci.NumPending == 0 && ci.Delivered.Consumer == 0 {
+		return nil, nil // Nothing matches the subject filter
+	}
+
+	var matched []Decision
+	for read := 0; read < q.Scan; read++ {
+		msg, err := sub.NextMsgWithContext(ctx)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && len(matched) > 0 {
+				break // Return what was found so far
+			}
+			return nil, fmt.Errorf("failed to read audit stream %s: %w", stream, err)
+		}
+
+		var d Decision
+		if err := json.Unmarshal(msg.Data, &d); err == nil && q.Matches(d) {
+			matched = append(matched, d)
+		}
+
+		meta, err := msg.Metadata()
+		if err != nil || meta.NumPending == 0 || meta.Sequence.Stream >= last {
+			break
+		}
+	}
+
+	// Newest first, keeping only the latest q.Limit decisions
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	if len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched, nil
+}
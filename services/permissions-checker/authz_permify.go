@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bafbi/minecraft-network/pkg/observability"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/audit"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/config"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/metrics"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/permify"
+)
+
+// permifyCompareTimeout bounds the Permify checks made in compare mode, off the request path.
+const permifyCompareTimeout = 2 * time.Second
+
+// newPermifyAuthorizer connects to the Permify server configured in cfg, or to the in-memory
+// stand-in, writes the schema and the relationships of cfg.PermifyTuplesFile.
+func newPermifyAuthorizer(ctx context.Context, cfg *config.Config) (*permify.Authorizer, error) {
+	var client permify.Client
+	switch cfg.PermifyURL {
+	case "":
+		return nil, fmt.Errorf("PERMIFY_URL is required by AUTHZ_BACKEND=%s", cfg.AuthzBackend)
+	case config.PermifyMemory:
+		client = permify.NewMemory()
+	default:
+		httpClient, err := permify.NewHTTPClient(cfg.PermifyURL, cfg.PermifyTenant, cfg.PermifyToken, 2*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		client = httpClient
+	}
+
+	var tuples []permify.Tuple
+	if cfg.PermifyTuplesFile != "" {
+		var err error
+		if tuples, err = permify.LoadTuples(cfg.PermifyTuplesFile); err != nil {
+			return nil, err
+		}
+	}
+	return permify.NewAuthorizer(ctx, client, tuples)
+}
+
+// evaluatePermify decides req from Permify relationships (AUTHZ_BACKEND=permify) and records
+// the decision to the audit stream like evaluate.
+func (s *authService) evaluatePermify(ctx context.Context, req *auth.AuthRequest) *auth.AuthResponse {
+	start := time.Now()
+	resp, err := s.permify.CheckPermission(ctx, req)
+	decisionRecord := audit.Decision{
+		Time:          start,
+		Caller:        callerFromContext(ctx),
+		RequestID:     req.GetRequestId(),
+		TraceID:       observability.TraceID(ctx),
+		Subject:       req.GetPlayerUuid(),
+		SubjectName:   req.GetPlayerName(),
+		Object:        req.GetResource(),
+		Server:        req.GetServerName(),
+		Action:        req.GetAction(),
+		Allowed:       err == nil && resp.GetAllowed(),
+		LatencyMicros: time.Since(start).Microseconds(),
+	}
+	if err != nil {
+		decisionRecord.Error = err.Error()
+	}
+	metrics.ObserveDecision(config.AuthzPermify, req.GetResource(), start, resp.GetAllowed(), err)
+	s.auditRecorder.Record(decisionRecord)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Permify check error", "player_uuid", req.GetPlayerUuid(), "action", req.GetAction(), "resource", req.GetResource(), "error", err)
+		return &auth.AuthResponse{Allowed: false, Message: fmt.Sprintf("Internal error: %v", err), RequestId: req.GetRequestId()}
+	}
+	return resp
+}
+
+// comparePermify asks Permify for the decision Casbin made for req (AUTHZ_BACKEND=compare)
+// and logs when they disagree. It does not delay the response: when s.compareSlots comparisons are
+// already in flight, e.g. during a large batch, req is not compared.
+func (s *authService) comparePermify(req *auth.AuthRequest, casbinAllowed bool) {
+	select {
+	case s.compareSlots <- struct{}{}:
+	default:
+		metrics.ObservePermifyComparison("skipped")
+		return
+	}
+	go func() {
+		defer func() { <-s.compareSlots }()
+		ctx, cancel := context.WithTimeout(context.Background(), permifyCompareTimeout)
+		defer cancel()
+		resp, err := s.permify.CheckPermission(ctx, req)
+		if err != nil {
+			metrics.ObservePermifyComparison("error")
+			slog.Warn("Permify comparison failed", "player_uuid", req.GetPlayerUuid(), "action", req.GetAction(), "resource", req.GetResource(), "error", err)
+			return
+		}
+		if resp.GetAllowed() != casbinAllowed {
+			metrics.ObservePermifyComparison("disagree")
+			slog.Warn("Permify disagrees with Casbin", "player_uuid", req.GetPlayerUuid(), "action", req.GetAction(),
+				"resource", req.GetResource(), "server", req.GetServerName(), "casbin_allowed", casbinAllowed,
+				"permify_allowed", resp.GetAllowed(), "permify_message", resp.GetMessage())
+			return
+		}
+		metrics.ObservePermifyComparison("agree")
+	}()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/permify"
+)
+
+// blockingPermify holds every check until release is closed.
+type blockingPermify struct {
+	*permify.Memory
+	release  chan struct{}
+	inFlight atomic.Int32
+	calls    atomic.Int32
+}
+
+func (c *blockingPermify) Check(ctx context.Context, entity permify.Reference, permission string, subject permify.Reference) (bool, error) {
+	c.calls.Add(1)
+	c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	return c.Memory.Check(ctx, entity, permission, subject)
+}
+
+func TestComparePermifyIsBounded(t *testing.T) {
+	client := &blockingPermify{Memory: permify.NewMemory(), release: make(chan struct{})}
+	authorizer, err := permify.NewAuthorizer(context.Background(), client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const limit = 4
+	s := &authService{permify: authorizer, compareSlots: make(chan struct{}, limit)}
+
+	req := &auth.AuthRequest{PlayerUuid: "00000000-0000-0000-0000-000000000001", Action: "connect", Resource: "server:lobby"}
+	for range 1000 {
+		s.comparePermify(req, false)
+	}
+	waitFor(t, func() bool { return client.inFlight.Load() == limit })
+	if calls := client.calls.Load(); calls != limit {
+		t.Fatalf("Permify called %d times for a batch of 1000 checks, want %d", calls, limit)
+	}
+
+	// Slots are freed once the comparisons are done
+	close(client.release)
+	waitFor(t, func() bool { return len(s.compareSlots) == 0 })
+	s.comparePermify(req, false)
+	waitFor(t, func() bool { return client.calls.Load() == limit+1 })
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 1s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+)
+
+// maxBatchSize bounds the number of checks accepted in a single CheckPermissions call.
+const maxBatchSize = 1000
+
+// CheckPermissions implements the gRPC method evaluating several checks in one round trip.
+// Responses are returned in request order.
+func (s *authService) CheckPermissions(ctx context.Context, req *auth.BatchAuthRequest) (*auth.BatchAuthResponse, error) {
+	requests := req.GetRequests()
+	if len(requests) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch of %d checks exceeds the limit of %d", len(requests), maxBatchSize)
+	}
+
+	if err := s.requireMetadata(ctx); err != nil {
+		return nil, err
+	}
+
+	responses := make([]*auth.AuthResponse, 0, len(requests))
+	allowedCount := 0
+	for _, r := range requests {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		resp := s.evaluate(ctx, r)
+		if resp.GetAllowed() {
+			allowedCount++
+		}
+		responses = append(responses, resp)
+	}
+
+	slog.DebugContext(ctx, "CheckPermissions: evaluated checks", "checks", len(responses),
+		"allowed", allowedCount, "denied", len(responses)-allowedCount)
+	return &auth.BatchAuthResponse{Responses: responses}, nil
+}
+
+// CheckPermissionStream implements the bidirectional streaming gRPC method.
+// Each request is answered as soon as it is received; request_id allows the caller to correlate responses.
+func (s *authService) CheckPermissionStream(stream auth.AuthService_CheckPermissionStreamServer) error {
+	var total, allowedCount int
+	defer func() {
+		slog.InfoContext(stream.Context(), "CheckPermissionStream closed", "checks", total,
+			"allowed", allowedCount, "denied", total-allowedCount)
+	}()
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.requireMetadata(stream.Context()); err != nil {
+			return err
+		}
+		resp := s.evaluate(stream.Context(), req)
+		total++
+		if resp.GetAllowed() {
+			allowedCount++
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
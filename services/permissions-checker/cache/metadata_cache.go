@@ -3,9 +3,12 @@ package cache
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nats-io/nats.go"
@@ -14,107 +17,335 @@ import (
 
 // MetadataCache holds cached player and server metadata.
 type MetadataCache struct {
-	playerCache  map[string]*structpb.Struct // Key: Player UUID
-	serverCache  map[string]*structpb.Struct // Key: Server Name
-	playerMu     sync.RWMutex
-	serverMu     sync.RWMutex
+	players      *section // Key: Player UUID
+	servers      *section // Key: Server Name
 	kv           nats.KeyValue
 	playerPrefix string
 	serverPrefix string
+	maxStaleness time.Duration // 0 disables the staleness bound
+
+	ready     chan struct{} // Closed once the initial values of both sections are cached
+	readyOnce sync.Once
+	errMu     sync.Mutex
+	errs      map[string]error // Key prefix -> last watcher failure, cleared when it restarts
+}
+
+// section is the cache of one key prefix, fed by its own watcher.
+type section struct {
+	keyPrefix string
+	mu        sync.RWMutex
+	values    map[string]*structpb.Struct
+	revisions map[string]uint64 // Last applied revision per key, kept after deletes
+	loaded    atomic.Bool       // Initial values cached at least once
+	synced    atomic.Bool       // The running watcher has delivered its initial values
+	syncedAt  atomic.Int64      // Unix nanoseconds the section was last known to be up to date
+	puts      atomic.Uint64     // PUT updates applied
+	deletes   atomic.Uint64     // DELETE and PURGE updates applied
+}
+
+// Stats describes the contents of the cache and the updates applied to it.
+type Stats struct {
+	Players SectionStats
+	Servers SectionStats
+}
+
+// SectionStats describes the players or servers section of the cache.
+type SectionStats struct {
+	Entries int    // Cached metadata objects
+	Puts    uint64 // PUT updates applied since startup
+	Deletes uint64 // DELETE and PURGE updates applied since startup
+}
+
+func newSection(keyPrefix string) *section {
+	return &section{
+		keyPrefix: keyPrefix,
+		values:    make(map[string]*structpb.Struct),
+		revisions: make(map[string]uint64),
+	}
 }
 
-// NewMetadataCache creates and initializes a new MetadataCache.
-func NewMetadataCache(kv nats.KeyValue, playerPrefix, serverPrefix string) *MetadataCache {
+// Delays between restarts of a failed watcher.
+const (
+	watchRetryMin = time.Second
+	watchRetryMax = 30 * time.Second
+)
+
+// syncProbeInterval is how often running watchers are confirmed to be connected.
+const syncProbeInterval = 5 * time.Second
+
+// ErrNotReady is returned by Fresh until the initial metadata has been cached.
+var ErrNotReady = errors.New("metadata cache: initial values not loaded")
+
+// NewMetadataCache creates and initializes a new MetadataCache. With maxStaleness, Fresh reports
+// an error when the cache may have missed updates for longer than that.
+func NewMetadataCache(kv nats.KeyValue, playerPrefix, serverPrefix string, maxStaleness time.Duration) *MetadataCache {
 	return &MetadataCache{
-		playerCache:  make(map[string]*structpb.Struct),
-		serverCache:  make(map[string]*structpb.Struct),
+		players:      newSection(playerPrefix + ">"),
+		servers:      newSection(serverPrefix + ">"),
 		kv:           kv,
 		playerPrefix: playerPrefix,
 		serverPrefix: serverPrefix,
+		maxStaleness: maxStaleness,
+		ready:        make(chan struct{}),
+		errs:         make(map[string]error),
 	}
 }
 
-// StartWatching initializes the NATS KV watchers for player and server metadata.
+// StartWatching initializes the NATS KV watchers for player and server metadata. Watchers
+// that fail are restarted until ctx is cancelled; Ready is closed once the initial values
+// of both are cached.
 func (mc *MetadataCache) StartWatching(ctx context.Context) {
-	go mc.watchForUpdates(ctx, mc.playerPrefix+">", mc.playerCache, &mc.playerMu, mc.extractPlayerKey)
-	go mc.watchForUpdates(ctx, mc.serverPrefix+">", mc.serverCache, &mc.serverMu, mc.extractServerKey)
-	log.Println("Started NATS KV watchers for metadata.")
+	go mc.keepWatching(ctx, mc.players)
+	go mc.keepWatching(ctx, mc.servers)
+	go mc.probeSync(ctx)
+	slog.Info("Started NATS KV watchers for metadata")
+}
+
+// Ready returns a channel closed once the values present in NATS KV when the watchers started
+// have been cached.
+func (mc *MetadataCache) Ready() <-chan struct{} {
+	return mc.ready
+}
+
+// WaitReady blocks until the cache is ready or ctx is done.
+func (mc *MetadataCache) WaitReady(ctx context.Context) error {
+	select {
+	case <-mc.ready:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrNotReady, ctx.Err())
+	}
+}
+
+// SyncedAt returns the last time both sections were known to be up to date with NATS KV,
+// or the zero time before the cache is ready.
+func (mc *MetadataCache) SyncedAt() time.Time {
+	oldest := min(mc.players.syncedAt.Load(), mc.servers.syncedAt.Load())
+	if oldest == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, oldest)
+}
+
+// Fresh returns nil if the cache is ready and, with a staleness bound, was up to date within it.
+func (mc *MetadataCache) Fresh() error {
+	select {
+	case <-mc.ready:
+	default:
+		return ErrNotReady
+	}
+	if mc.maxStaleness <= 0 {
+		return nil
+	}
+	if age := time.Since(mc.SyncedAt()); age > mc.maxStaleness {
+		return fmt.Errorf("metadata cache: last synced %s ago, more than the %s bound", age.Round(time.Millisecond), mc.maxStaleness)
+	}
+	return nil
+}
+
+// Err returns the errors of failed watchers until they have been restarted, nil otherwise.
+func (mc *MetadataCache) Err() error {
+	mc.errMu.Lock()
+	defer mc.errMu.Unlock()
+	var errs []error
+	for _, err := range mc.errs {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (mc *MetadataCache) setErr(keyPrefix string, err error) {
+	mc.errMu.Lock()
+	defer mc.errMu.Unlock()
+	if err == nil {
+		delete(mc.errs, keyPrefix)
+	} else {
+		mc.errs[keyPrefix] = err
+	}
+}
+
+// keepWatching runs watchForUpdates, restarting it with a growing delay when it fails.
+func (mc *MetadataCache) keepWatching(ctx context.Context, sec *section) {
+	delay := watchRetryMin
+	for {
+		err := mc.watchForUpdates(ctx, sec)
+		sec.synced.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		mc.setErr(sec.keyPrefix, fmt.Errorf("metadata watcher for %s: %w", sec.keyPrefix, err))
+		slog.Warn("NATS KV watcher failed, restarting", "prefix", sec.keyPrefix, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, watchRetryMax)
+	}
+}
+
+// probeSync advances the sync time of sections whose watcher is running while JetStream answers.
+// Watchers deliver nothing while no metadata changes, so a reachable server is what tells a
+// quiet bucket from a disconnected one.
+func (mc *MetadataCache) probeSync(ctx context.Context) {
+	interval := syncProbeInterval
+	if mc.maxStaleness > 0 {
+		interval = max(min(interval, mc.maxStaleness/3), 100*time.Millisecond)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := mc.kv.Status(); err != nil {
+			continue
+		}
+		now := time.Now().UnixNano()
+		for _, sec := range []*section{mc.players, mc.servers} {
+			if sec.synced.Load() {
+				sec.syncedAt.Store(now)
+			}
+		}
+	}
+}
+
+// Stats returns the current size and update counts of the cache.
+func (mc *MetadataCache) Stats() Stats {
+	return Stats{Players: mc.players.stats(), Servers: mc.servers.stats()}
+}
+
+func (sec *section) stats() SectionStats {
+	sec.mu.RLock()
+	entries := len(sec.values)
+	sec.mu.RUnlock()
+	return SectionStats{Entries: entries, Puts: sec.puts.Load(), Deletes: sec.deletes.Load()}
 }
 
 // GetPlayerMetadata retrieves player metadata from the cache.
 func (mc *MetadataCache) GetPlayerMetadata(uuid string) *structpb.Struct {
-	mc.playerMu.RLock()
-	defer mc.playerMu.RUnlock()
-	return mc.playerCache[uuid]
+	return mc.players.get(uuid)
 }
 
 // GetServerMetadata retrieves server metadata from the cache.
 func (mc *MetadataCache) GetServerMetadata(name string) *structpb.Struct {
-	mc.serverMu.RLock()
-	defer mc.serverMu.RUnlock()
-	return mc.serverCache[name]
+	return mc.servers.get(name)
+}
+
+func (sec *section) get(key string) *structpb.Struct {
+	sec.mu.RLock()
+	defer sec.mu.RUnlock()
+	return sec.values[key]
 }
 
-// watchForUpdates is a generic function to watch for changes in NATS KV.
-func (mc *MetadataCache) watchForUpdates(ctx context.Context, keyPrefix string, cache map[string]*structpb.Struct, mu *sync.RWMutex, extractKeyFunc func(string, string) string) {
-	watcher, err := mc.kv.Watch(keyPrefix)
+// watchForUpdates is a generic function to watch for changes in NATS KV. It returns when ctx
+// is cancelled or the watch fails.
+func (mc *MetadataCache) watchForUpdates(ctx context.Context, sec *section) error {
+	watcher, err := mc.kv.Watch(sec.keyPrefix)
 	if err != nil {
-		log.Fatalf("Failed to create NATS KV watcher for %s: %v", keyPrefix, err)
+		return fmt.Errorf("failed to create NATS KV watcher: %w", err)
 	}
 	defer watcher.Stop()
+	mc.setErr(sec.keyPrefix, nil)
 
-	log.Printf("Watching NATS KV for updates on prefix: %s", keyPrefix)
+	slog.Info("Watching NATS KV for updates", "prefix", sec.keyPrefix)
 
+	// Keys delivered before the initial-values marker; cached keys missing from them were
+	// deleted while no watcher was running
+	initial := make(map[string]bool)
 	for {
 		select {
 		case <-ctx.Done():
-			log.Printf("Stopping NATS KV watcher for %s due to context cancellation.", keyPrefix)
-			return
-		case entry := <-watcher.Updates():
+			slog.Info("Stopping NATS KV watcher due to context cancellation", "prefix", sec.keyPrefix)
+			return ctx.Err()
+		case entry, ok := <-watcher.Updates():
+			if !ok {
+				return errors.New("NATS KV watcher closed")
+			}
 			if entry == nil {
-				log.Printf("NATS KV watcher for %s received nil entry, likely closing.", keyPrefix)
-				return
+				mc.initialValuesLoaded(sec, initial)
+				initial = nil
+				continue
 			}
-
-			key := entry.Key()
-			extractedKey := extractKeyFunc(key, keyPrefix)
-			mu.Lock()
-
-			switch entry.Operation() {
-			case nats.KeyValuePut:
-				var data map[string]any
-				if err := json.Unmarshal(entry.Value(), &data); err != nil {
-					log.Printf("Error unmarshaling JSON for key %s: %v", key, err)
-					mu.Unlock()
-					continue
-				}
-				pbStruct, err := structpb.NewStruct(data)
-				if err != nil {
-					log.Printf("Error converting map to protobuf Struct for key %s: %v", key, err)
-					mu.Unlock()
-					continue
-				}
-				cache[extractedKey] = pbStruct
-				log.Printf("Cached PUT update for %s: %s (rev %d)", strings.TrimSuffix(keyPrefix, ">"), extractedKey, entry.Revision())
-			case nats.KeyValueDelete:
-				delete(cache, extractedKey)
-				log.Printf("Cached DELETE update for %s: %s (rev %d)", strings.TrimSuffix(keyPrefix, ">"), extractedKey, entry.Revision())
-			default:
-				log.Printf("Unknown NATS KV operation for %s: %v", key, entry.Operation())
+			key := mc.extractKey(entry.Key(), sec.keyPrefix)
+			if initial != nil {
+				initial[key] = true
+			}
+			sec.apply(key, entry)
+			if initial == nil {
+				sec.syncedAt.Store(time.Now().UnixNano())
 			}
-			mu.Unlock()
-		case <-time.After(10 * time.Second): // Periodically log heartbeats or check for no updates
-			// log.Printf("NATS KV watcher for %s is active, no updates in 10s.", keyPrefix)
 		}
 	}
 }
 
-// Helper to extract the actual UUID from a player metadata key (e.g., "player.metadata.UUID")
-func (mc *MetadataCache) extractPlayerKey(fullKey, prefix string) string {
-	return strings.TrimPrefix(fullKey, strings.TrimSuffix(prefix, ">"))
+// initialValuesLoaded handles the marker sent by a watcher once it has delivered the values
+// present when it started.
+func (mc *MetadataCache) initialValuesLoaded(sec *section, initial map[string]bool) {
+	sec.mu.Lock()
+	pruned := 0
+	for key := range sec.revisions {
+		if !initial[key] {
+			delete(sec.values, key)
+			delete(sec.revisions, key)
+			pruned++
+		}
+	}
+	sec.mu.Unlock()
+
+	sec.syncedAt.Store(time.Now().UnixNano())
+	sec.synced.Store(true)
+	if !sec.loaded.Swap(true) {
+		slog.Info("Loaded initial metadata", "prefix", sec.keyPrefix, "keys", len(initial))
+	} else if pruned > 0 {
+		slog.Info("Resynced metadata, dropped keys deleted meanwhile", "prefix", sec.keyPrefix, "dropped", pruned)
+	}
+	if mc.players.loaded.Load() && mc.servers.loaded.Load() {
+		mc.readyOnce.Do(func() {
+			close(mc.ready)
+			slog.Info("Metadata cache ready")
+		})
+	}
+}
+
+// apply caches entry unless a later revision of key has been applied already, e.g. when a
+// restarted watcher replays values.
+func (sec *section) apply(key string, entry nats.KeyValueEntry) {
+	sec.mu.Lock()
+	defer sec.mu.Unlock()
+	if entry.Revision() <= sec.revisions[key] {
+		return
+	}
+
+	switch entry.Operation() {
+	case nats.KeyValuePut:
+		var data map[string]any
+		if err := json.Unmarshal(entry.Value(), &data); err != nil {
+			slog.Error("Error unmarshaling metadata JSON", "key", entry.Key(), "error", err)
+			return
+		}
+		pbStruct, err := structpb.NewStruct(data)
+		if err != nil {
+			slog.Error("Error converting metadata to protobuf Struct", "key", entry.Key(), "error", err)
+			return
+		}
+		sec.values[key] = pbStruct
+		sec.puts.Add(1)
+		slog.Debug("Cached PUT update", "prefix", strings.TrimSuffix(sec.keyPrefix, ">"), "key", key, "revision", entry.Revision())
+	case nats.KeyValueDelete, nats.KeyValuePurge:
+		delete(sec.values, key)
+		sec.deletes.Add(1)
+		slog.Debug("Cached DELETE update", "prefix", strings.TrimSuffix(sec.keyPrefix, ">"), "key", key, "revision", entry.Revision())
+	default:
+		slog.Warn("Unknown NATS KV operation", "key", entry.Key(), "operation", entry.Operation().String())
+		return
+	}
+	sec.revisions[key] = entry.Revision()
 }
 
-// Helper to extract the actual server name from a server metadata key (e.g., "server.metadata.survival")
-func (mc *MetadataCache) extractServerKey(fullKey, prefix string) string {
+// extractKey returns the UUID or server name of a metadata key (e.g. "player.metadata.UUID").
+func (mc *MetadataCache) extractKey(fullKey, prefix string) string {
 	return strings.TrimPrefix(fullKey, strings.TrimSuffix(prefix, ">"))
 }
@@ -1,10 +1,28 @@
 package config
 
 import (
-	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/audit"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/grants"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/history"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/policyfile"
+)
+
+// Authorization backends deciding CheckPermission requests (AUTHZ_BACKEND).
+const (
+	AuthzCasbin  = "casbin"  // Casbin policies (default)
+	AuthzPermify = "permify" // Permify relationships (see package permify)
+	AuthzCompare = "compare" // Casbin decides; Permify is asked as well and disagreements are logged
 )
 
+// PermifyMemory as PERMIFY_URL uses the in-memory stand-in instead of a Permify server.
+const PermifyMemory = "memory"
+
 type Config struct {
 	GRPCPort             string
 	ValkeyAddr           string
@@ -13,8 +31,37 @@ type Config struct {
 	NATSAddr             string
 	NATSUser             string
 	NATSPassword         string
-	PlayerMetadataPrefix string // e.g., "player.metadata."
-	ServerMetadataPrefix string // e.g., "server.metadata."
+	PlayerMetadataPrefix string        // e.g., "player.metadata."
+	ServerMetadataPrefix string        // e.g., "server.metadata."
+	MetadataMaxStaleness time.Duration // Checks fail while metadata may be older than this, 0 disables
+	MetadataWaitTimeout  time.Duration // How long checks wait for the initial metadata after startup
+	PolicyUpdateSubject  string        // NATS subject used to sync policy changes between replicas
+	PolicyHistoryBucket  string        // NATS KV bucket holding policy versions
+	PolicySeedFile       string        // Policy file imported on startup (e.g. mounted from a ConfigMap), optional
+	PolicySeedMode       policyfile.Mode
+	PolicyTestFile       string            // Policy test suite that policy changes must pass, optional
+	GrantsBucket         string            // NATS KV bucket holding the expiry of time-bound rules
+	GrantReaperInterval  time.Duration     // How often expired rules are removed
+	GrantStores          map[string]string // Policy update subject => Valkey key of the other stores whose expired rules are removed
+	Audit                audit.Config
+	AuthzBackend         string // One of the Authz* constants
+	PermifyURL           string // Permify REST API, e.g. http://permify:3476, or PermifyMemory
+	PermifyTenant        string
+	PermifyToken         string // Preshared key, when Permify authentication is enabled
+	PermifyTuplesFile    string // Relationships written to Permify on startup, optional
+	PermifyCompareLimit  int    // Permify comparisons in flight at once in compare mode; checks beyond it are not compared
+	GRPCTLSCert          string // Server certificate; TLS is enabled when set with GRPCTLSKey
+	GRPCTLSKey           string
+	GRPCTLSClientCA      string // CA verifying client certificates (mutual TLS), optional
+	GRPCClientCertRoles  string // "commonName=role,..." for mutual TLS clients
+	GRPCAPIKeys          string // "name:role:key,..." accepted in the x-api-key metadata
+	GRPCJWTSecret        string // HS256 secret of accepted JWTs, optional
+	GRPCJWTIssuer        string
+	GRPCJWTAudience      string
+	GRPCAnonymousRole    string        // Role of calls without credentials or "none"; "check" by default without authentication, "none" with it
+	GRPCReflection       bool          // Register gRPC server reflection (for grpcurl and similar tools)
+	HealthCheckInterval  time.Duration // How often readiness dependencies are checked
+	MetricsPort          string        // Port of the Prometheus /metrics endpoint, "off" disables it
 }
 
 func LoadConfig() *Config {
@@ -49,8 +96,131 @@ func LoadConfig() *Config {
 		serverMetaPrefix = "server."
 	}
 
-	log.Printf("Loading config: GRPC_PORT=%s, VALKEY_ADDR=%s, NATS_ADDR=%s, PlayerMetaPrefix=%s, ServerMetaPrefix=%s",
-		grpcPort, valkeyAddr, natsAddr, playerMetaPrefix, serverMetaPrefix)
+	policyUpdateSubject := os.Getenv("POLICY_UPDATE_SUBJECT")
+	if policyUpdateSubject == "" {
+		policyUpdateSubject = "permissions.checker.policy.updated"
+	}
+
+	policyHistoryBucket := os.Getenv("POLICY_HISTORY_BUCKET")
+	if policyHistoryBucket == "" {
+		policyHistoryBucket = history.DefaultBucket
+	}
+
+	policySeedFile := os.Getenv("POLICY_SEED_FILE")
+	policySeedMode := policyfile.ModeAdd
+	switch v := os.Getenv("POLICY_SEED_MODE"); v {
+	case "", "add":
+	case "replace":
+		policySeedMode = policyfile.ModeReplace
+	case "prune":
+		policySeedMode = policyfile.ModePrune
+	default:
+		slog.Warn("Invalid POLICY_SEED_MODE (expected add, replace or prune), using add", "value", v)
+	}
+
+	grantsBucket := os.Getenv("GRANTS_BUCKET")
+	if grantsBucket == "" {
+		grantsBucket = grants.DefaultBucket
+	}
+	grantReaperInterval := 30 * time.Second
+	if v := os.Getenv("GRANT_REAPER_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			grantReaperInterval = d
+		} else {
+			slog.Warn("Invalid GRANT_REAPER_INTERVAL, using the default", "value", v, "default", grantReaperInterval)
+		}
+	}
+	// The proxy and the webapp share the Casbin Redis adapter's default key
+	grantStoresSpec := os.Getenv("GRANT_STORES")
+	if grantStoresSpec == "" {
+		grantStoresSpec = "network.casbin.policy.updated=casbin_rules"
+	}
+	grantStores := make(map[string]string)
+	for _, entry := range strings.Split(grantStoresSpec, ",") {
+		subject, key, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || subject == "" || key == "" {
+			slog.Warn("Invalid GRANT_STORES entry (expected subject=key), ignoring it", "entry", entry)
+			continue
+		}
+		grantStores[subject] = key
+	}
+
+	authzBackend := os.Getenv("AUTHZ_BACKEND")
+	switch authzBackend {
+	case "":
+		authzBackend = AuthzCasbin
+	case AuthzCasbin, AuthzPermify, AuthzCompare:
+	default:
+		slog.Warn("Invalid AUTHZ_BACKEND (expected casbin, permify or compare), using casbin", "value", authzBackend)
+		authzBackend = AuthzCasbin
+	}
+
+	permifyCompareLimit := 32
+	if v := os.Getenv("PERMIFY_COMPARE_LIMIT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			permifyCompareLimit = n
+		} else {
+			slog.Warn("Invalid PERMIFY_COMPARE_LIMIT, using the default", "value", v, "default", permifyCompareLimit)
+		}
+	}
+
+	var metadataMaxStaleness time.Duration
+	if v := os.Getenv("METADATA_MAX_STALENESS"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			metadataMaxStaleness = d
+		} else {
+			slog.Warn("Invalid METADATA_MAX_STALENESS, staleness bound disabled", "value", v)
+		}
+	}
+	metadataWaitTimeout := 2 * time.Second
+	if v := os.Getenv("METADATA_WAIT_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			metadataWaitTimeout = d
+		} else {
+			slog.Warn("Invalid METADATA_WAIT_TIMEOUT, using the default", "value", v, "default", metadataWaitTimeout)
+		}
+	}
+
+	metricsPort := os.Getenv("METRICS_PORT")
+	if metricsPort == "" {
+		metricsPort = "9090"
+	}
+
+	healthCheckInterval := 5 * time.Second
+	if v := os.Getenv("HEALTH_CHECK_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			healthCheckInterval = d
+		} else {
+			slog.Warn("Invalid HEALTH_CHECK_INTERVAL, using the default", "value", v, "default", healthCheckInterval)
+		}
+	}
+
+	auditCfg := audit.Config{
+		Enabled:         os.Getenv("AUDIT_ENABLED") != "false",
+		Stream:          os.Getenv("AUDIT_STREAM"),
+		MaxAge:          72 * time.Hour,
+		AllowSampleRate: 0.1,
+	}
+	if auditCfg.Stream == "" {
+		auditCfg.Stream = audit.DefaultStream
+	}
+	if v := os.Getenv("AUDIT_MAX_AGE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			auditCfg.MaxAge = d
+		} else {
+			slog.Warn("Invalid AUDIT_MAX_AGE, using the default", "value", v, "default", auditCfg.MaxAge, "error", err)
+		}
+	}
+	if v := os.Getenv("AUDIT_ALLOW_SAMPLE_RATE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+			auditCfg.AllowSampleRate = f
+		} else {
+			slog.Warn("Invalid AUDIT_ALLOW_SAMPLE_RATE (expected 0..1), using the default", "value", v, "default", auditCfg.AllowSampleRate)
+		}
+	}
+
+	slog.Info("Loading config", "grpc_port", grpcPort, "valkey_addr", valkeyAddr, "nats_addr", natsAddr,
+		"player_meta_prefix", playerMetaPrefix, "server_meta_prefix", serverMetaPrefix, "policy_update_subject", policyUpdateSubject)
 
 	return &Config{
 		GRPCPort:             grpcPort,
@@ -62,5 +232,34 @@ func LoadConfig() *Config {
 		NATSPassword:         natsPassword,
 		PlayerMetadataPrefix: playerMetaPrefix,
 		ServerMetadataPrefix: serverMetaPrefix,
+		MetadataMaxStaleness: metadataMaxStaleness,
+		MetadataWaitTimeout:  metadataWaitTimeout,
+		PolicyUpdateSubject:  policyUpdateSubject,
+		PolicyHistoryBucket:  policyHistoryBucket,
+		PolicySeedFile:       policySeedFile,
+		PolicySeedMode:       policySeedMode,
+		PolicyTestFile:       os.Getenv("POLICY_TEST_FILE"),
+		GrantsBucket:         grantsBucket,
+		GrantReaperInterval:  grantReaperInterval,
+		GrantStores:          grantStores,
+		AuthzBackend:         authzBackend,
+		PermifyURL:           os.Getenv("PERMIFY_URL"),
+		PermifyTenant:        os.Getenv("PERMIFY_TENANT"),
+		PermifyToken:         os.Getenv("PERMIFY_TOKEN"),
+		PermifyTuplesFile:    os.Getenv("PERMIFY_TUPLES_FILE"),
+		PermifyCompareLimit:  permifyCompareLimit,
+		GRPCTLSCert:          os.Getenv("GRPC_TLS_CERT"),
+		GRPCTLSKey:           os.Getenv("GRPC_TLS_KEY"),
+		GRPCTLSClientCA:      os.Getenv("GRPC_TLS_CLIENT_CA"),
+		GRPCClientCertRoles:  os.Getenv("GRPC_CLIENT_CERT_ROLES"),
+		GRPCAPIKeys:          os.Getenv("GRPC_API_KEYS"),
+		GRPCJWTSecret:        os.Getenv("GRPC_JWT_SECRET"),
+		GRPCJWTIssuer:        os.Getenv("GRPC_JWT_ISSUER"),
+		GRPCJWTAudience:      os.Getenv("GRPC_JWT_AUDIENCE"),
+		GRPCAnonymousRole:    os.Getenv("GRPC_ANONYMOUS_ROLE"),
+		GRPCReflection:       os.Getenv("GRPC_REFLECTION") != "false",
+		HealthCheckInterval:  healthCheckInterval,
+		MetricsPort:          metricsPort,
+		Audit:                auditCfg,
 	}
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/nats-io/nats.go"
+
+	"github.com/bafbi/minecraft-network/pkg/policywatch"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/grants"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/history"
+)
+
+// grantReaperAuthor is the author of the policy versions and updates made by the grant reaper.
+const grantReaperAuthor = "grant-reaper"
+
+// grantReaper removes time-bound rules once their grant has expired. Rules of the checker's
+// own store are removed through its enforcer; rules of other stores (e.g. group assignments
+// made on the proxy) are removed from that store's adapter, then the removal is published to
+// the enforcers of that store, which apply it in memory only. A grant is only released once
+// its rule was removed from storage, so a failed removal is retried on the next run.
+//
+// Every replica runs a reaper: removals are idempotent and each grant is released once.
+type grantReaper struct {
+	svc    *authService
+	nc     *nats.Conn
+	stores map[string]persist.Adapter // Adapters of the other stores by policy update subject
+}
+
+// Run removes expired rules every interval until ctx is done.
+func (r *grantReaper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		r.reap(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *grantReaper) reap(now time.Time) {
+	if r.svc.grants == nil {
+		return
+	}
+	list, err := r.svc.grants.List("")
+	if err != nil {
+		slog.Error("Grant reaper: failed to list grants", "error", err)
+		return
+	}
+
+	var expiredPolicies int
+	for _, g := range list {
+		if !g.Expired(now) {
+			continue
+		}
+		removed, err := r.remove(g)
+		if err != nil {
+			slog.Warn("Grant reaper: failed to remove expired rule, retrying later", "ptype", g.Ptype, "rule", g.Rule, "subject", g.Subject, "error", err)
+			continue
+		}
+		if removed && g.Subject == r.svc.policySubject {
+			expiredPolicies++
+		}
+		if _, err := r.svc.grants.Release(g); err != nil {
+			slog.Error("Grant reaper: failed to release grant", "ptype", g.Ptype, "rule", g.Rule, "subject", g.Subject, "error", err)
+			continue
+		}
+		slog.Info("Grant reaper: removed expired rule", "ptype", g.Ptype, "rule", g.Rule, "subject", g.Subject,
+			"expires_at", g.ExpiresAt, "author", g.Author)
+	}
+	if expiredPolicies > 0 {
+		r.svc.recordPolicyVersionBy(grantReaperAuthor, history.OpExpire)
+	}
+}
+
+// remove removes the rule of g from its store and reports whether this reaper removed it
+// from the checker's enforcer.
+func (r *grantReaper) remove(g grants.Grant) (bool, error) {
+	if g.Subject != r.svc.policySubject {
+		adapter, ok := r.stores[g.Subject]
+		if !ok {
+			return false, fmt.Errorf("no policy store configured for %s (see GRANT_STORES)", g.Subject)
+		}
+		if err := adapter.RemovePolicy(g.Sec, g.Ptype, g.Rule); err != nil {
+			return false, fmt.Errorf("failed to remove rule from the policy store of %s: %w", g.Subject, err)
+		}
+		// The rule is gone from storage; enforcers that miss this reload it on their next start
+		update := policywatch.PolicyUpdate{Op: policywatch.OpRemovePolicies, Sec: g.Sec, Ptype: g.Ptype, Rules: [][]string{g.Rule}}
+		if err := policywatch.Publish(r.nc, g.Subject, grantReaperAuthor, update); err != nil {
+			slog.Warn("Grant reaper: failed to publish rule removal", "subject", g.Subject, "error", err)
+		}
+		return false, nil
+	}
+	// The enforcer persists the removal and publishes it to the other replicas
+	if g.Sec == "g" {
+		return r.svc.enforcer.RemoveNamedGroupingPolicy(g.Ptype, g.Rule)
+	}
+	return r.svc.enforcer.RemoveNamedPolicy(g.Ptype, g.Rule)
+}
@@ -0,0 +1,144 @@
+// Package grants records the expiry of time-bound policy rules and group assignments in a
+// NATS KV bucket shared by every component that manages policies.
+//
+// A grant names the rule, the policy store it belongs to (by the NATS subject its policy
+// watchers listen on) and when it expires. The rule itself is stored as usual; the reaper
+// of the permissions-checker removes it from its store once the grant has expired.
+package grants
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// DefaultBucket is the KV bucket holding grants.
+const DefaultBucket = "permission_grants"
+
+// Grant is the expiry of a single rule.
+type Grant struct {
+	Subject   string    `json:"subject"`          // Policy update subject of the store holding the rule
+	Sec       string    `json:"sec"`              // Model section, "p" or "g"
+	Ptype     string    `json:"ptype"`            // Policy type, e.g. "p" or "g2"
+	Rule      []string  `json:"rule"`             // Rule as stored by the adapter
+	ExpiresAt time.Time `json:"expires_at"`       // Time after which the rule is removed
+	Author    string    `json:"author,omitempty"` // User or client that granted the rule
+	CreatedAt time.Time `json:"created_at"`
+
+	revision uint64
+}
+
+// Key identifies the grant of a rule in the bucket.
+func Key(subject, sec, ptype string, rule []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(append([]string{subject, sec, ptype}, rule...), "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Key returns the key of g in the bucket.
+func (g Grant) Key() string {
+	return Key(g.Subject, g.Sec, g.Ptype, g.Rule)
+}
+
+// Expired reports whether g has expired at now.
+func (g Grant) Expired(now time.Time) bool {
+	return !now.Before(g.ExpiresAt)
+}
+
+// Store reads and writes grants.
+type Store struct {
+	kv nats.KeyValue
+}
+
+// NewStore binds to the grants bucket, creating it if it does not exist.
+func NewStore(js nats.JetStreamContext, bucket string) (*Store, error) {
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		slog.Info("NATS KV bucket not found, attempting to create", "bucket", bucket)
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket, Description: "Expiry of time-bound permissions"})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NATS KV bucket '%s': %w", bucket, err)
+	}
+	return &Store{kv: kv}, nil
+}
+
+// Put records g, replacing the previous expiry of the same rule.
+func (s *Store) Put(g Grant) error {
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = time.Now().UTC()
+	}
+	data, err := json.Marshal(g)
+	if err != nil {
+		return fmt.Errorf("failed to encode grant: %w", err)
+	}
+	if _, err := s.kv.Put(g.Key(), data); err != nil {
+		return fmt.Errorf("failed to store grant: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the grant of a rule, making the rule permanent. It is not an error if
+// the rule has no grant.
+func (s *Store) Delete(subject, sec, ptype string, rule []string) error {
+	err := s.kv.Delete(Key(subject, sec, ptype, rule))
+	if err != nil && !errors.Is(err, nats.ErrKeyNotFound) {
+		return fmt.Errorf("failed to delete grant: %w", err)
+	}
+	return nil
+}
+
+// Release deletes g once its rule was removed, unless the grant was renewed since it was listed.
+// It reports whether the grant was deleted.
+func (s *Store) Release(g Grant) (bool, error) {
+	err := s.kv.Delete(g.Key(), nats.LastRevision(g.revision))
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, nats.ErrKeyNotFound) {
+		return false, nil
+	}
+	var apiErr *nats.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode == nats.JSErrCodeStreamWrongLastSequence {
+		return false, nil // Renewed or released by another replica
+	}
+	return false, fmt.Errorf("failed to release grant: %w", err)
+}
+
+// List returns every grant, optionally only those of the store published on subject.
+func (s *Store) List(subject string) ([]Grant, error) {
+	keys, err := s.kv.Keys()
+	if errors.Is(err, nats.ErrNoKeysFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list grants: %w", err)
+	}
+	grants := make([]Grant, 0, len(keys))
+	for _, key := range keys {
+		entry, err := s.kv.Get(key)
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			continue // Deleted since listed
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get grant %s: %w", key, err)
+		}
+		var g Grant
+		if err := json.Unmarshal(entry.Value(), &g); err != nil {
+			slog.Warn("Skipping undecodable grant", "key", key, "error", err)
+			continue
+		}
+		if subject != "" && g.Subject != subject {
+			continue
+		}
+		g.revision = entry.Revision()
+		grants = append(grants, g)
+	}
+	return grants, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/grpc"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/config"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/grpcauth"
+)
+
+// newGRPCAuth returns the server options securing the AuthService as configured in cfg:
+// TLS (mutual when a client CA is set), then API keys, JWTs and client certificates checked
+// per method by grpcauth. The interceptor is returned so that public methods can be added.
+//
+// Calls without credentials may only check permissions when no authentication is configured,
+// and are rejected otherwise; GRPC_ANONYMOUS_ROLE overrides this, "admin" being required to let
+// them manage policies.
+func newGRPCAuth(cfg *config.Config) ([]grpc.ServerOption, *grpcauth.Interceptor, error) {
+	var opts []grpc.ServerOption
+	var authenticators []grpcauth.Authenticator
+
+	if cfg.GRPCTLSCert != "" || cfg.GRPCTLSKey != "" {
+		creds, err := grpcauth.ServerCredentials(cfg.GRPCTLSCert, cfg.GRPCTLSKey, cfg.GRPCTLSClientCA)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, grpc.Creds(creds))
+		slog.Info("gRPC TLS enabled", "client_certificates_verified", cfg.GRPCTLSClientCA != "")
+	} else if cfg.GRPCTLSClientCA != "" {
+		return nil, nil, fmt.Errorf("GRPC_TLS_CLIENT_CA requires GRPC_TLS_CERT and GRPC_TLS_KEY")
+	}
+
+	if cfg.GRPCTLSClientCA != "" {
+		roles, err := grpcauth.ParseCertificateRoles(cfg.GRPCClientCertRoles)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid GRPC_CLIENT_CERT_ROLES: %w", err)
+		}
+		authenticators = append(authenticators, grpcauth.NewClientCertificates(roles))
+		slog.Info("Accepting client certificate identities", "count", len(roles))
+	}
+	if cfg.GRPCAPIKeys != "" {
+		keys, err := grpcauth.ParseAPIKeys(cfg.GRPCAPIKeys)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid GRPC_API_KEYS: %w", err)
+		}
+		authenticators = append(authenticators, grpcauth.NewAPIKeys(keys))
+		slog.Info("Accepting API keys", "count", len(keys))
+	}
+	if cfg.GRPCJWTSecret != "" {
+		authenticators = append(authenticators, grpcauth.NewJWT([]byte(cfg.GRPCJWTSecret), cfg.GRPCJWTIssuer, cfg.GRPCJWTAudience))
+		slog.Info("Accepting HS256 JWTs", "issuer", cfg.GRPCJWTIssuer, "audience", cfg.GRPCJWTAudience)
+	}
+
+	var anonymous *grpcauth.Identity
+	switch cfg.GRPCAnonymousRole {
+	case "":
+		if len(authenticators) == 0 {
+			// Proxies keep checking permissions, but nobody can change policies unauthenticated
+			anonymous = &grpcauth.Identity{Name: "anonymous", Role: grpcauth.RoleCheck, Method: "anonymous"}
+			slog.Warn("No gRPC client authentication configured, calls without credentials may only check permissions. Set GRPC_API_KEYS, GRPC_JWT_SECRET or GRPC_TLS_CLIENT_CA to manage policies.")
+		}
+	case "none":
+	default:
+		role, err := grpcauth.ParseRole(cfg.GRPCAnonymousRole)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid GRPC_ANONYMOUS_ROLE: %w", err)
+		}
+		anonymous = &grpcauth.Identity{Name: "anonymous", Role: role, Method: "anonymous"}
+		if role == grpcauth.RoleAdmin {
+			slog.Warn("GRPC_ANONYMOUS_ROLE=admin, every client may manage policies without credentials")
+		} else {
+			slog.Info("Calls without credentials get a role", "role", role)
+		}
+	}
+
+	interceptor := grpcauth.NewInterceptor(anonymous, authenticators...)
+	opts = append(opts, grpc.ChainUnaryInterceptor(interceptor.Unary()), grpc.ChainStreamInterceptor(interceptor.Stream()))
+	return opts, interceptor, nil
+}
@@ -0,0 +1,245 @@
+package grpcauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Metadata keys carrying client credentials.
+const (
+	APIKeyHeader        = "x-api-key"
+	AuthorizationHeader = "authorization"
+)
+
+// APIKey is a static client credential.
+type APIKey struct {
+	Name string
+	Role Role
+	Key  string
+}
+
+// ParseAPIKeys parses keys written as "name:role:key", separated by commas,
+// e.g. "proxy:check:s3cr3t,editor:admin:0th3r".
+func ParseAPIKeys(s string) ([]APIKey, error) {
+	var keys []APIKey
+	for i, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			// Do not echo the entry, it may contain a key
+			return nil, fmt.Errorf("invalid API key entry #%d: expected name:role:key", i+1)
+		}
+		role, err := ParseRole(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("API key %s: %w", parts[0], err)
+		}
+		keys = append(keys, APIKey{Name: parts[0], Role: role, Key: parts[2]})
+	}
+	return keys, nil
+}
+
+// APIKeys authenticates calls by the "x-api-key" metadata.
+type APIKeys struct {
+	keys []APIKey
+}
+
+// NewAPIKeys returns an authenticator accepting keys.
+func NewAPIKeys(keys []APIKey) *APIKeys {
+	return &APIKeys{keys: keys}
+}
+
+func (a *APIKeys) Authenticate(ctx context.Context) (Identity, error) {
+	values := metadata.ValueFromIncomingContext(ctx, APIKeyHeader)
+	if len(values) == 0 {
+		return Identity{}, ErrNoCredentials
+	}
+	given := sha256.Sum256([]byte(values[0]))
+	for _, k := range a.keys {
+		// Compare digests so that the comparison time does not depend on the key length
+		expected := sha256.Sum256([]byte(k.Key))
+		if subtle.ConstantTimeCompare(given[:], expected[:]) == 1 {
+			return Identity{Name: k.Name, Role: k.Role, Method: "api-key"}, nil
+		}
+	}
+	return Identity{}, errors.New("invalid API key")
+}
+
+// JWT authenticates calls by an HS256 JSON Web Token in the "authorization: Bearer" metadata.
+// The token's "sub" claim names the client and its "role" claim gives its role; "exp" is required.
+type JWT struct {
+	secret   []byte
+	issuer   string // Required "iss", if not empty
+	audience string // Required "aud", if not empty
+	now      func() time.Time
+}
+
+// NewJWT returns an authenticator accepting tokens signed with secret.
+func NewJWT(secret []byte, issuer, audience string) *JWT {
+	return &JWT{secret: secret, issuer: issuer, audience: audience, now: time.Now}
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Role      string          `json:"role"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt int64           `json:"exp"`
+	NotBefore int64           `json:"nbf"`
+}
+
+func (a *JWT) Authenticate(ctx context.Context) (Identity, error) {
+	values := metadata.ValueFromIncomingContext(ctx, AuthorizationHeader)
+	if len(values) == 0 {
+		return Identity{}, ErrNoCredentials
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return Identity{}, ErrNoCredentials
+	}
+	claims, err := a.verify(token)
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid token: %w", err)
+	}
+	role, err := ParseRole(claims.Role)
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid token: %w", err)
+	}
+	return Identity{Name: claims.Subject, Role: role, Method: "jwt"}, nil
+}
+
+func (a *JWT) verify(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return jwtClaims{}, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return jwtClaims{}, fmt.Errorf("malformed header: %w", err)
+	}
+	if header.Alg != "HS256" {
+		return jwtClaims{}, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return jwtClaims{}, errors.New("malformed signature")
+	}
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return jwtClaims{}, errors.New("bad signature")
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return jwtClaims{}, fmt.Errorf("malformed claims: %w", err)
+	}
+	now := a.now().Unix()
+	if claims.ExpiresAt == 0 || now >= claims.ExpiresAt {
+		return jwtClaims{}, errors.New("token expired or without expiry")
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return jwtClaims{}, errors.New("token not valid yet")
+	}
+	if claims.Subject == "" {
+		return jwtClaims{}, errors.New("missing subject")
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return jwtClaims{}, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if a.audience != "" && !hasAudience(claims.Audience, a.audience) {
+		return jwtClaims{}, errors.New("token not intended for this service")
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// hasAudience reports whether the "aud" claim (a string or a list of strings) contains audience.
+func hasAudience(raw json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		for _, a := range list {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ClientCertificates authenticates calls over mutual TLS by the common name of the verified
+// client certificate, mapped to a role.
+type ClientCertificates struct {
+	roles map[string]Role
+}
+
+// NewClientCertificates returns an authenticator accepting the common names of roles.
+func NewClientCertificates(roles map[string]Role) *ClientCertificates {
+	return &ClientCertificates{roles: roles}
+}
+
+// ParseCertificateRoles parses "commonName=role" pairs separated by commas,
+// e.g. "permissions-editor=admin,proxy=check".
+func ParseCertificateRoles(s string) (map[string]Role, error) {
+	roles := make(map[string]Role)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, roleName, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid certificate role %q: expected commonName=role", entry)
+		}
+		role, err := ParseRole(roleName)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %s: %w", name, err)
+		}
+		roles[name] = role
+	}
+	return roles, nil
+}
+
+func (a *ClientCertificates) Authenticate(ctx context.Context) (Identity, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Identity{}, ErrNoCredentials
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return Identity{}, ErrNoCredentials
+	}
+	name := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	role, ok := a.roles[name]
+	if !ok {
+		return Identity{}, fmt.Errorf("client certificate %q has no role", name)
+	}
+	return Identity{Name: name, Role: role, Method: "mtls"}, nil
+}
@@ -0,0 +1,208 @@
+// Package grpcauth authenticates the clients of the AuthService and authorizes every method
+// by the role of the client.
+//
+// Clients are identified by an API key ("x-api-key" metadata), a JWT ("authorization: Bearer"
+// metadata, HS256) or, over mutual TLS, their client certificate. Each identity has a role:
+//
+//   - RoleCheck may only check permissions (proxies and game servers);
+//   - RoleReadOnly may also read policies, versions and validate changes (dashboards);
+//   - RoleAdmin may also change policies (the permissions editor).
+package grpcauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+)
+
+// Role is what a client identity may do.
+type Role string
+
+const (
+	RoleCheck    Role = "check"
+	RoleReadOnly Role = "read-only"
+	RoleAdmin    Role = "admin"
+)
+
+// ParseRole parses a role name.
+func ParseRole(s string) (Role, error) {
+	switch r := Role(strings.TrimSpace(s)); r {
+	case RoleCheck, RoleReadOnly, RoleAdmin:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unknown role %q (expected check, read-only or admin)", s)
+	}
+}
+
+// Permission is what a method requires.
+type Permission int
+
+const (
+	PermCheck  Permission = iota // Permission checks
+	PermRead                     // Reading policies and their history
+	PermManage                   // Changing policies
+)
+
+func (p Permission) String() string {
+	switch p {
+	case PermCheck:
+		return "check"
+	case PermRead:
+		return "read"
+	default:
+		return "manage"
+	}
+}
+
+// Allows reports whether r grants p.
+func (r Role) Allows(p Permission) bool {
+	switch r {
+	case RoleAdmin:
+		return true
+	case RoleReadOnly:
+		return p <= PermRead
+	case RoleCheck:
+		return p == PermCheck
+	default:
+		return false
+	}
+}
+
+// MethodPermissions is the permission required by each AuthService method. Methods missing
+// from the map require PermManage.
+var MethodPermissions = map[string]Permission{
+	auth.AuthService_CheckPermission_FullMethodName:       PermCheck,
+	auth.AuthService_CheckPermissions_FullMethodName:      PermCheck,
+	auth.AuthService_CheckPermissionStream_FullMethodName: PermCheck,
+	auth.AuthService_ListPolicies_FullMethodName:          PermRead,
+	auth.AuthService_ListPolicyVersions_FullMethodName:    PermRead,
+	auth.AuthService_ExportPolicies_FullMethodName:        PermRead,
+	auth.AuthService_ValidatePolicies_FullMethodName:      PermRead,
+	auth.AuthService_AddPolicy_FullMethodName:             PermManage,
+	auth.AuthService_RemovePolicy_FullMethodName:          PermManage,
+	auth.AuthService_RollbackPolicy_FullMethodName:        PermManage,
+	auth.AuthService_ImportPolicies_FullMethodName:        PermManage,
+
+	// Server reflection only describes the API
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      PermRead,
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": PermRead,
+}
+
+// Identity is an authenticated client.
+type Identity struct {
+	Name   string // Key name, JWT subject or certificate common name
+	Role   Role
+	Method string // "api-key", "jwt", "mtls" or "anonymous"
+}
+
+type identityKey struct{}
+
+// FromContext returns the identity of the client of a call, set by the interceptors.
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// ErrNoCredentials is returned by an Authenticator when the call carries none of its credentials.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator identifies the client of a call from its metadata or transport.
+type Authenticator interface {
+	// Authenticate returns the client's identity, ErrNoCredentials if the call has no
+	// credentials of this kind, or another error if they are invalid.
+	Authenticate(ctx context.Context) (Identity, error)
+}
+
+// Interceptor authenticates every call and checks the role of the client against MethodPermissions.
+type Interceptor struct {
+	authenticators []Authenticator
+	anonymous      *Identity
+	public         map[string]bool
+}
+
+// NewInterceptor returns an interceptor trying authenticators in order. Calls without credentials
+// get the anonymous identity, or are rejected when anonymous is nil.
+func NewInterceptor(anonymous *Identity, authenticators ...Authenticator) *Interceptor {
+	return &Interceptor{authenticators: authenticators, anonymous: anonymous, public: make(map[string]bool)}
+}
+
+// AllowPublic lets methods be called without credentials, e.g. health checks.
+func (i *Interceptor) AllowPublic(methods ...string) {
+	for _, m := range methods {
+		i.public[m] = true
+	}
+}
+
+// Unary returns the unary server interceptor.
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := i.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the stream server interceptor.
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (i *Interceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	if i.public[method] {
+		return ctx, nil
+	}
+	id, err := i.authenticate(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Rejected unauthenticated call", "method", method, "error", err)
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	required, ok := MethodPermissions[method]
+	if !ok {
+		required = PermManage
+	}
+	if !id.Role.Allows(required) {
+		slog.WarnContext(ctx, "Denied call", "method", method, "caller", id.Name, "auth_method", id.Method, "role", id.Role, "required", required)
+		return nil, status.Errorf(codes.PermissionDenied, "%s requires the %s permission, %s has role %s", method, required, id.Name, id.Role)
+	}
+	return context.WithValue(ctx, identityKey{}, id), nil
+}
+
+func (i *Interceptor) authenticate(ctx context.Context) (Identity, error) {
+	for _, a := range i.authenticators {
+		id, err := a.Authenticate(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return id, err
+	}
+	if i.anonymous != nil {
+		return *i.anonymous, nil
+	}
+	return Identity{}, errors.New("credentials required (API key, JWT or client certificate)")
+}
+
+// identityStream carries the authorized context to stream handlers.
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,209 @@
+package grpcauth_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/grpcauth"
+)
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role                grpcauth.Role
+		check, read, manage bool
+	}{
+		{grpcauth.RoleCheck, true, false, false},
+		{grpcauth.RoleReadOnly, true, true, false},
+		{grpcauth.RoleAdmin, true, true, true},
+		{grpcauth.Role("unknown"), false, false, false},
+	}
+	for _, tt := range tests {
+		for p, want := range map[grpcauth.Permission]bool{grpcauth.PermCheck: tt.check, grpcauth.PermRead: tt.read, grpcauth.PermManage: tt.manage} {
+			if got := tt.role.Allows(p); got != want {
+				t.Errorf("%s.Allows(%s) = %v, want %v", tt.role, p, got, want)
+			}
+		}
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := grpcauth.ParseAPIKeys("proxy:check:s3cr3t, permissions-editor:admin:a:b")
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+	want := []grpcauth.APIKey{
+		{Name: "proxy", Role: grpcauth.RoleCheck, Key: "s3cr3t"},
+		{Name: "permissions-editor", Role: grpcauth.RoleAdmin, Key: "a:b"},
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("ParseAPIKeys = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("key %d = %v, want %v", i, keys[i], want[i])
+		}
+	}
+
+	for _, invalid := range []string{"proxy:check", "proxy:superuser:key", ":admin:key", "proxy:admin:"} {
+		if _, err := grpcauth.ParseAPIKeys(invalid); err == nil {
+			t.Errorf("ParseAPIKeys(%q) accepted an invalid entry", invalid)
+		}
+	}
+}
+
+// signToken returns an HS256 JWT with claims signed with secret.
+func signToken(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	unsigned := encode(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encode(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func withMetadata(kv ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+}
+
+func TestJWT(t *testing.T) {
+	const secret = "jwt-secret"
+	exp := time.Now().Add(time.Hour).Unix()
+	a := grpcauth.NewJWT([]byte(secret), "network", "permissions-checker")
+
+	tests := []struct {
+		name     string
+		token    string
+		wantRole grpcauth.Role
+		wantErr  bool
+	}{
+		{
+			name:     "valid",
+			token:    signToken(t, secret, map[string]any{"sub": "tooling", "role": "read-only", "iss": "network", "aud": "permissions-checker", "exp": exp}),
+			wantRole: grpcauth.RoleReadOnly,
+		},
+		{
+			name:     "audience list",
+			token:    signToken(t, secret, map[string]any{"sub": "tooling", "role": "admin", "iss": "network", "aud": []string{"other", "permissions-checker"}, "exp": exp}),
+			wantRole: grpcauth.RoleAdmin,
+		},
+		{
+			name:    "expired",
+			token:   signToken(t, secret, map[string]any{"sub": "tooling", "role": "admin", "iss": "network", "aud": "permissions-checker", "exp": time.Now().Add(-time.Minute).Unix()}),
+			wantErr: true,
+		},
+		{
+			name:    "without expiry",
+			token:   signToken(t, secret, map[string]any{"sub": "tooling", "role": "admin", "iss": "network", "aud": "permissions-checker"}),
+			wantErr: true,
+		},
+		{
+			name:    "bad signature",
+			token:   signToken(t, "other-secret", map[string]any{"sub": "tooling", "role": "admin", "iss": "network", "aud": "permissions-checker", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			token:   signToken(t, secret, map[string]any{"sub": "tooling", "role": "admin", "iss": "elsewhere", "aud": "permissions-checker", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			token:   signToken(t, secret, map[string]any{"sub": "tooling", "role": "admin", "iss": "network", "aud": "other", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "unknown role",
+			token:   signToken(t, secret, map[string]any{"sub": "tooling", "role": "root", "iss": "network", "aud": "permissions-checker", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			token:   "not-a-token",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := a.Authenticate(withMetadata(grpcauth.AuthorizationHeader, "Bearer "+tt.token))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Authenticate accepted the token as %+v", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if id.Name != "tooling" || id.Role != tt.wantRole || id.Method != "jwt" {
+				t.Errorf("Authenticate = %+v, want tooling with role %s", id, tt.wantRole)
+			}
+		})
+	}
+
+	if _, err := a.Authenticate(withMetadata()); err != grpcauth.ErrNoCredentials {
+		t.Errorf("Authenticate without a token = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	keys, err := grpcauth.ParseAPIKeys("proxy:check:proxy-key,permissions-editor:admin:editor-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonymous := &grpcauth.Identity{Name: "anonymous", Role: grpcauth.RoleCheck, Method: "anonymous"}
+	withAnonymous := grpcauth.NewInterceptor(anonymous, grpcauth.NewAPIKeys(keys)).Unary()
+	authenticated := grpcauth.NewInterceptor(nil, grpcauth.NewAPIKeys(keys)).Unary()
+
+	tests := []struct {
+		name        string
+		interceptor grpc.UnaryServerInterceptor
+		ctx         context.Context
+		method      string
+		wantCode    codes.Code
+		wantCaller  string
+	}{
+		{"editor manages policies", authenticated, withMetadata(grpcauth.APIKeyHeader, "editor-key"), auth.AuthService_AddPolicy_FullMethodName, codes.OK, "permissions-editor"},
+		{"editor rolls back", authenticated, withMetadata(grpcauth.APIKeyHeader, "editor-key"), auth.AuthService_RollbackPolicy_FullMethodName, codes.OK, "permissions-editor"},
+		{"proxy checks", authenticated, withMetadata(grpcauth.APIKeyHeader, "proxy-key"), auth.AuthService_CheckPermission_FullMethodName, codes.OK, "proxy"},
+		{"proxy cannot read", authenticated, withMetadata(grpcauth.APIKeyHeader, "proxy-key"), auth.AuthService_ListPolicies_FullMethodName, codes.PermissionDenied, ""},
+		{"unknown methods require manage", authenticated, withMetadata(grpcauth.APIKeyHeader, "proxy-key"), "/auth.AuthService/Unknown", codes.PermissionDenied, ""},
+		{"invalid key", withAnonymous, withMetadata(grpcauth.APIKeyHeader, "wrong"), auth.AuthService_CheckPermission_FullMethodName, codes.Unauthenticated, ""},
+		{"anonymous checks", withAnonymous, withMetadata(), auth.AuthService_CheckPermission_FullMethodName, codes.OK, "anonymous"},
+		{"anonymous cannot manage", withAnonymous, withMetadata(), auth.AuthService_AddPolicy_FullMethodName, codes.PermissionDenied, ""},
+		{"credentials required", authenticated, withMetadata(), auth.AuthService_CheckPermission_FullMethodName, codes.Unauthenticated, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var caller string
+			handler := func(ctx context.Context, _ any) (any, error) {
+				id, _ := grpcauth.FromContext(ctx)
+				caller = id.Name
+				return nil, nil
+			}
+			_, err := tt.interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %s (%v), want %s", code, err, tt.wantCode)
+			}
+			if caller != tt.wantCaller {
+				t.Errorf("handler saw caller %q, want %q", caller, tt.wantCaller)
+			}
+		})
+	}
+}
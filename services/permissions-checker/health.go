@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/cache"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/config"
+)
+
+// LivenessService is the health service name reporting SERVING as long as the process serves
+// gRPC, for liveness probes. The overall ("") and AuthService statuses report readiness.
+const LivenessService = "liveness"
+
+// healthMonitor reports the readiness of the checker through the standard gRPC health service:
+// the Valkey policy store answers, NATS is connected and the metadata cache holds the initial
+// KV snapshot with its watchers running.
+type healthMonitor struct {
+	server *health.Server
+	cfg    *config.Config
+	nc     *nats.Conn
+	cache  *cache.MetadataCache
+	ready  bool
+	reason string
+}
+
+func newHealthMonitor(cfg *config.Config, nc *nats.Conn, mc *cache.MetadataCache) *healthMonitor {
+	m := &healthMonitor{server: health.NewServer(), cfg: cfg, nc: nc, cache: mc}
+	m.server.SetServingStatus(LivenessService, healthpb.HealthCheckResponse_SERVING)
+	m.setReady(false, "starting")
+	return m
+}
+
+// Run checks the dependencies every interval until ctx is cancelled, then reports NOT_SERVING.
+func (m *healthMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.check()
+		select {
+		case <-ctx.Done():
+			m.server.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *healthMonitor) check() {
+	var problems []string
+	if err := m.pingValkey(); err != nil {
+		problems = append(problems, fmt.Sprintf("valkey: %v", err))
+	}
+	if status := m.nc.Status(); status != nats.CONNECTED {
+		problems = append(problems, fmt.Sprintf("nats: %s", status))
+	}
+	if err := m.cache.Err(); err != nil {
+		problems = append(problems, err.Error())
+	} else if err := m.cache.Fresh(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	m.setReady(len(problems) == 0, strings.Join(problems, "; "))
+}
+
+func (m *healthMonitor) setReady(ready bool, reason string) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if ready {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	m.server.SetServingStatus("", status)
+	m.server.SetServingStatus(auth.AuthService_ServiceDesc.ServiceName, status)
+
+	// Log transitions only, reasons such as the metadata age change on every check
+	if ready != m.ready || m.reason == "starting" {
+		if ready {
+			slog.Info("Health: ready")
+		} else {
+			slog.Warn("Health: not ready", "reason", reason)
+		}
+	}
+	m.ready, m.reason = ready, reason
+}
+
+// pingValkey checks that the Valkey server holding the Casbin policies answers.
+func (m *healthMonitor) pingValkey() error {
+	opts := []redis.DialOption{
+		redis.DialConnectTimeout(2 * time.Second),
+		redis.DialReadTimeout(2 * time.Second),
+		redis.DialWriteTimeout(2 * time.Second),
+	}
+	if m.cfg.ValkeyPassword != "" {
+		opts = append(opts, redis.DialPassword(m.cfg.ValkeyPassword))
+	}
+	conn, err := redis.Dial("tcp", m.cfg.ValkeyAddr, opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	pong, err := redis.String(conn.Do("PING"))
+	if err != nil {
+		return err
+	}
+	if pong != "PONG" {
+		return errors.New("unexpected PING reply " + pong)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+// Package history records every policy mutation as a versioned snapshot in a NATS KV bucket.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// DefaultBucket is the KV bucket holding policy versions.
+const DefaultBucket = "policy_versions"
+
+// Operations recorded in a Version.
+const (
+	OpInit     = "init"
+	OpAdd      = "add"
+	OpRemove   = "remove"
+	OpRollback = "rollback"
+	OpImport   = "import"
+	OpExpire   = "expire" // Time-bound rules removed by the grant reaper
+)
+
+const (
+	latestKey     = "latest"
+	versionPrefix = "v."
+)
+
+// ErrVersionNotFound is returned when a version does not exist.
+var ErrVersionNotFound = errors.New("policy version not found")
+
+// Version is the policy set after a mutation, with the changes compared to the previous version.
+type Version struct {
+	Version      uint64     `json:"version"`
+	Time         time.Time  `json:"time"`
+	Author       string     `json:"author,omitempty"`
+	Operation    string     `json:"operation"`
+	Added        [][]string `json:"added,omitempty"`
+	Removed      [][]string `json:"removed,omitempty"`
+	Policies     [][]string `json:"policies"`
+	RolledBackTo uint64     `json:"rolled_back_to,omitempty"`
+}
+
+// Store reads and writes policy versions.
+type Store struct {
+	kv nats.KeyValue
+}
+
+// NewStore binds to the history bucket, creating it if it does not exist.
+func NewStore(js nats.JetStreamContext, bucket string) (*Store, error) {
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		slog.Info("NATS KV bucket not found, attempting to create", "bucket", bucket)
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket, Description: "Casbin policy versions"})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NATS KV bucket '%s': %w", bucket, err)
+	}
+	return &Store{kv: kv}, nil
+}
+
+func versionKey(v uint64) string {
+	return fmt.Sprintf("%s%020d", versionPrefix, v)
+}
+
+// Latest returns the number of the latest version, or 0 if none was recorded yet.
+func (s *Store) Latest() (uint64, error) {
+	n, _, err := s.latest()
+	return n, err
+}
+
+func (s *Store) latest() (uint64, uint64, error) {
+	entry, err := s.kv.Get(latestKey)
+	if errors.Is(err, nats.ErrKeyNotFound) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := strconv.ParseUint(string(entry.Value()), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latest policy version %q: %w", entry.Value(), err)
+	}
+	return n, entry.Revision(), nil
+}
+
+// Get returns a single version.
+func (s *Store) Get(v uint64) (Version, error) {
+	entry, err := s.kv.Get(versionKey(v))
+	if errors.Is(err, nats.ErrKeyNotFound) {
+		return Version{}, fmt.Errorf("%w: %d", ErrVersionNotFound, v)
+	}
+	if err != nil {
+		return Version{}, err
+	}
+	var version Version
+	if err := json.Unmarshal(entry.Value(), &version); err != nil {
+		return Version{}, fmt.Errorf("failed to decode policy version %d: %w", v, err)
+	}
+	return version, nil
+}
+
+// List returns up to limit versions older than before (0 for the latest), newest first.
+func (s *Store) List(limit int, before uint64) ([]Version, error) {
+	latest, err := s.Latest()
+	if err != nil {
+		return nil, err
+	}
+	if before == 0 || before > latest+1 {
+		before = latest + 1
+	}
+
+	versions := make([]Version, 0, limit)
+	for v := before - 1; v >= 1 && len(versions) < limit; v-- {
+		version, err := s.Get(v)
+		if errors.Is(err, ErrVersionNotFound) {
+			continue // Purged by the bucket's history limits
+		}
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// Record stores policies as a new version if they differ from the latest version.
+// It returns false when there was nothing to record.
+func (s *Store) Record(author, operation string, policies [][]string) (Version, bool, error) {
+	return s.record(Version{Author: author, Operation: operation, Policies: policies})
+}
+
+// RecordRollback stores policies restored from version target as a new version.
+func (s *Store) RecordRollback(author string, target uint64, policies [][]string) (Version, bool, error) {
+	return s.record(Version{Author: author, Operation: OpRollback, Policies: policies, RolledBackTo: target})
+}
+
+func (s *Store) record(version Version) (Version, bool, error) {
+	// Replicas may record concurrently: each version is created only once, and the "latest"
+	// pointer is moved to it afterwards with a revision check, so that it never designates
+	// a version that has not been written yet
+	for attempt := 0; attempt < 5; attempt++ {
+		latest, revision, err := s.latest()
+		if err != nil {
+			return Version{}, false, err
+		}
+
+		var previous [][]string
+		if latest > 0 {
+			prev, err := s.Get(latest)
+			if err != nil && !errors.Is(err, ErrVersionNotFound) {
+				return Version{}, false, err
+			}
+			previous = prev.Policies
+		}
+		version.Added, version.Removed = Diff(previous, version.Policies)
+		if latest > 0 && len(version.Added) == 0 && len(version.Removed) == 0 {
+			return Version{}, false, nil
+		}
+
+		version.Version = latest + 1
+		version.Time = time.Now().UTC()
+		data, err := json.Marshal(version)
+		if err != nil {
+			return Version{}, false, fmt.Errorf("failed to encode policy version: %w", err)
+		}
+
+		if _, err := s.kv.Create(versionKey(version.Version), data); err != nil {
+			if !errors.Is(err, nats.ErrKeyExists) {
+				return Version{}, false, fmt.Errorf("failed to store policy version %d: %w", version.Version, err)
+			}
+			// Another replica wrote this version first; it may have stopped before moving
+			// "latest", which is then moved for it before diffing against its version
+			s.setLatest(version.Version, revision)
+			continue
+		}
+		if err := s.setLatest(version.Version, revision); err != nil {
+			// Only a replica catching up on this version can have moved "latest" meanwhile
+			if current, _, lerr := s.latest(); lerr != nil || current < version.Version {
+				return Version{}, false, fmt.Errorf("failed to update latest policy version to %d: %w", version.Version, err)
+			}
+		}
+		return version, true, nil
+	}
+	return Version{}, false, errors.New("failed to record policy version: too many concurrent updates")
+}
+
+// setLatest moves the "latest" pointer to v, provided it is still at revision.
+func (s *Store) setLatest(v, revision uint64) error {
+	value := []byte(strconv.FormatUint(v, 10))
+	var err error
+	if revision == 0 {
+		_, err = s.kv.Create(latestKey, value)
+	} else {
+		_, err = s.kv.Update(latestKey, value, revision)
+	}
+	return err
+}
+
+// Diff returns the rules of after missing from before (added) and of before missing from after (removed).
+func Diff(before, after [][]string) (added, removed [][]string) {
+	beforeSet := ruleSet(before)
+	afterSet := ruleSet(after)
+	for _, rule := range after {
+		if _, ok := beforeSet[ruleKey(rule)]; !ok {
+			added = append(added, rule)
+		}
+	}
+	for _, rule := range before {
+		if _, ok := afterSet[ruleKey(rule)]; !ok {
+			removed = append(removed, rule)
+		}
+	}
+	return added, removed
+}
+
+func ruleSet(rules [][]string) map[string]struct{} {
+	set := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		set[ruleKey(rule)] = struct{}{}
+	}
+	return set
+}
+
+func ruleKey(rule []string) string {
+	return strings.Join(rule, "\x00")
+}
+
+// Clone copies a policy set, as returned by the enforcer, so it can be stored safely.
+func Clone(policies [][]string) [][]string {
+	cloned := make([][]string, len(policies))
+	for i, rule := range policies {
+		cloned[i] = slices.Clone(rule)
+	}
+	return cloned
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+)
+
+func TestListPoliciesWithMalformedPriority(t *testing.T) {
+	e, err := casbin.NewSyncedEnforcer("model.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range [][]string{
+		{"allow-join", "join", "server:lobby", "true", "true", "allow", "10"},
+		{"legacy", "kick", "*", "true", "true", "deny", "high"},
+	} {
+		if _, err := e.AddPolicy(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewAuthService(e, nil, nil, nil, nil, nil, "policy.updated")
+
+	list, err := s.ListPolicies(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		t.Fatalf("ListPolicies: %v", err)
+	}
+	rules := map[string]*auth.PolicyRule{}
+	for _, rule := range list.GetRules() {
+		rules[rule.GetId()] = rule
+	}
+	if r := rules["allow-join"]; r == nil || r.GetPriority() != 10 || r.GetError() != "" {
+		t.Errorf("valid rule listed as %v", r)
+	}
+	legacy := rules["legacy"]
+	if legacy == nil || legacy.GetError() == "" || legacy.GetEffect() != "deny" {
+		t.Fatalf("malformed rule listed as %v, want it with an error", legacy)
+	}
+
+	// The rule as listed removes the stored one, whose priority it does not carry
+	resp, err := s.RemovePolicy(context.Background(), &auth.PolicyManagementRequest{Rules: []*auth.PolicyRule{legacy}})
+	if err != nil || !resp.GetSuccess() {
+		t.Fatalf("RemovePolicy = %v, %v", resp, err)
+	}
+	if ok, _ := e.HasPolicy("legacy", "kick", "*", "true", "true", "deny", "high"); ok {
+		t.Error("malformed rule was not removed")
+	}
+	if ok, _ := e.HasPolicy("allow-join", "join", "server:lobby", "true", "true", "allow", "10"); !ok {
+		t.Error("valid rule was removed")
+	}
+}
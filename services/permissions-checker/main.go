@@ -3,86 +3,200 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time" // Added for context timeout in Ping
 
 	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/persist"
 	casbinredisadapter "github.com/casbin/redis-adapter/v2"
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
-	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/bafbi/minecraft-network/pkg/observability"
+	"github.com/bafbi/minecraft-network/pkg/policywatch"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/attributes"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/audit"
 	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
 	"github.com/bafbi/minecraft-network/services/permissions-checker/cache"
 	"github.com/bafbi/minecraft-network/services/permissions-checker/config"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/grants"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/grpcauth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/history"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/metrics"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/permify"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/policytest"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/priority"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/validation"
 )
 
+// tracerName is the instrumentation name of the checker's own spans.
+const tracerName = "github.com/bafbi/minecraft-network/services/permissions-checker"
+
 type authService struct {
 	auth.UnimplementedAuthServiceServer
-	enforcer      *casbin.Enforcer
+	enforcer      *casbin.SyncedEnforcer
 	metadataCache *cache.MetadataCache
+	auditRecorder *audit.Recorder
+	history       *history.Store
+	policyTests   *policytest.Suite // Suite a policy change must pass before it is applied, optional
+	validator     *validation.Validator
+	grants        *grants.Store       // Expiry of time-bound policies, optional
+	policySubject string              // Policy update subject of this store, identifying its grants
+	authzBackend  string              // config.AuthzCasbin, AuthzPermify or AuthzCompare
+	permify       *permify.Authorizer // Set unless authzBackend is config.AuthzCasbin
+	compareSlots  chan struct{}       // Bounds the Permify comparisons in flight when authzBackend is config.AuthzCompare
+	metadataWait  time.Duration       // How long checks wait for the metadata cache to be ready
 }
 
-func NewAuthService(e *casbin.Enforcer, mc *cache.MetadataCache) *authService {
-	return &authService{enforcer: e, metadataCache: mc}
+func NewAuthService(e *casbin.SyncedEnforcer, mc *cache.MetadataCache, ar *audit.Recorder, hs *history.Store, tests *policytest.Suite, gs *grants.Store, policySubject string) *authService {
+	return &authService{
+		enforcer:      e,
+		metadataCache: mc,
+		auditRecorder: ar,
+		history:       hs,
+		policyTests:   tests,
+		validator:     validation.New(e.GetModel(), attributes.Functions()),
+		grants:        gs,
+		policySubject: policySubject,
+	}
 }
 
 // CheckPermission implements the gRPC method
 func (s *authService) CheckPermission(ctx context.Context, req *auth.AuthRequest) (*auth.AuthResponse, error) {
-	log.Printf("CheckPermission Request for Player %s (%s): Action='%s', Resource='%s', Server='%s'",
-		req.GetPlayerName(), req.GetPlayerUuid(), req.GetAction(), req.GetResource(), req.GetServerName())
-
-	// 1. Get player metadata from local cache
-	playerAttrs := s.metadataCache.GetPlayerMetadata(req.GetPlayerUuid())
-	if playerAttrs == nil {
-		log.Printf("Warning: Player metadata not found in cache for %s. Defaulting to empty attributes.", req.GetPlayerUuid())
-		playerAttrs = &structpb.Struct{} // Provide empty struct to Casbin
+	if err := s.requireMetadata(ctx); err != nil {
+		return nil, err
 	}
+	resp := s.evaluate(ctx, req)
+	slog.InfoContext(ctx, "Decision", "player", req.GetPlayerName(), "player_uuid", req.GetPlayerUuid(),
+		"action", req.GetAction(), "resource", req.GetResource(), "server", req.GetServerName(), "allowed", resp.GetAllowed())
+	return resp, nil
+}
 
-	// Important: Casbin policies might expect player_uuid to be in the player attributes map
-	// Add player_uuid to the attributes if it's not already there
-	playerAttrsMap := playerAttrs.AsMap()
-	if _, ok := playerAttrsMap["player_uuid"]; !ok {
-		playerAttrsMap["player_uuid"] = req.GetPlayerUuid()
+// requireMetadata waits for the metadata cache to be ready, at most s.metadataWait, and fails with
+// codes.Unavailable when it is not or is staler than its bound, so that clients apply their
+// fail mode instead of getting decisions made without attributes.
+func (s *authService) requireMetadata(ctx context.Context) error {
+	if s.authzBackend == config.AuthzPermify {
+		return nil // Permify decides from relationships only
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, s.metadataWait)
+	defer cancel()
+	if err := s.metadataCache.WaitReady(waitCtx); err != nil {
+		slog.WarnContext(ctx, "Rejected permission check", "error", err)
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if err := s.metadataCache.Fresh(); err != nil {
+		slog.WarnContext(ctx, "Rejected permission check", "error", err)
+		return status.Error(codes.Unavailable, err.Error())
 	}
+	return nil
+}
 
-	// 2. Get server metadata from local cache (if server_name is provided)
-	serverAttrs := &structpb.Struct{} // Default to empty
+// evaluate runs a single permission check against the cached metadata and records it to the audit stream.
+// It does not log the decision so that batched and streamed checks can log a summary instead.
+func (s *authService) evaluate(ctx context.Context, req *auth.AuthRequest) *auth.AuthResponse {
+	ctx, span := observability.Tracer(tracerName).Start(ctx, "evaluate", trace.WithAttributes(
+		attribute.String("permission.action", req.GetAction()),
+		attribute.String("permission.resource", req.GetResource()),
+		attribute.String("permission.server", req.GetServerName()),
+	))
+	defer span.End()
+	if s.authzBackend == config.AuthzPermify {
+		return s.evaluatePermify(ctx, req)
+	}
+	start := time.Now()
+	// Metadata from the local cache, normalized into labels and annotations (see package attributes)
+	playerAttrs := attributes.Player(req.GetPlayerUuid(), req.GetPlayerName(), s.metadataCache.GetPlayerMetadata(req.GetPlayerUuid()).AsMap())
+	var serverMeta map[string]any
 	if req.GetServerName() != "" {
-		serverAttrs = s.metadataCache.GetServerMetadata(req.GetServerName())
-		if serverAttrs == nil {
-			log.Printf("Warning: Server metadata not found in cache for %s. Defaulting to empty attributes.", req.GetServerName())
-			serverAttrs = &structpb.Struct{} // Provide empty struct to Casbin
-		}
+		serverMeta = s.metadataCache.GetServerMetadata(req.GetServerName()).AsMap()
 	}
+	serverAttrs := attributes.Server(req.GetServerName(), serverMeta)
+	requestCtx := attributes.Context(req.GetServerName(), callerFromContext(ctx), req.GetRequestId(), start)
 
-	// Casbin expects arguments as interfaces.
-	// The order must match model.conf's request_definition (r = player, server, action, resource)
-	// Pass the *modified* playerAttrsMap
-	allowed, err := s.enforcer.Enforce(playerAttrsMap, serverAttrs.AsMap(), req.GetAction(), req.GetResource())
+	// The order must match model.conf's request_definition (r = player, server, action, resource, ctx)
+	allowed, matched, err := s.enforcer.EnforceEx(playerAttrs, serverAttrs, req.GetAction(), req.GetResource(), requestCtx)
+
+	decisionRecord := audit.Decision{
+		Time:          start,
+		Caller:        callerFromContext(ctx),
+		RequestID:     req.GetRequestId(),
+		TraceID:       observability.TraceID(ctx),
+		Subject:       req.GetPlayerUuid(),
+		SubjectName:   req.GetPlayerName(),
+		Object:        req.GetResource(),
+		Server:        req.GetServerName(),
+		Action:        req.GetAction(),
+		Allowed:       allowed && err == nil,
+		MatchedPolicy: matched,
+		LatencyMicros: time.Since(start).Microseconds(),
+	}
 	if err != nil {
-		log.Printf("Casbin enforcement error: %v", err)
-		return &auth.AuthResponse{Allowed: false, Message: fmt.Sprintf("Internal error: %v", err)}, nil
+		decisionRecord.Error = err.Error()
+	}
+	metrics.ObserveDecision(config.AuthzCasbin, req.GetResource(), start, allowed, err)
+	s.auditRecorder.Record(decisionRecord)
+	if s.authzBackend == config.AuthzCompare {
+		s.comparePermify(req, decisionRecord.Allowed)
+	}
+
+	span.SetAttributes(attribute.Bool("permission.allowed", decisionRecord.Allowed))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		slog.ErrorContext(ctx, "Casbin enforcement error", "player_uuid", req.GetPlayerUuid(), "action", req.GetAction(), "resource", req.GetResource(), "error", err)
+		return &auth.AuthResponse{Allowed: false, Message: fmt.Sprintf("Internal error: %v", err), RequestId: req.GetRequestId()}
 	}
 
 	decision := "DENIED"
 	if allowed {
 		decision = "ALLOWED"
 	}
-	log.Printf("Decision for Player %s (%s) on %s %s (Server: %s): %s",
-		req.GetPlayerName(), req.GetPlayerUuid(), req.GetAction(), req.GetResource(), req.GetServerName(), decision)
+	return &auth.AuthResponse{Allowed: allowed, Message: fmt.Sprintf("Permission %s", decision), RequestId: req.GetRequestId()}
+}
 
-	return &auth.AuthResponse{Allowed: allowed, Message: fmt.Sprintf("Permission %s", decision)}, nil
+// callerFromContext identifies the client of a gRPC call for the audit log: its authenticated
+// identity, else the "x-caller" metadata set by clients, else the peer address.
+func callerFromContext(ctx context.Context) string {
+	if id, ok := grpcauth.FromContext(ctx); ok && id.Method != "anonymous" {
+		return id.Name
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if callers := md.Get("x-caller"); len(callers) > 0 {
+			return callers[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
 }
 
 // AddPolicy implements the gRPC method to add policies
 func (s *authService) AddPolicy(ctx context.Context, req *auth.PolicyManagementRequest) (*auth.PolicyManagementResponse, error) {
+	if errs := s.validateRules(req.GetRules()); len(errs) > 0 {
+		slog.WarnContext(ctx, "Rejected invalid policies", "error", errs)
+		return &auth.PolicyManagementResponse{Success: false, Message: "Invalid policy: " + errs.Error(), FieldErrors: fieldErrorsToProto(errs)}, nil
+	}
+	if err := s.checkPolicyChange(policyRulesToStrings(req.GetRules()), nil); err != nil {
+		slog.WarnContext(ctx, "Rejected policy addition", "error", err)
+		return &auth.PolicyManagementResponse{Success: false, Message: err.Error()}, nil
+	}
+
 	var addedCount int
 	for _, rule := range req.GetRules() {
 		// Casbin's AddPolicy expects string arguments matching the policy_definition (p = id, target_action, target_resource, player_condition_expr, server_condition_expr, effect, priority)
@@ -96,86 +210,118 @@ func (s *authService) AddPolicy(ctx context.Context, req *auth.PolicyManagementR
 			fmt.Sprintf("%d", rule.GetPriority()), // Convert int32 to string for Casbin
 		)
 		if err != nil {
-			log.Printf("Error adding policy %s: %v", rule.GetId(), err)
+			slog.ErrorContext(ctx, "Error adding policy", "policy_id", rule.GetId(), "error", err)
 			return &auth.PolicyManagementResponse{Success: false, Message: fmt.Sprintf("Error adding policy %s: %v", rule.GetId(), err)}, nil
 		}
 		if ok {
 			addedCount++
-			log.Printf("Added policy: %v", rule)
+			slog.InfoContext(ctx, "Added policy", "policy_id", rule.GetId(), "author", authorFromContext(ctx))
 		} else {
-			log.Printf("Policy already exists: %v", rule)
+			slog.InfoContext(ctx, "Policy already exists", "policy_id", rule.GetId())
 		}
 	}
-	s.enforcer.LoadPolicy() // Reload policy to ensure consistency if not automatically done by adapter
+	// Rules added again get the expiry of the request, so a grant can be extended or made permanent
+	s.recordGrants(authorFromContext(ctx), req.GetRules())
+	if addedCount > 0 {
+		s.recordPolicyVersion(ctx, history.OpAdd)
+	}
 	return &auth.PolicyManagementResponse{Success: true, Message: fmt.Sprintf("Successfully added %d policies", addedCount)}, nil
 }
 
 // RemovePolicy implements the gRPC method to remove policies
 func (s *authService) RemovePolicy(ctx context.Context, req *auth.PolicyManagementRequest) (*auth.PolicyManagementResponse, error) {
+	policies, err := s.storedPolicies(policyRulesToStrings(req.GetRules()))
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving policies", "error", err)
+		return &auth.PolicyManagementResponse{Success: false, Message: fmt.Sprintf("Error retrieving policies: %v", err)}, nil
+	}
+	if err := s.checkPolicyChange(nil, policies); err != nil {
+		slog.WarnContext(ctx, "Rejected policy removal", "error", err)
+		return &auth.PolicyManagementResponse{Success: false, Message: err.Error()}, nil
+	}
+
 	var removedCount int
-	for _, rule := range req.GetRules() {
-		ok, err := s.enforcer.RemovePolicy(
-			rule.GetId(),
-			rule.GetTargetAction(),
-			rule.GetTargetResource(),
-			rule.GetPlayerConditionExpression(),
-			rule.GetServerConditionExpression(),
-			rule.GetEffect(),
-			fmt.Sprintf("%d", rule.GetPriority()),
-		)
+	for _, p := range policies {
+		ok, err := s.enforcer.RemovePolicy(p)
 		if err != nil {
-			log.Printf("Error removing policy %s: %v", rule.GetId(), err)
-			return &auth.PolicyManagementResponse{Success: false, Message: fmt.Sprintf("Error removing policy %s: %v", rule.GetId(), err)}, nil
+			slog.ErrorContext(ctx, "Error removing policy", "policy_id", p[0], "error", err)
+			return &auth.PolicyManagementResponse{Success: false, Message: fmt.Sprintf("Error removing policy %s: %v", p[0], err)}, nil
 		}
 		if ok {
 			removedCount++
-			log.Printf("Removed policy: %v", rule)
+			slog.InfoContext(ctx, "Removed policy", "policy_id", p[0], "author", authorFromContext(ctx))
 		} else {
-			log.Printf("Policy not found: %v", rule)
+			slog.InfoContext(ctx, "Policy not found", "policy_id", p[0])
 		}
 	}
-	s.enforcer.LoadPolicy()
+	s.clearGrants(policies)
+	if removedCount > 0 {
+		s.recordPolicyVersion(ctx, history.OpRemove)
+	}
 	return &auth.PolicyManagementResponse{Success: true, Message: fmt.Sprintf("Successfully removed %d policies", removedCount)}, nil
 }
 
+// storedPolicies returns the stored policies designated by listed, the policies of a removal.
+// A malformed policy is listed by ListPolicies without its invalid priority, so a listed policy
+// stands for the malformed policies it was read from, if any.
+func (s *authService) storedPolicies(listed [][]string) ([][]string, error) {
+	policies := make([][]string, 0, len(listed))
+	for _, l := range listed {
+		stored, err := s.enforcer.GetFilteredPolicy(0, l[0])
+		if err != nil {
+			return nil, err
+		}
+		var malformed bool
+		for _, p := range stored {
+			if isMalformedListing(p, l) {
+				policies = append(policies, p)
+				malformed = true
+			}
+		}
+		if !malformed {
+			policies = append(policies, l)
+		}
+	}
+	return policies, nil
+}
+
 // ListPolicies implements the gRPC method to list all policies
 func (s *authService) ListPolicies(ctx context.Context, req *emptypb.Empty) (*auth.PolicyManagementRequest, error) {
 	policies, err := s.enforcer.GetPolicy()
 	if err != nil {
-		log.Printf("Error retrieving policies: %v", err)
+		slog.ErrorContext(ctx, "Error retrieving policies", "error", err)
 		return nil, fmt.Errorf("error retrieving policies: %v", err)
 	}
-	var pbRules []*auth.PolicyRule
-
+	// A stored policy that cannot be represented (e.g. a legacy one with an invalid priority) fails
+	// the decisions it matches. It is listed with its error rather than a made-up priority, so that
+	// the other policies stay visible and it can be removed.
+	rules := make([]*auth.PolicyRule, 0, len(policies))
+	expiries := s.policyExpiries()
 	for _, p := range policies {
-		if len(p) == 7 { // Ensure the policy string has the correct number of fields
-			priority, err := strconv.Atoi(p[6])
-			if err != nil {
-				log.Printf("Warning: Could not parse priority for policy %s: %v", p[0], err)
-				priority = 0 // Default to 0 or handle error as appropriate
-			}
-			pbRules = append(pbRules, &auth.PolicyRule{
-				Id:                        p[0],
-				TargetAction:              p[1],
-				TargetResource:            p[2],
-				PlayerConditionExpression: p[3],
-				ServerConditionExpression: p[4],
-				Effect:                    p[5],
-				Priority:                  int32(priority),
-			})
-		} else {
-			log.Printf("Warning: Policy has unexpected number of fields: %v", p)
+		rule, err := policyRuleFromStrings(p)
+		if err != nil {
+			slog.WarnContext(ctx, "Listing malformed policy", "policy", p, "error", err)
+			rule = malformedPolicyRule(p, err)
 		}
+		rule.ExpiresAt = expiries[grants.Key(s.policySubject, "p", "p", p)]
+		rules = append(rules, rule)
 	}
-	return &auth.PolicyManagementRequest{Rules: pbRules}, nil
+	return &auth.PolicyManagementRequest{Rules: rules}, nil
 }
 
 func main() {
+	logger := observability.SetupLogging("permissions-checker")
 	cfg := config.LoadConfig()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// Spans of incoming calls continue the traces of the proxy and the editor
+	shutdownTracing, err := observability.SetupTracing(ctx, "permissions-checker", logger)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+
 	// --- 2. Initialize Casbin Enforcer ---
 	adapter := casbinredisadapter.NewAdpaterWithOption(
 		casbinredisadapter.WithNetwork("tcp"),
@@ -183,15 +329,22 @@ func main() {
 		casbinredisadapter.WithPassword(cfg.ValkeyPassword),
 		casbinredisadapter.WithKey(cfg.ValkeyKey),
 	)
-	enforcer, err := casbin.NewEnforcer("model.conf", adapter)
+	// Decisions are made concurrently by the gRPC handlers while the watcher, the grant reaper
+	// and the metrics read or change the rules: every access goes through the enforcer's lock.
+	enforcer, err := casbin.NewSyncedEnforcer("model.conf", adapter)
 	if err != nil {
-		log.Fatalf("Failed to create Casbin enforcer: %v", err)
+		fatal("Failed to create Casbin enforcer", err)
 	}
 	err = enforcer.LoadPolicy()
 	if err != nil {
-		log.Fatalf("Failed to load policies from adapter: %v", err)
+		fatal("Failed to load policies from adapter", err)
+	}
+	attributes.Register(enforcer.Enforcer)
+	// Decisions follow explicit priorities: highest priority wins, deny overrides allow at equal priority
+	if err := priority.Use(enforcer.Enforcer); err != nil {
+		fatal("Failed to set up policy priorities", err)
 	}
-	log.Println("Casbin policies loaded.")
+	slog.Info("Casbin policies loaded")
 
 	// --- 3. Initialize NATS Connection and Key-Value Store for Metadata ---
 	natsOpts := []nats.Option{nats.Name("MinecraftAuthService")}
@@ -200,46 +353,158 @@ func main() {
 	}
 	nc, err := nats.Connect(cfg.NATSAddr, natsOpts...)
 	if err != nil {
-		log.Fatalf("Could not connect to NATS: %v", err)
+		fatal("Could not connect to NATS", err)
 	}
 	defer nc.Close()
-	log.Println("Successfully connected to NATS!")
+	slog.Info("Connected to NATS", "url", nc.ConnectedUrlRedacted())
+
+	// Keep the enforcers of all replicas in sync: local changes are published as deltas
+	// and deltas from other replicas are applied without touching the adapter.
+	policyWatcher, err := policywatch.NewNATSWatcher(nc, cfg.PolicyUpdateSubject, policywatch.Options{Logger: logger})
+	if err != nil {
+		fatal("Failed to create policy watcher", err)
+	}
+	defer policyWatcher.Close()
+	if err := enforcer.SetWatcher(policyWatcher); err != nil {
+		fatal("Failed to set policy watcher", err)
+	}
+	if err := policyWatcher.SetUpdateCallback(policywatch.DefaultUpdateCallback(enforcer, logger)); err != nil {
+		fatal("Failed to set policy watcher callback", err)
+	}
+	slog.Info("Policy watcher subscribed", "subject", cfg.PolicyUpdateSubject)
 
 	// Bind to the KV store (assuming it already exists as a JetStream KV bucket named "metadata")
 	// If you're running JetStream for the first time or need to create the bucket:
 	js, jsErr := nc.JetStream()
 	if jsErr != nil {
-		log.Fatalf("Could not get JetStream context: %v", jsErr)
+		fatal("Could not get JetStream context", jsErr)
 	}
 	kv, err := js.KeyValue("metadata")
 	if err != nil && err == nats.ErrBucketNotFound {
-		log.Printf("NATS KV bucket 'metadata' not found, attempting to create.")
+		slog.Info("NATS KV bucket not found, attempting to create", "bucket", "metadata")
 		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: "metadata"})
 		if err != nil {
-			log.Fatalf("Failed to create NATS KV bucket 'metadata': %v", err)
+			fatal("Failed to create NATS KV bucket 'metadata'", err)
 		}
 	} else if err != nil {
-		log.Fatalf("Failed to get NATS KV bucket 'metadata': %v", err)
+		fatal("Failed to get NATS KV bucket 'metadata'", err)
+	}
+	slog.Info("Connected to NATS KV bucket", "bucket", "metadata")
+
+	// Decisions are recorded to a JetStream stream (all denies, a sample of allows)
+	auditRecorder, err := audit.NewRecorder(nc, js, "permissions-checker", cfg.Audit)
+	if err != nil {
+		fatal("Failed to initialize decision audit log", err)
+	}
+	if auditRecorder != nil {
+		slog.Info("Recording decisions to JetStream", "stream", cfg.Audit.Stream, "allow_sample_rate", cfg.Audit.AllowSampleRate)
+	}
+
+	// Every policy mutation is recorded as a version that can be rolled back to
+	historyStore, err := history.NewStore(js, cfg.PolicyHistoryBucket)
+	if err != nil {
+		fatal("Failed to initialize policy history", err)
+	}
+	if latest, err := historyStore.Latest(); err != nil {
+		fatal("Failed to read policy history", err)
+	} else if latest == 0 {
+		policies, err := enforcer.GetPolicy()
+		if err != nil {
+			fatal("Failed to read policies", err)
+		}
+		if _, _, err := historyStore.Record("permissions-checker", history.OpInit, history.Clone(policies)); err != nil {
+			fatal("Failed to record initial policy version", err)
+		}
+		slog.Info("Recorded initial policy version", "policies", len(policies))
+	}
+
+	// Time-bound rules are removed once expired, here and in the stores of the other components
+	grantStore, err := grants.NewStore(js, cfg.GrantsBucket)
+	if err != nil {
+		fatal("Failed to initialize permission grants", err)
 	}
-	log.Println("Successfully connected to NATS KV 'metadata' bucket!")
 
 	// --- 4. Initialize and Start Metadata Cache ---
-	metadataCache := cache.NewMetadataCache(kv, cfg.PlayerMetadataPrefix, cfg.ServerMetadataPrefix)
+	metadataCache := cache.NewMetadataCache(kv, cfg.PlayerMetadataPrefix, cfg.ServerMetadataPrefix, cfg.MetadataMaxStaleness)
 	ctx, cancelMain := context.WithCancel(context.Background()) // Use a different context for main app lifetime
 	metadataCache.StartWatching(ctx)
 
 	// --- 5. Start gRPC server ---
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPCPort))
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		fatal("Failed to listen", err)
+	}
+	var policyTests *policytest.Suite
+	if cfg.PolicyTestFile != "" {
+		policyTests, err = policytest.LoadSuite(cfg.PolicyTestFile)
+		if err != nil {
+			fatal("Failed to load policy test suite", err)
+		}
+		slog.Info("Loaded policy test suite; policy changes must pass it", "cases", len(policyTests.Cases), "file", cfg.PolicyTestFile)
+	}
+
+	svc := NewAuthService(enforcer, metadataCache, auditRecorder, historyStore, policyTests, grantStore, cfg.PolicyUpdateSubject)
+	svc.authzBackend = cfg.AuthzBackend
+	svc.metadataWait = cfg.MetadataWaitTimeout
+	if cfg.AuthzBackend != config.AuthzCasbin {
+		svc.permify, err = newPermifyAuthorizer(ctx, cfg)
+		if err != nil {
+			fatal("Failed to initialize Permify authorization backend", err)
+		}
+		svc.compareSlots = make(chan struct{}, cfg.PermifyCompareLimit)
+		slog.Info("Authorization backend", "backend", cfg.AuthzBackend, "permify_url", cfg.PermifyURL, "compare_limit", cfg.PermifyCompareLimit)
+	}
+	if cfg.PolicySeedFile != "" {
+		if err := svc.seedPolicies(cfg.PolicySeedFile, cfg.PolicySeedMode); err != nil {
+			fatal("Failed to seed policies", err)
+		}
+		slog.Info("Seeded policies", "file", cfg.PolicySeedFile, "mode", cfg.PolicySeedMode)
+	}
+
+	// Expired rules of other stores are removed from their storage directly
+	grantStores := make(map[string]persist.Adapter, len(cfg.GrantStores))
+	for subject, key := range cfg.GrantStores {
+		grantStores[subject] = casbinredisadapter.NewAdpaterWithOption(
+			casbinredisadapter.WithNetwork("tcp"),
+			casbinredisadapter.WithAddress(cfg.ValkeyAddr),
+			casbinredisadapter.WithPassword(cfg.ValkeyPassword),
+			casbinredisadapter.WithKey(key),
+		)
+	}
+	reaper := &grantReaper{svc: svc, nc: nc, stores: grantStores}
+	go reaper.Run(ctx, cfg.GrantReaperInterval)
+	slog.Info("Removing expired permission grants", "interval", cfg.GrantReaperInterval)
+
+	serverOpts, interceptor, err := newGRPCAuth(cfg)
+	if err != nil {
+		fatal("Failed to set up gRPC authentication", err)
+	}
+	// Probes call the health service without credentials
+	interceptor.AllowPublic(healthpb.Health_Check_FullMethodName, healthpb.Health_Watch_FullMethodName)
+	// Metrics interceptors first, so that calls rejected by authentication are counted
+	serverOpts = append(append(metrics.ServerOptions(), serverOpts...), observability.GRPCServerOption())
+	s := grpc.NewServer(serverOpts...)
+	auth.RegisterAuthServiceServer(s, svc)
+
+	// Readiness stays NOT_SERVING until Valkey, NATS and the initial metadata snapshot are available
+	healthMonitor := newHealthMonitor(cfg, nc, metadataCache)
+	healthpb.RegisterHealthServer(s, healthMonitor.server)
+	go healthMonitor.Run(ctx, cfg.HealthCheckInterval)
+	if cfg.GRPCReflection {
+		reflection.Register(s)
 	}
-	s := grpc.NewServer()
-	auth.RegisterAuthServiceServer(s, NewAuthService(enforcer, metadataCache))
 
-	log.Printf("gRPC server listening on port %s", cfg.GRPCPort)
+	if err := metrics.Init(enforcer, metadataCache); err != nil {
+		fatal("Failed to register metrics", err)
+	}
+	if cfg.MetricsPort != "off" {
+		go metrics.Serve(ctx, ":"+cfg.MetricsPort)
+	}
+
+	slog.Info("gRPC server listening", "port", cfg.GRPCPort)
 	go func() {
 		if err := s.Serve(lis); err != nil {
-			log.Fatalf("Failed to serve gRPC: %v", err)
+			fatal("Failed to serve gRPC", err)
 		}
 	}()
 
@@ -248,8 +513,20 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan // Block until a signal is received
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
+	healthMonitor.server.Shutdown() // Stop receiving traffic before draining calls
 	s.GracefulStop()
 	cancelMain() // Stop NATS KV watchers
-	log.Println("Server gracefully stopped.")
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	slog.Info("Server gracefully stopped")
+}
+
+// fatal logs err and exits, for failures that prevent the service from running.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,200 @@
+// Package metrics exports Prometheus metrics of the permissions-checker: gRPC calls, decisions,
+// the metadata cache and the loaded policies.
+//
+// Denial spikes after a policy change show up as a rise of
+//
+//	sum by (resource_type) (rate(permissions_checker_decisions_total{result="deny"}[5m]))
+//
+// following a change of permissions_checker_policies.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/cache"
+)
+
+const namespace = "permissions_checker"
+
+var (
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC calls handled, by method and status code.",
+	}, []string{"method", "code"})
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Time taken to handle gRPC calls, by method. Streams are measured until they end.",
+		Buckets:   []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"method"})
+	decisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "decisions_total",
+		Help:      "Authorization decisions, by result (allow, deny or error), resource type (the resource prefix, e.g. server) and backend.",
+	}, []string{"result", "resource_type", "backend"})
+	decisionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "decision_duration_seconds",
+		Help:      "Time taken to evaluate a single decision, by backend.",
+		Buckets:   []float64{.00001, .000025, .00005, .0001, .00025, .0005, .001, .0025, .005, .01, .025, .05},
+	}, []string{"backend"})
+	permifyComparisons = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "permify_comparisons_total",
+		Help:      "Permify checks made in compare mode, by result (agree, disagree, error, or skipped when too many were in flight).",
+	}, []string{"result"})
+)
+
+var registry = prometheus.NewRegistry()
+
+// Init registers the metrics; the cache and policy gauges are read from mc and e when scraped.
+func Init(e *casbin.SyncedEnforcer, mc *cache.MetadataCache) error {
+	return errors.Join(
+		registry.Register(grpcRequests),
+		registry.Register(grpcDuration),
+		registry.Register(decisions),
+		registry.Register(decisionDuration),
+		registry.Register(permifyComparisons),
+		registry.Register(cacheCollector{mc: mc}),
+		registry.Register(policyCollector{e: e}),
+		registry.Register(collectors.NewGoCollector()),
+		registry.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})),
+	)
+}
+
+// Serve serves /metrics on addr until ctx is cancelled.
+func Serve(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	slog.Info("Serving Prometheus metrics", "addr", addr, "path", "/metrics")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Metrics server failed", "error", err)
+	}
+}
+
+// ServerOptions measure every gRPC call, including the ones rejected by authentication.
+// They must come before the other interceptors.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			start := time.Now()
+			resp, err := handler(ctx, req)
+			observeCall(info.FullMethod, start, err)
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			start := time.Now()
+			err := handler(srv, ss)
+			observeCall(info.FullMethod, start, err)
+			return err
+		}),
+	}
+}
+
+func observeCall(method string, start time.Time, err error) {
+	grpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	grpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// ObserveDecision records a decision on resource made by backend since start.
+func ObserveDecision(backend, resource string, start time.Time, allowed bool, err error) {
+	result := "deny"
+	switch {
+	case err != nil:
+		result = "error"
+	case allowed:
+		result = "allow"
+	}
+	decisions.WithLabelValues(result, ResourceType(resource), backend).Inc()
+	decisionDuration.WithLabelValues(backend).Observe(time.Since(start).Seconds())
+}
+
+// ObservePermifyComparison records the result of a Permify comparison in compare mode.
+func ObservePermifyComparison(result string) {
+	permifyComparisons.WithLabelValues(result).Inc()
+}
+
+// ResourceType returns the type of a resource such as "server:lobby-1" ("server"), "other" for
+// resources without a type.
+func ResourceType(resource string) string {
+	resourceType, _, found := strings.Cut(resource, ":")
+	if !found || resourceType == "" {
+		return "other"
+	}
+	return resourceType
+}
+
+// cacheCollector reports the size and update counts of the metadata cache.
+type cacheCollector struct {
+	mc *cache.MetadataCache
+}
+
+var (
+	cacheEntriesDesc = prometheus.NewDesc(namespace+"_metadata_cache_entries",
+		"Metadata objects in the cache, by section (players or servers).", []string{"section"}, nil)
+	cacheUpdatesDesc = prometheus.NewDesc(namespace+"_metadata_cache_updates_total",
+		"NATS KV updates applied to the metadata cache, by section and operation (put or delete).", []string{"section", "operation"}, nil)
+	cacheAgeDesc = prometheus.NewDesc(namespace+"_metadata_cache_age_seconds",
+		"Time since the metadata cache was last known to be up to date with NATS KV.", nil, nil)
+)
+
+func (c cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheEntriesDesc
+	ch <- cacheUpdatesDesc
+	ch <- cacheAgeDesc
+}
+
+func (c cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.mc.Stats()
+	for section, s := range map[string]cache.SectionStats{"players": stats.Players, "servers": stats.Servers} {
+		ch <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(s.Entries), section)
+		ch <- prometheus.MustNewConstMetric(cacheUpdatesDesc, prometheus.CounterValue, float64(s.Puts), section, "put")
+		ch <- prometheus.MustNewConstMetric(cacheUpdatesDesc, prometheus.CounterValue, float64(s.Deletes), section, "delete")
+	}
+	if syncedAt := c.mc.SyncedAt(); !syncedAt.IsZero() {
+		ch <- prometheus.MustNewConstMetric(cacheAgeDesc, prometheus.GaugeValue, time.Since(syncedAt).Seconds())
+	}
+}
+
+// policyCollector reports the number of rules loaded in the enforcer.
+type policyCollector struct {
+	e *casbin.SyncedEnforcer
+}
+
+var policiesDesc = prometheus.NewDesc(namespace+"_policies",
+	"Rules loaded in the Casbin enforcer, by policy type (p for policies, g for role assignments).", []string{"ptype"}, nil)
+
+func (c policyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- policiesDesc
+}
+
+func (c policyCollector) Collect(ch chan<- prometheus.Metric) {
+	lock := c.e.GetLock()
+	lock.RLock()
+	defer lock.RUnlock()
+	for _, sec := range []string{"p", "g"} {
+		for ptype, assertion := range c.e.GetModel()[sec] {
+			ch <- prometheus.MustNewConstMetric(policiesDesc, prometheus.GaugeValue, float64(len(assertion.Policy)), ptype)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package permify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+)
+
+// PlayerType is the entity type of the players checks are made for.
+const PlayerType = "player"
+
+// Authorizer answers AuthService CheckPermission requests from the relationships of a Client.
+type Authorizer struct {
+	client Client
+	schema []Entity
+}
+
+// NewAuthorizer writes Schema to client, then stores tuples (e.g. read with LoadTuples).
+func NewAuthorizer(ctx context.Context, client Client, tuples []Tuple) (*Authorizer, error) {
+	if _, err := client.WriteSchema(ctx, Schema); err != nil {
+		return nil, err
+	}
+	for _, t := range tuples {
+		if err := validateTuple(Schema, t); err != nil {
+			return nil, err
+		}
+	}
+	if err := client.WriteRelationships(ctx, tuples); err != nil {
+		return nil, err
+	}
+	return &Authorizer{client: client, schema: Schema}, nil
+}
+
+// Client returns the client the authorizer checks against, e.g. to manage relationships.
+func (a *Authorizer) Client() Client {
+	return a.client
+}
+
+// CheckPermission decides req like the AuthService method of the same name: the player may perform
+// req.Action on req.Resource ("<type>:<id>") if it has the permission named after the action on
+// that entity. Actions or resource types the schema does not define are denied without error.
+func (a *Authorizer) CheckPermission(ctx context.Context, req *auth.AuthRequest) (*auth.AuthResponse, error) {
+	entityRef, ok := a.resolve(req.GetResource(), req.GetAction())
+	if !ok {
+		return &auth.AuthResponse{
+			Allowed:   false,
+			Message:   fmt.Sprintf("Permission DENIED (no %q permission on %q in the Permify schema)", req.GetAction(), req.GetResource()),
+			RequestId: req.GetRequestId(),
+		}, nil
+	}
+	allowed, err := a.client.Check(ctx, entityRef, req.GetAction(), Reference{Type: PlayerType, ID: req.GetPlayerUuid()})
+	if err != nil {
+		return nil, err
+	}
+	decision := "DENIED"
+	if allowed {
+		decision = "ALLOWED"
+	}
+	return &auth.AuthResponse{Allowed: allowed, Message: fmt.Sprintf("Permission %s", decision), RequestId: req.GetRequestId()}, nil
+}
+
+// resolve maps a resource and action to the entity to check, if the schema defines the permission.
+func (a *Authorizer) resolve(resource, action string) (Reference, bool) {
+	typ, id, ok := strings.Cut(resource, ":")
+	if !ok || id == "" {
+		return Reference{}, false
+	}
+	e, ok := entity(a.schema, typ)
+	if !ok {
+		return Reference{}, false
+	}
+	if _, ok := e.permission(action); !ok {
+		return Reference{}, false
+	}
+	return Reference{Type: typ, ID: id}, true
+}
@@ -0,0 +1,127 @@
+package permify
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Client is the part of the Permify API used by the Authorizer. It is implemented by
+// HTTPClient for a Permify server and by Memory for local use.
+type Client interface {
+	// WriteSchema replaces the tenant's schema and returns its version.
+	WriteSchema(ctx context.Context, schema []Entity) (string, error)
+	// WriteRelationships stores tuples; tuples that already exist are kept.
+	WriteRelationships(ctx context.Context, tuples []Tuple) error
+	// DeleteRelationships removes tuples; missing tuples are ignored.
+	DeleteRelationships(ctx context.Context, tuples []Tuple) error
+	// Check reports whether subject has permission (or relation) on entity.
+	Check(ctx context.Context, entity Reference, permission string, subject Reference) (bool, error)
+}
+
+// Reference names an entity, e.g. server:survival, or a subject set, e.g. group:admin#member.
+type Reference struct {
+	Type     string
+	ID       string
+	Relation string // Only for subject sets
+}
+
+func (r Reference) String() string {
+	if r.Relation != "" {
+		return r.Type + ":" + r.ID + "#" + r.Relation
+	}
+	return r.Type + ":" + r.ID
+}
+
+// Tuple is a relationship: Subject has Relation on Entity.
+type Tuple struct {
+	Entity   Reference
+	Relation string
+	Subject  Reference
+}
+
+// String returns the tuple in Permify notation, e.g. "group:admin#member@player:<uuid>".
+func (t Tuple) String() string {
+	return t.Entity.String() + "#" + t.Relation + "@" + t.Subject.String()
+}
+
+// ParseTuple parses a tuple in Permify notation, e.g. "server:survival#visitor@group:vip#member".
+func ParseTuple(s string) (Tuple, error) {
+	entityPart, subjectPart, ok := strings.Cut(strings.TrimSpace(s), "@")
+	if !ok {
+		return Tuple{}, fmt.Errorf("invalid tuple %q: expected entity:id#relation@subject:id", s)
+	}
+	entityRef, relation, ok := strings.Cut(entityPart, "#")
+	if !ok || relation == "" {
+		return Tuple{}, fmt.Errorf("invalid tuple %q: missing relation", s)
+	}
+	entity, err := parseReference(entityRef)
+	if err != nil {
+		return Tuple{}, fmt.Errorf("invalid tuple %q: %w", s, err)
+	}
+	subjectRef, subjectRelation, _ := strings.Cut(subjectPart, "#")
+	subject, err := parseReference(subjectRef)
+	if err != nil {
+		return Tuple{}, fmt.Errorf("invalid tuple %q: %w", s, err)
+	}
+	subject.Relation = subjectRelation
+	return Tuple{Entity: entity, Relation: relation, Subject: subject}, nil
+}
+
+func parseReference(s string) (Reference, error) {
+	typ, id, ok := strings.Cut(s, ":")
+	if !ok || typ == "" || id == "" {
+		return Reference{}, fmt.Errorf("invalid reference %q: expected type:id", s)
+	}
+	return Reference{Type: typ, ID: id}, nil
+}
+
+// LoadTuples reads a relationship file: one tuple per line in Permify notation,
+// blank lines and lines starting with # are ignored.
+func LoadTuples(path string) ([]Tuple, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open relationship file: %w", err)
+	}
+	defer f.Close()
+
+	var tuples []Tuple
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		t, err := ParseTuple(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		tuples = append(tuples, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read relationship file: %w", err)
+	}
+	return tuples, nil
+}
+
+// validateTuple checks t against schema.
+func validateTuple(schema []Entity, t Tuple) error {
+	e, ok := entity(schema, t.Entity.Type)
+	if !ok {
+		return fmt.Errorf("tuple %s: unknown entity type %q", t, t.Entity.Type)
+	}
+	r, ok := e.relation(t.Relation)
+	if !ok {
+		return fmt.Errorf("tuple %s: %s has no relation %q", t, e.Name, t.Relation)
+	}
+	subject := t.Subject.Type
+	if t.Subject.Relation != "" {
+		subject += "#" + t.Subject.Relation
+	}
+	if !r.allows(subject) {
+		return fmt.Errorf("tuple %s: %s#%s does not accept %s subjects", t, e.Name, r.Name, subject)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package permify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPClient talks to the REST API of a Permify server (port 3476 by default).
+type HTTPClient struct {
+	baseURL string
+	tenant  string
+	token   string // Preshared key, when Permify authentication is enabled
+	http    *http.Client
+
+	mu            sync.RWMutex
+	schemaVersion string
+	snapToken     string // Snapshot of the latest write, so that checks see our own writes
+}
+
+var _ Client = (*HTTPClient)(nil)
+
+// NewHTTPClient returns a client for the Permify server at baseURL (e.g. "http://permify:3476")
+// using tenant (Permify's default tenant is "t1") and the preshared key token, if not empty.
+func NewHTTPClient(baseURL, tenant, token string, timeout time.Duration) (*HTTPClient, error) {
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid Permify URL %q: %w", baseURL, err)
+	}
+	if tenant == "" {
+		tenant = "t1"
+	}
+	return &HTTPClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		tenant:  tenant,
+		token:   token,
+		http:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+type entityJSON struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type subjectJSON struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	Relation string `json:"relation,omitempty"`
+}
+
+type tupleJSON struct {
+	Entity   entityJSON  `json:"entity"`
+	Relation string      `json:"relation"`
+	Subject  subjectJSON `json:"subject"`
+}
+
+func (c *HTTPClient) WriteSchema(ctx context.Context, schema []Entity) (string, error) {
+	var resp struct {
+		SchemaVersion string `json:"schema_version"`
+	}
+	if err := c.post(ctx, "/schemas/write", map[string]any{"schema": DSL(schema)}, &resp); err != nil {
+		return "", fmt.Errorf("failed to write Permify schema: %w", err)
+	}
+	c.mu.Lock()
+	c.schemaVersion = resp.SchemaVersion
+	c.mu.Unlock()
+	return resp.SchemaVersion, nil
+}
+
+func (c *HTTPClient) WriteRelationships(ctx context.Context, tuples []Tuple) error {
+	if len(tuples) == 0 {
+		return nil
+	}
+	body := map[string]any{
+		"metadata":   map[string]any{"schema_version": c.currentSchemaVersion()},
+		"tuples":     tuplesToJSON(tuples),
+		"attributes": []any{},
+	}
+	var resp struct {
+		SnapToken string `json:"snap_token"`
+	}
+	if err := c.post(ctx, "/data/write", body, &resp); err != nil {
+		return fmt.Errorf("failed to write Permify relationships: %w", err)
+	}
+	c.setSnapToken(resp.SnapToken)
+	return nil
+}
+
+func (c *HTTPClient) DeleteRelationships(ctx context.Context, tuples []Tuple) error {
+	// The delete API takes a filter: delete tuple by tuple to never match more than asked
+	for _, t := range tuples {
+		body := map[string]any{
+			"tuple_filter": map[string]any{
+				"entity":   map[string]any{"type": t.Entity.Type, "ids": []string{t.Entity.ID}},
+				"relation": t.Relation,
+				"subject":  map[string]any{"type": t.Subject.Type, "ids": []string{t.Subject.ID}, "relation": t.Subject.Relation},
+			},
+			"attribute_filter": map[string]any{},
+		}
+		var resp struct {
+			SnapToken string `json:"snap_token"`
+		}
+		if err := c.post(ctx, "/data/delete", body, &resp); err != nil {
+			return fmt.Errorf("failed to delete Permify relationship %s: %w", t, err)
+		}
+		c.setSnapToken(resp.SnapToken)
+	}
+	return nil
+}
+
+func (c *HTTPClient) Check(ctx context.Context, entity Reference, permission string, subject Reference) (bool, error) {
+	c.mu.RLock()
+	metadata := map[string]any{"schema_version": c.schemaVersion, "snap_token": c.snapToken, "depth": maxDepth}
+	c.mu.RUnlock()
+	body := map[string]any{
+		"metadata":   metadata,
+		"entity":     entityJSON{Type: entity.Type, ID: entity.ID},
+		"permission": permission,
+		"subject":    subjectJSON{Type: subject.Type, ID: subject.ID, Relation: subject.Relation},
+	}
+	var resp struct {
+		Can string `json:"can"`
+	}
+	if err := c.post(ctx, "/permissions/check", body, &resp); err != nil {
+		return false, fmt.Errorf("permify check failed: %w", err)
+	}
+	return resp.Can == "CHECK_RESULT_ALLOWED", nil
+}
+
+func (c *HTTPClient) currentSchemaVersion() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.schemaVersion
+}
+
+func (c *HTTPClient) setSnapToken(token string) {
+	if token == "" {
+		return
+	}
+	c.mu.Lock()
+	c.snapToken = token
+	c.mu.Unlock()
+}
+
+// post sends body to the tenant endpoint path and decodes the JSON response into out.
+func (c *HTTPClient) post(ctx context.Context, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/v1/tenants/%s%s", c.baseURL, url.PathEscape(c.tenant), path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s: %s", path, resp.Status, strings.TrimSpace(string(payload)))
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", path, err)
+	}
+	return nil
+}
+
+func tuplesToJSON(tuples []Tuple) []tupleJSON {
+	out := make([]tupleJSON, len(tuples))
+	for i, t := range tuples {
+		out[i] = tupleJSON{
+			Entity:   entityJSON{Type: t.Entity.Type, ID: t.Entity.ID},
+			Relation: t.Relation,
+			Subject:  subjectJSON{Type: t.Subject.Type, ID: t.Subject.ID, Relation: t.Subject.Relation},
+		}
+	}
+	return out
+}
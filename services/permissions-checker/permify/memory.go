@@ -0,0 +1,143 @@
+package permify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// maxDepth bounds the relationships followed by a check, as Permify's check depth does.
+const maxDepth = 20
+
+// ErrDepthExceeded is returned when a check follows more than maxDepth relationships,
+// e.g. because of a cycle in group memberships.
+var ErrDepthExceeded = errors.New("permify: maximum check depth exceeded")
+
+// Memory is an in-memory stand-in for Permify, evaluating the same schema and relationships
+// without a server. It is meant for local development and policy comparisons, not for
+// production: nothing is persisted or shared between replicas.
+type Memory struct {
+	mu        sync.RWMutex
+	schema    []Entity
+	version   int
+	relations map[string][]Reference // Subjects by "type:id#relation"
+}
+
+var _ Client = (*Memory)(nil)
+
+// NewMemory returns an empty in-memory store. A schema must be written before relationships.
+func NewMemory() *Memory {
+	return &Memory{relations: make(map[string][]Reference)}
+}
+
+func (m *Memory) WriteSchema(_ context.Context, schema []Entity) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.schema = schema
+	m.version++
+	return fmt.Sprintf("memory-%d", m.version), nil
+}
+
+func (m *Memory) WriteRelationships(_ context.Context, tuples []Tuple) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range tuples {
+		if err := validateTuple(m.schema, t); err != nil {
+			return err
+		}
+	}
+	for _, t := range tuples {
+		key := relationKey(t.Entity, t.Relation)
+		if !containsReference(m.relations[key], t.Subject) {
+			m.relations[key] = append(m.relations[key], t.Subject)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) DeleteRelationships(_ context.Context, tuples []Tuple) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range tuples {
+		key := relationKey(t.Entity, t.Relation)
+		subjects := m.relations[key]
+		for i, s := range subjects {
+			if s == t.Subject {
+				m.relations[key] = append(subjects[:i:i], subjects[i+1:]...)
+				break
+			}
+		}
+		if len(m.relations[key]) == 0 {
+			delete(m.relations, key)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Check(_ context.Context, entity Reference, permission string, subject Reference) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.check(entity, permission, subject, maxDepth)
+}
+
+// check resolves name (a relation or permission of entity's type) for subject.
+func (m *Memory) check(ref Reference, name string, subject Reference, depth int) (bool, error) {
+	if depth <= 0 {
+		return false, ErrDepthExceeded
+	}
+	e, ok := entity(m.schema, ref.Type)
+	if !ok {
+		return false, fmt.Errorf("permify: unknown entity type %q", ref.Type)
+	}
+
+	if _, ok := e.relation(name); ok {
+		for _, s := range m.relations[relationKey(ref, name)] {
+			if s == subject {
+				return true, nil
+			}
+			if s.Relation == "" {
+				continue
+			}
+			// Subject set, e.g. group:admin#member: check the subject against it
+			if ok, err := m.check(Reference{Type: s.Type, ID: s.ID}, s.Relation, subject, depth-1); err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+
+	p, ok := e.permission(name)
+	if !ok {
+		return false, fmt.Errorf("permify: %s has no relation or permission %q", e.Name, name)
+	}
+	for _, term := range p.Union {
+		if relation, target, ok := strings.Cut(term, "."); ok {
+			// Follow relation to the related entities and check their permission
+			for _, related := range m.relations[relationKey(ref, relation)] {
+				if ok, err := m.check(Reference{Type: related.Type, ID: related.ID}, target, subject, depth-1); err != nil || ok {
+					return ok, err
+				}
+			}
+			continue
+		}
+		if ok, err := m.check(ref, term, subject, depth-1); err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func relationKey(entity Reference, relation string) string {
+	return entity.Type + ":" + entity.ID + "#" + relation
+}
+
+func containsReference(refs []Reference, ref Reference) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
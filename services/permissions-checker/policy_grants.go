@@ -0,0 +1,83 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/grants"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/validation"
+)
+
+// validateExpiries rejects time-bound rules that would already be expired when stored.
+func validateExpiries(rules []*auth.PolicyRule, now time.Time) validation.Errors {
+	var errs validation.Errors
+	for _, rule := range rules {
+		if rule.GetExpiresAt() != nil && !rule.GetExpiresAt().AsTime().After(now) {
+			errs = append(errs, validation.FieldError{PolicyID: rule.GetId(), Field: validation.FieldExpiresAt, Message: "must be in the future"})
+		}
+	}
+	return errs
+}
+
+// recordGrants stores the expiry of the time-bound rules among rules and makes the
+// others permanent, replacing the expiry they had if they were added again.
+func (s *authService) recordGrants(author string, rules []*auth.PolicyRule) {
+	if s.grants == nil {
+		return
+	}
+	for _, rule := range rules {
+		p := policyRuleToStrings(rule)
+		var err error
+		if rule.GetExpiresAt() != nil {
+			err = s.grants.Put(grants.Grant{
+				Subject:   s.policySubject,
+				Sec:       "p",
+				Ptype:     "p",
+				Rule:      p,
+				ExpiresAt: rule.GetExpiresAt().AsTime(),
+				Author:    author,
+			})
+		} else {
+			err = s.grants.Delete(s.policySubject, "p", "p", p)
+		}
+		if err != nil {
+			// The rule is stored: it is permanent or keeps its previous expiry
+			slog.Error("Failed to record expiry of policy", "policy_id", rule.GetId(), "error", err)
+		}
+	}
+}
+
+// clearGrants forgets the expiry of policies removed or restored by a bulk change
+// (import, rollback), which are permanent from then on.
+func (s *authService) clearGrants(policies ...[][]string) {
+	if s.grants == nil {
+		return
+	}
+	for _, rules := range policies {
+		for _, p := range rules {
+			if err := s.grants.Delete(s.policySubject, "p", "p", p); err != nil {
+				slog.Error("Failed to clear expiry of policy", "policy", p, "error", err)
+			}
+		}
+	}
+}
+
+// policyExpiries returns the expiry of the time-bound policies by grant key.
+func (s *authService) policyExpiries() map[string]*timestamppb.Timestamp {
+	if s.grants == nil {
+		return nil
+	}
+	list, err := s.grants.List(s.policySubject)
+	if err != nil {
+		slog.Error("Failed to list policy expiries", "error", err)
+		return nil
+	}
+	expiries := make(map[string]*timestamppb.Timestamp, len(list))
+	for _, g := range list {
+		expiries[g.Key()] = timestamppb.New(g.ExpiresAt)
+	}
+	return expiries
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/grpcauth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/history"
+)
+
+// authorFromContext identifies who changed the policies: the "x-actor" metadata
+// set by management UIs for their signed-in user, otherwise the calling client.
+// Only admin clients may name another actor.
+func authorFromContext(ctx context.Context) string {
+	if id, ok := grpcauth.FromContext(ctx); ok && id.Role != grpcauth.RoleAdmin {
+		return callerFromContext(ctx)
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if actors := md.Get("x-actor"); len(actors) > 0 {
+			return actors[0]
+		}
+	}
+	return callerFromContext(ctx)
+}
+
+// recordPolicyVersion snapshots the current policy set after a mutation.
+// Failures are logged: the mutation itself has already been applied.
+func (s *authService) recordPolicyVersion(ctx context.Context, operation string) {
+	s.recordPolicyVersionBy(authorFromContext(ctx), operation)
+}
+
+// recordPolicyVersionBy is recordPolicyVersion for mutations not made by a client, e.g. expiries.
+func (s *authService) recordPolicyVersionBy(author, operation string) {
+	if s.history == nil {
+		return
+	}
+	policies, err := s.enforcer.GetPolicy()
+	if err != nil {
+		slog.Error("Failed to read policies for version history", "error", err)
+		return
+	}
+	version, recorded, err := s.history.Record(author, operation, history.Clone(policies))
+	if err != nil {
+		slog.Error("Failed to record policy version", "operation", operation, "author", author, "error", err)
+		return
+	}
+	if recorded {
+		slog.Info("Recorded policy version", "version", version.Version, "operation", operation,
+			"author", version.Author, "added", len(version.Added), "removed", len(version.Removed))
+	}
+}
+
+// ListPolicyVersions implements the gRPC method listing recorded policy versions
+func (s *authService) ListPolicyVersions(ctx context.Context, req *auth.ListPolicyVersionsRequest) (*auth.ListPolicyVersionsResponse, error) {
+	if s.history == nil {
+		return nil, status.Error(codes.Unavailable, "policy history is not enabled")
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = 50
+	}
+	versions, err := s.history.List(limit, req.GetBeforeVersion())
+	if err != nil {
+		slog.ErrorContext(ctx, "Error listing policy versions", "error", err)
+		return nil, status.Errorf(codes.Internal, "error listing policy versions: %v", err)
+	}
+
+	resp := &auth.ListPolicyVersionsResponse{Versions: make([]*auth.PolicyVersion, 0, len(versions))}
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, policyVersionToProto(v))
+	}
+	return resp, nil
+}
+
+// RollbackPolicy implements the gRPC method restoring the policy set of a previous version
+func (s *authService) RollbackPolicy(ctx context.Context, req *auth.RollbackPolicyRequest) (*auth.RollbackPolicyResponse, error) {
+	if s.history == nil {
+		return nil, status.Error(codes.Unavailable, "policy history is not enabled")
+	}
+	target, err := s.history.Get(req.GetVersion())
+	if errors.Is(err, history.ErrVersionNotFound) {
+		return nil, status.Errorf(codes.NotFound, "policy version %d not found", req.GetVersion())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error reading policy version %d: %v", req.GetVersion(), err)
+	}
+
+	current, err := s.enforcer.GetPolicy()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error retrieving policies: %v", err)
+	}
+	added, removed := history.Diff(current, target.Policies)
+	if !req.GetDryRun() {
+		_, groups, err := s.currentRules()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error retrieving group assignments: %v", err)
+		}
+		if err := s.checkPolicyTests(target.Policies, groups); err != nil {
+			return &auth.RollbackPolicyResponse{Success: false, Message: err.Error()}, nil
+		}
+	}
+	resp := &auth.RollbackPolicyResponse{
+		Success: true,
+		Added:   policyRulesFromStrings(added),
+		Removed: policyRulesFromStrings(removed),
+	}
+	if req.GetDryRun() {
+		resp.Message = fmt.Sprintf("Rollback to version %d would add %d and remove %d policies", target.Version, len(added), len(removed))
+		return resp, nil
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		resp.Message = fmt.Sprintf("Policies already match version %d", target.Version)
+		return resp, nil
+	}
+
+	// The enforcer persists the changes through the adapter and publishes them to the other replicas
+	if len(removed) > 0 {
+		if _, err := s.enforcer.RemovePolicies(removed); err != nil {
+			slog.ErrorContext(ctx, "Error removing policies during rollback", "version", target.Version, "error", err)
+			return &auth.RollbackPolicyResponse{Success: false, Message: fmt.Sprintf("Error removing policies: %v", err)}, nil
+		}
+	}
+	if len(added) > 0 {
+		if _, err := s.enforcer.AddPolicies(added); err != nil {
+			slog.ErrorContext(ctx, "Error adding policies during rollback", "version", target.Version, "error", err)
+			return &auth.RollbackPolicyResponse{Success: false, Message: fmt.Sprintf("Error adding policies: %v", err)}, nil
+		}
+	}
+
+	s.clearGrants(added, removed)
+
+	policies, err := s.enforcer.GetPolicy()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error retrieving policies: %v", err)
+	}
+	version, _, err := s.history.RecordRollback(authorFromContext(ctx), target.Version, history.Clone(policies))
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record rollback", "version", target.Version, "error", err)
+	} else {
+		resp.Version = policyVersionToProto(version)
+	}
+	slog.InfoContext(ctx, "Rolled back policies", "version", target.Version, "author", authorFromContext(ctx), "added", len(added), "removed", len(removed))
+	resp.Message = fmt.Sprintf("Rolled back to version %d: added %d and removed %d policies", target.Version, len(added), len(removed))
+	return resp, nil
+}
+
+func policyVersionToProto(v history.Version) *auth.PolicyVersion {
+	return &auth.PolicyVersion{
+		Version:      v.Version,
+		Time:         timestamppb.New(v.Time),
+		Author:       v.Author,
+		Operation:    v.Operation,
+		Added:        policyRulesFromStrings(v.Added),
+		Removed:      policyRulesFromStrings(v.Removed),
+		PolicyCount:  int32(len(v.Policies)),
+		RolledBackTo: v.RolledBackTo,
+	}
+}
+
+// policyRulesFromStrings converts Casbin policies to PolicyRules, skipping malformed ones.
+func policyRulesFromStrings(policies [][]string) []*auth.PolicyRule {
+	rules := make([]*auth.PolicyRule, 0, len(policies))
+	for _, p := range policies {
+		rule, err := policyRuleFromStrings(p)
+		if err != nil {
+			slog.Warn("Skipping malformed policy", "policy", p, "error", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// policyRulesToStrings converts PolicyRules to Casbin policies.
+func policyRulesToStrings(rules []*auth.PolicyRule) [][]string {
+	policies := make([][]string, len(rules))
+	for i, rule := range rules {
+		policies[i] = policyRuleToStrings(rule)
+	}
+	return policies
+}
+
+// policyRuleToStrings converts a PolicyRule to a Casbin policy, in policy_definition order.
+func policyRuleToStrings(rule *auth.PolicyRule) []string {
+	return []string{
+		rule.GetId(),
+		rule.GetTargetAction(),
+		rule.GetTargetResource(),
+		rule.GetPlayerConditionExpression(),
+		rule.GetServerConditionExpression(),
+		rule.GetEffect(),
+		strconv.Itoa(int(rule.GetPriority())),
+	}
+}
+
+// policyRuleFromStrings converts a Casbin policy (p = id, target_action, target_resource,
+// player_condition_expr, server_condition_expr, effect, priority) to a PolicyRule.
+func policyRuleFromStrings(p []string) (*auth.PolicyRule, error) {
+	if len(p) != 7 { // Ensure the policy string has the correct number of fields
+		return nil, fmt.Errorf("expected 7 fields, got %d", len(p))
+	}
+	priority, err := strconv.ParseInt(p[6], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s has an invalid priority %q", p[0], p[6])
+	}
+	return &auth.PolicyRule{
+		Id:                        p[0],
+		TargetAction:              p[1],
+		TargetResource:            p[2],
+		PlayerConditionExpression: p[3],
+		ServerConditionExpression: p[4],
+		Effect:                    p[5],
+		Priority:                  int32(priority),
+	}, nil
+}
+
+// malformedPolicyRule converts a Casbin policy that policyRuleFromStrings rejected with err,
+// keeping the fields it has and a zero priority, and reports err on the rule.
+func malformedPolicyRule(p []string, err error) *auth.PolicyRule {
+	fields := make([]string, 6)
+	copy(fields, p)
+	return &auth.PolicyRule{
+		Id:                        fields[0],
+		TargetAction:              fields[1],
+		TargetResource:            fields[2],
+		PlayerConditionExpression: fields[3],
+		ServerConditionExpression: fields[4],
+		Effect:                    fields[5],
+		Error:                     err.Error(),
+	}
+}
+
+// isMalformedListing reports whether stored is a malformed policy that ListPolicies lists as
+// listed, i.e. with the same fields but a zero priority.
+func isMalformedListing(stored, listed []string) bool {
+	if _, err := policyRuleFromStrings(stored); err == nil || listed[6] != "0" {
+		return false
+	}
+	fields := make([]string, 6)
+	copy(fields, stored)
+	for i := range fields {
+		if fields[i] != listed[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/history"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/policyfile"
+)
+
+// ImportPolicies implements the gRPC method merging a declarative policy file into the current rules
+func (s *authService) ImportPolicies(ctx context.Context, req *auth.ImportPoliciesRequest) (*auth.ImportPoliciesResponse, error) {
+	f, err := policyfile.Parse(req.GetContent(), req.GetFormat())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid policy file: %v", err)
+	}
+	if errs := s.validator.Policies(f.Policies); len(errs) > 0 {
+		return &auth.ImportPoliciesResponse{Success: false, Message: "Invalid policy file: " + errs.Error(), FieldErrors: fieldErrorsToProto(errs)}, nil
+	}
+	plan, err := s.planImport(f, importModeFromProto(req.GetMode()))
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
+	resp := &auth.ImportPoliciesResponse{
+		Success:       true,
+		Added:         policyRulesFromStrings(plan.AddPolicies),
+		Removed:       policyRulesFromStrings(plan.RemovePolicies),
+		AddedGroups:   groupAssignmentsFromStrings(plan.AddGroups),
+		RemovedGroups: groupAssignmentsFromStrings(plan.RemoveGroups),
+		Conflicts:     plan.Conflicts,
+	}
+	if req.GetDryRun() {
+		resp.Message = "Dry run: " + plan.Summary()
+		return resp, nil
+	}
+	if err := s.applyImport(ctx, plan); err != nil {
+		slog.ErrorContext(ctx, "Error importing policies", "error", err)
+		return &auth.ImportPoliciesResponse{Success: false, Message: fmt.Sprintf("Error importing policies: %v", err)}, nil
+	}
+	resp.Message = "Imported: " + plan.Summary()
+	return resp, nil
+}
+
+// ExportPolicies implements the gRPC method writing the current rules as a declarative policy file
+func (s *authService) ExportPolicies(ctx context.Context, req *auth.ExportPoliciesRequest) (*auth.ExportPoliciesResponse, error) {
+	policies, groups, err := s.currentRules()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error retrieving policies: %v", err)
+	}
+	format := req.GetFormat()
+	if format == "" {
+		format = policyfile.FormatYAML
+	}
+	content, err := policyfile.FromRules(policies, groups).Marshal(format)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "error exporting policies: %v", err)
+	}
+	return &auth.ExportPoliciesResponse{Content: content, Format: format}, nil
+}
+
+// hasGroupingPolicies reports whether the model defines a role (g) section.
+func (s *authService) hasGroupingPolicies() bool {
+	lock := s.enforcer.GetLock()
+	lock.RLock()
+	defer lock.RUnlock()
+	_, ok := s.enforcer.GetModel()["g"]["g"]
+	return ok
+}
+
+func (s *authService) currentRules() (policies, groups [][]string, err error) {
+	policies, err = s.enforcer.GetPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.hasGroupingPolicies() {
+		groups, err = s.enforcer.GetGroupingPolicy()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return policies, groups, nil
+}
+
+func (s *authService) planImport(f *policyfile.File, mode policyfile.Mode) (*policyfile.Plan, error) {
+	if len(f.Groups) > 0 && !s.hasGroupingPolicies() {
+		return nil, fmt.Errorf("the policy file contains group assignments but the model has no role definition (g)")
+	}
+	policies, groups, err := s.currentRules()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving policies: %w", err)
+	}
+	return policyfile.NewPlan(policies, groups, f, mode), nil
+}
+
+// applyImport applies an import plan through the enforcer, which persists the changes
+// and publishes them to the other replicas, then records a policy version.
+func (s *authService) applyImport(ctx context.Context, plan *policyfile.Plan) error {
+	if plan.Empty() {
+		return nil
+	}
+	policies, groups, err := s.currentRules()
+	if err != nil {
+		return fmt.Errorf("error retrieving policies: %w", err)
+	}
+	if err := s.checkPolicyTests(
+		applyRuleChanges(policies, plan.AddPolicies, plan.RemovePolicies),
+		applyRuleChanges(groups, plan.AddGroups, plan.RemoveGroups),
+	); err != nil {
+		return err
+	}
+	if len(plan.RemovePolicies) > 0 {
+		if _, err := s.enforcer.RemovePolicies(plan.RemovePolicies); err != nil {
+			return fmt.Errorf("removing policies: %w", err)
+		}
+	}
+	if len(plan.AddPolicies) > 0 {
+		if _, err := s.enforcer.AddPolicies(plan.AddPolicies); err != nil {
+			return fmt.Errorf("adding policies: %w", err)
+		}
+	}
+	if len(plan.RemoveGroups) > 0 {
+		if _, err := s.enforcer.RemoveGroupingPolicies(plan.RemoveGroups); err != nil {
+			return fmt.Errorf("removing group assignments: %w", err)
+		}
+	}
+	if len(plan.AddGroups) > 0 {
+		if _, err := s.enforcer.AddGroupingPolicies(plan.AddGroups); err != nil {
+			return fmt.Errorf("adding group assignments: %w", err)
+		}
+	}
+	s.clearGrants(plan.AddPolicies, plan.RemovePolicies)
+	slog.InfoContext(ctx, "Imported policies", "summary", plan.Summary())
+	s.recordPolicyVersion(ctx, history.OpImport)
+	return nil
+}
+
+// seedPolicies imports the policy file at path (e.g. mounted from a ConfigMap) on startup.
+func (s *authService) seedPolicies(path string, mode policyfile.Mode) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read policy seed file: %w", err)
+	}
+	f, err := policyfile.Parse(content, "")
+	if err != nil {
+		return fmt.Errorf("invalid policy seed file %s: %w", path, err)
+	}
+	if err := s.validator.Policies(f.Policies).Err(); err != nil {
+		return fmt.Errorf("invalid policy seed file %s: %w", path, err)
+	}
+	plan, err := s.planImport(f, mode)
+	if err != nil {
+		return err
+	}
+	for _, id := range plan.Conflicts {
+		slog.Warn("Policy seed: keeping existing policy, which differs from the seed file", "policy_id", id, "file", path)
+	}
+	return s.applyImport(context.Background(), plan)
+}
+
+func importModeFromProto(mode auth.ImportMode) policyfile.Mode {
+	switch mode {
+	case auth.ImportMode_IMPORT_MODE_REPLACE:
+		return policyfile.ModeReplace
+	case auth.ImportMode_IMPORT_MODE_PRUNE:
+		return policyfile.ModePrune
+	default:
+		return policyfile.ModeAdd
+	}
+}
+
+func groupAssignmentsFromStrings(groups [][]string) []*auth.GroupAssignment {
+	assignments := make([]*auth.GroupAssignment, 0, len(groups))
+	for _, g := range groups {
+		if len(g) < 2 {
+			continue
+		}
+		a := &auth.GroupAssignment{Member: g[0], Group: g[1]}
+		if len(g) > 2 {
+			a.Domain = g[2]
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/history"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/policytest"
+)
+
+// ValidatePolicies implements the gRPC method validating the added rules and running a policy test suite against a proposed change
+func (s *authService) ValidatePolicies(ctx context.Context, req *auth.ValidatePoliciesRequest) (*auth.ValidatePoliciesResponse, error) {
+	if errs := s.validateRules(req.GetAdd()); len(errs) > 0 {
+		return &auth.ValidatePoliciesResponse{Passed: false, Message: "Invalid policy: " + errs.Error(), FieldErrors: fieldErrorsToProto(errs)}, nil
+	}
+
+	suite := s.policyTests
+	if len(req.GetSuite()) > 0 {
+		var err error
+		if suite, err = policytest.ParseSuite(req.GetSuite()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+	}
+	if suite == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no policy test suite configured or given")
+	}
+
+	policies, groups, err := s.currentRules()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error retrieving policies: %v", err)
+	}
+	policies = applyRuleChanges(policies, policyRulesToStrings(req.GetAdd()), policyRulesToStrings(req.GetRemove()))
+
+	results, err := s.runPolicyTests(suite, policies, groups)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error running policy tests: %v", err)
+	}
+
+	resp := &auth.ValidatePoliciesResponse{Passed: true, Results: make([]*auth.PolicyTestResult, 0, len(results))}
+	for _, r := range results {
+		result := &auth.PolicyTestResult{
+			Name:          r.Case.Name,
+			Passed:        r.Passed(),
+			Expected:      r.Case.Expect,
+			Actual:        r.Actual(),
+			MatchedPolicy: r.Matched,
+		}
+		if r.Err != nil {
+			result.Error = r.Err.Error()
+		}
+		resp.Passed = resp.Passed && result.Passed
+		resp.Results = append(resp.Results, result)
+	}
+	failed := len(policytest.Failures(results))
+	resp.Message = fmt.Sprintf("%d of %d policy tests passed", len(results)-failed, len(results))
+	return resp, nil
+}
+
+// runPolicyTests evaluates suite against an in-memory copy of the model holding policies and groups.
+func (s *authService) runPolicyTests(suite *policytest.Suite, policies, groups [][]string) ([]policytest.Result, error) {
+	lock := s.enforcer.GetLock()
+	lock.RLock()
+	m := s.enforcer.GetModel().Copy()
+	lock.RUnlock()
+	e, err := policytest.NewEnforcer(m, history.Clone(policies), history.Clone(groups))
+	if err != nil {
+		return nil, err
+	}
+	return policytest.Run(e, suite), nil
+}
+
+// checkPolicyTests runs the configured suite against the rules a change would produce
+// and returns an error listing the failing cases. It does nothing when no suite is configured.
+func (s *authService) checkPolicyTests(policies, groups [][]string) error {
+	if s.policyTests == nil {
+		return nil
+	}
+	results, err := s.runPolicyTests(s.policyTests, policies, groups)
+	if err != nil {
+		return fmt.Errorf("policy tests could not run: %w", err)
+	}
+	failed := policytest.Failures(results)
+	if len(failed) == 0 {
+		return nil
+	}
+	lines := make([]string, len(failed))
+	for i, r := range failed {
+		lines[i] = r.String()
+	}
+	return fmt.Errorf("change rejected, %d policy tests failed: %s", len(failed), strings.Join(lines, "; "))
+}
+
+// checkPolicyChange runs the configured suite against the current policies with add and remove applied.
+func (s *authService) checkPolicyChange(add, remove [][]string) error {
+	if s.policyTests == nil {
+		return nil
+	}
+	policies, groups, err := s.currentRules()
+	if err != nil {
+		return fmt.Errorf("error retrieving policies: %w", err)
+	}
+	return s.checkPolicyTests(applyRuleChanges(policies, add, remove), groups)
+}
+
+// applyRuleChanges returns current without the rules of remove and with the missing rules of add.
+func applyRuleChanges(current, add, remove [][]string) [][]string {
+	removed := make(map[string]bool, len(remove))
+	for _, rule := range remove {
+		removed[strings.Join(rule, "\x00")] = true
+	}
+	present := make(map[string]bool, len(current)+len(add))
+	result := make([][]string, 0, len(current)+len(add))
+	for _, rules := range [][][]string{current, add} {
+		for _, rule := range rules {
+			k := strings.Join(rule, "\x00")
+			if removed[k] || present[k] {
+				continue
+			}
+			present[k] = true
+			result = append(result, rule)
+		}
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/policyfile"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/validation"
+)
+
+// validateRules statically checks rules against the model before they are stored. A rule
+// may not reuse the id of a different stored policy, so that each decision names a single rule,
+// and a time-bound rule must not be expired already.
+func (s *authService) validateRules(rules []*auth.PolicyRule) validation.Errors {
+	policies := make([]policyfile.Policy, len(rules))
+	for i, rule := range rules {
+		policies[i] = policyFromRule(rule)
+	}
+	errs := append(s.validator.Policies(policies), validateExpiries(rules, time.Now())...)
+
+	current, err := s.enforcer.GetPolicy()
+	if err != nil {
+		slog.Error("Error retrieving policies for validation", "error", err)
+		return errs
+	}
+	stored := make(map[string][]string, len(current))
+	for _, p := range current {
+		stored[p[0]] = p
+	}
+	for _, rule := range rules {
+		if p, ok := stored[rule.GetId()]; ok && !slices.Equal(p, policyRuleToStrings(rule)) {
+			errs = append(errs, validation.FieldError{PolicyID: rule.GetId(), Field: validation.FieldID, Message: "is already used by another policy; remove it first"})
+		}
+	}
+	return errs
+}
+
+func policyFromRule(rule *auth.PolicyRule) policyfile.Policy {
+	return policyfile.Policy{
+		ID:              rule.GetId(),
+		Action:          rule.GetTargetAction(),
+		Resource:        rule.GetTargetResource(),
+		PlayerCondition: rule.GetPlayerConditionExpression(),
+		ServerCondition: rule.GetServerConditionExpression(),
+		Effect:          rule.GetEffect(),
+		Priority:        int(rule.GetPriority()),
+	}
+}
+
+func fieldErrorsToProto(errs validation.Errors) []*auth.PolicyFieldError {
+	fieldErrors := make([]*auth.PolicyFieldError, len(errs))
+	for i, fe := range errs {
+		fieldErrors[i] = &auth.PolicyFieldError{PolicyId: fe.PolicyID, Field: fe.Field, Message: fe.Message}
+	}
+	return fieldErrors
+}
@@ -0,0 +1,120 @@
+package policyfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode selects how an imported file is merged with the current rules.
+type Mode int
+
+const (
+	// ModeAdd adds rules of the file that do not exist yet. Existing policies with the
+	// same ID but different content are left untouched and reported as conflicts.
+	ModeAdd Mode = iota
+	// ModeReplace is ModeAdd, but existing policies with the same ID are replaced by the file's version.
+	ModeReplace
+	// ModePrune makes the current rules equal to the file: rules missing from the file are removed.
+	ModePrune
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeAdd:
+		return "add"
+	case ModeReplace:
+		return "replace"
+	case ModePrune:
+		return "prune"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// Plan lists the changes needed to import a file.
+type Plan struct {
+	AddPolicies    [][]string
+	RemovePolicies [][]string
+	AddGroups      [][]string
+	RemoveGroups   [][]string
+	Conflicts      []string // IDs of policies that differ and were not replaced (ModeAdd)
+}
+
+// Empty reports whether the plan changes nothing.
+func (p *Plan) Empty() bool {
+	return len(p.AddPolicies) == 0 && len(p.RemovePolicies) == 0 && len(p.AddGroups) == 0 && len(p.RemoveGroups) == 0
+}
+
+// Summary describes the plan in one line.
+func (p *Plan) Summary() string {
+	s := fmt.Sprintf("%d policies to add, %d to remove, %d group assignments to add, %d to remove",
+		len(p.AddPolicies), len(p.RemovePolicies), len(p.AddGroups), len(p.RemoveGroups))
+	if len(p.Conflicts) > 0 {
+		s += fmt.Sprintf("; %d conflicting policies kept (%s)", len(p.Conflicts), strings.Join(p.Conflicts, ", "))
+	}
+	return s
+}
+
+// NewPlan computes the changes turning the current rules into the result of importing f with mode.
+func NewPlan(currentPolicies, currentGroups [][]string, f *File, mode Mode) *Plan {
+	plan := &Plan{}
+
+	current := make(map[string][]string, len(currentPolicies))
+	currentIDs := make(map[string][][]string, len(currentPolicies))
+	for _, rule := range currentPolicies {
+		current[key(rule)] = rule
+		if len(rule) > 0 {
+			currentIDs[rule[0]] = append(currentIDs[rule[0]], rule)
+		}
+	}
+	desired := make(map[string]bool, len(f.Policies))
+	for _, p := range f.Policies {
+		rule := p.Fields()
+		desired[key(rule)] = true
+		if _, exists := current[key(rule)]; exists {
+			continue
+		}
+		if existing := currentIDs[p.ID]; len(existing) > 0 {
+			if mode == ModeAdd {
+				plan.Conflicts = append(plan.Conflicts, p.ID)
+				continue
+			}
+			if mode == ModeReplace {
+				plan.RemovePolicies = append(plan.RemovePolicies, existing...)
+			}
+		}
+		plan.AddPolicies = append(plan.AddPolicies, rule)
+	}
+	if mode == ModePrune {
+		for _, rule := range currentPolicies {
+			if !desired[key(rule)] {
+				plan.RemovePolicies = append(plan.RemovePolicies, rule)
+			}
+		}
+	}
+
+	currentG := make(map[string]bool, len(currentGroups))
+	for _, rule := range currentGroups {
+		currentG[key(rule)] = true
+	}
+	desiredG := make(map[string]bool, len(f.Groups))
+	for _, g := range f.Groups {
+		rule := g.Fields()
+		desiredG[key(rule)] = true
+		if !currentG[key(rule)] {
+			plan.AddGroups = append(plan.AddGroups, rule)
+		}
+	}
+	if mode == ModePrune {
+		for _, rule := range currentGroups {
+			if !desiredG[key(rule)] {
+				plan.RemoveGroups = append(plan.RemoveGroups, rule)
+			}
+		}
+	}
+	return plan
+}
+
+func key(rule []string) string {
+	return strings.Join(rule, "\x00")
+}
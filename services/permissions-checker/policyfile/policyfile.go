@@ -0,0 +1,277 @@
+// Package policyfile reads and writes policies and group assignments in a declarative
+// YAML or CSV format, so they can be version-controlled and seeded on deploy.
+//
+// YAML:
+//
+//	policies:
+//	  - id: lobby-connect
+//	    action: connect
+//	    resource: server:lobby
+//	    player_condition: "true"
+//	    server_condition: "true"
+//	    effect: allow
+//	    priority: 100
+//	groups:
+//	  - member: 069a79f4-44e9-4726-a5be-fca90e38aaf5
+//	    group: group:admin
+//
+// CSV uses Casbin's policy file layout, one rule per line:
+//
+//	p, lobby-connect, connect, server:lobby, true, true, allow, 100
+//	g, 069a79f4-44e9-4726-a5be-fca90e38aaf5, group:admin
+package policyfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported formats.
+const (
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// Policy is a single "p" rule.
+type Policy struct {
+	ID              string `yaml:"id"`
+	Action          string `yaml:"action"`
+	Resource        string `yaml:"resource"`
+	PlayerCondition string `yaml:"player_condition"`
+	ServerCondition string `yaml:"server_condition"`
+	Effect          string `yaml:"effect"`
+	Priority        int    `yaml:"priority"`
+}
+
+// Group assigns a member (player UUID or group) to a group, optionally within a domain.
+type Group struct {
+	Member string `yaml:"member"`
+	Group  string `yaml:"group"`
+	Domain string `yaml:"domain,omitempty"`
+}
+
+// File is the content of a policy file.
+type File struct {
+	Policies []Policy `yaml:"policies"`
+	Groups   []Group  `yaml:"groups,omitempty"`
+}
+
+// DetectFormat guesses the format of content: CSV when every rule line starts with "p," or "g,".
+func DetectFormat(content []byte) string {
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, "p,") && !strings.HasPrefix(line, "g,") {
+			return FormatYAML
+		}
+	}
+	return FormatCSV
+}
+
+// Parse reads a policy file. An empty format is detected from the content.
+func Parse(content []byte, format string) (*File, error) {
+	if format == "" {
+		format = DetectFormat(content)
+	}
+	var (
+		f   *File
+		err error
+	)
+	switch strings.ToLower(format) {
+	case FormatYAML, "yml":
+		f, err = parseYAML(content)
+	case FormatCSV:
+		f, err = parseCSV(content)
+	default:
+		return nil, fmt.Errorf("unsupported policy file format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, f.Validate()
+}
+
+func parseYAML(content []byte) (*File, error) {
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid YAML policy file: %w", err)
+	}
+	return &f, nil
+}
+
+func parseCSV(content []byte) (*File, error) {
+	r := csv.NewReader(bytes.NewReader(content))
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.LazyQuotes = true
+
+	var f File
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV policy file: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+
+		switch record[0] {
+		case "p":
+			if len(record) != 8 {
+				return nil, fmt.Errorf("line %d: policy needs 7 fields (id, action, resource, player condition, server condition, effect, priority), got %d", line, len(record)-1)
+			}
+			p, err := policyFromFields(record[1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			f.Policies = append(f.Policies, p)
+		case "g":
+			if len(record) != 3 && len(record) != 4 {
+				return nil, fmt.Errorf("line %d: group assignment needs 2 or 3 fields (member, group[, domain]), got %d", line, len(record)-1)
+			}
+			g := Group{Member: record[1], Group: record[2]}
+			if len(record) == 4 {
+				g.Domain = record[3]
+			}
+			f.Groups = append(f.Groups, g)
+		default:
+			return nil, fmt.Errorf("line %d: unknown rule type %q (expected \"p\" or \"g\")", line, record[0])
+		}
+	}
+	return &f, nil
+}
+
+func policyFromFields(fields []string) (Policy, error) {
+	priority, err := strconv.Atoi(fields[6])
+	if err != nil {
+		return Policy{}, fmt.Errorf("policy %s: invalid priority %q", fields[0], fields[6])
+	}
+	return Policy{
+		ID:              fields[0],
+		Action:          fields[1],
+		Resource:        fields[2],
+		PlayerCondition: fields[3],
+		ServerCondition: fields[4],
+		Effect:          fields[5],
+		Priority:        priority,
+	}, nil
+}
+
+// Validate checks required fields and duplicate policy IDs.
+func (f *File) Validate() error {
+	var errs []error
+	seen := make(map[string]bool, len(f.Policies))
+	for i, p := range f.Policies {
+		if p.ID == "" || p.Action == "" || p.Resource == "" || p.Effect == "" {
+			errs = append(errs, fmt.Errorf("policy #%d: id, action, resource and effect are required", i+1))
+			continue
+		}
+		if seen[p.ID] {
+			errs = append(errs, fmt.Errorf("policy %s: duplicate id", p.ID))
+		}
+		seen[p.ID] = true
+	}
+	for i, g := range f.Groups {
+		if g.Member == "" || g.Group == "" {
+			errs = append(errs, fmt.Errorf("group assignment #%d: member and group are required", i+1))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Marshal writes f in the given format.
+func (f *File) Marshal(format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "", FormatYAML, "yml":
+		return yaml.Marshal(f)
+	case FormatCSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		for _, p := range f.Policies {
+			if err := w.Write(append([]string{"p"}, p.Fields()...)); err != nil {
+				return nil, err
+			}
+		}
+		for _, g := range f.Groups {
+			if err := w.Write(append([]string{"g"}, g.Fields()...)); err != nil {
+				return nil, err
+			}
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	default:
+		return nil, fmt.Errorf("unsupported policy file format %q", format)
+	}
+}
+
+// Fields returns the Casbin rule of p
+// (p = id, target_action, target_resource, player_condition_expr, server_condition_expr, effect, priority).
+func (p Policy) Fields() []string {
+	return []string{p.ID, p.Action, p.Resource, p.PlayerCondition, p.ServerCondition, p.Effect, strconv.Itoa(p.Priority)}
+}
+
+// Fields returns the Casbin grouping rule of g.
+func (g Group) Fields() []string {
+	if g.Domain != "" {
+		return []string{g.Member, g.Group, g.Domain}
+	}
+	return []string{g.Member, g.Group}
+}
+
+// FromRules builds a File from Casbin "p" and "g" rules. Malformed policies are skipped.
+func FromRules(policies, groups [][]string) *File {
+	f := &File{}
+	for _, rule := range policies {
+		if len(rule) != 7 {
+			continue
+		}
+		if p, err := policyFromFields(rule); err == nil {
+			f.Policies = append(f.Policies, p)
+		}
+	}
+	for _, rule := range groups {
+		if len(rule) < 2 {
+			continue
+		}
+		g := Group{Member: rule[0], Group: rule[1]}
+		if len(rule) > 2 {
+			g.Domain = rule[2]
+		}
+		f.Groups = append(f.Groups, g)
+	}
+	return f
+}
+
+// PolicyRules returns the Casbin "p" rules of f.
+func (f *File) PolicyRules() [][]string {
+	rules := make([][]string, len(f.Policies))
+	for i, p := range f.Policies {
+		rules[i] = p.Fields()
+	}
+	return rules
+}
+
+// GroupRules returns the Casbin "g" rules of f.
+func (f *File) GroupRules() [][]string {
+	rules := make([][]string, len(f.Groups))
+	for i, g := range f.Groups {
+		rules[i] = g.Fields()
+	}
+	return rules
+}
@@ -0,0 +1,235 @@
+// Package policytest evaluates policies against a suite of expected decisions.
+//
+// A suite is a YAML file of cases, each giving the player and server metadata of a request,
+// the action and resource, and the expected decision. Metadata is normalized into labels and
+// annotations like the checker does (see package attributes):
+//
+//	cases:
+//	  - name: vip players can join the lobby
+//	    player:
+//	      player_uuid: 069a79f4-44e9-4726-a5be-fca90e38aaf5
+//	      labels: {vip: "true"}
+//	    server:
+//	      labels: {type: lobby}
+//	    context: {proxy: proxy-0, hour: 20} # r.ctx, optional
+//	    action: connect
+//	    resource: server:lobby
+//	    expect: allow
+//
+// Suites can be run from Go tests with RunFiles:
+//
+//	func TestPolicies(t *testing.T) {
+//		policytest.RunFiles(t, "model.conf", "policies.yaml", "policy_tests.yaml")
+//	}
+//
+// and by permissions-checker before accepting a policy change (see the ValidatePolicies RPC).
+package policytest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/attributes"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/policyfile"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/priority"
+)
+
+// Expected decisions.
+const (
+	ExpectAllow = "allow"
+	ExpectDeny  = "deny"
+)
+
+// Case is a single request with its expected decision.
+type Case struct {
+	Name     string         `yaml:"name"`
+	Player   map[string]any `yaml:"player"`  // Player attributes (r.player)
+	Server   map[string]any `yaml:"server"`  // Server attributes (r.server)
+	Context  map[string]any `yaml:"context"` // Request context (r.ctx), optional
+	Action   string         `yaml:"action"`
+	Resource string         `yaml:"resource"`
+	Expect   string         `yaml:"expect"` // "allow" or "deny"
+}
+
+// Suite is the content of a test case file.
+type Suite struct {
+	Cases []Case `yaml:"cases"`
+}
+
+// Result is the outcome of a single case.
+type Result struct {
+	Case    Case
+	Allowed bool
+	Matched []string // Policy that decided, as reported by Casbin
+	Err     error    // Enforcement error; the case fails
+}
+
+// Passed reports whether the decision matched the expectation.
+func (r Result) Passed() bool {
+	return r.Err == nil && r.Actual() == r.Case.Expect
+}
+
+// Actual returns the decision as "allow" or "deny".
+func (r Result) Actual() string {
+	if r.Allowed {
+		return ExpectAllow
+	}
+	return ExpectDeny
+}
+
+// String describes the result in one line.
+func (r Result) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s: error: %v", r.Case.Name, r.Err)
+	case r.Passed():
+		return fmt.Sprintf("%s: ok (%s)", r.Case.Name, r.Actual())
+	default:
+		return fmt.Sprintf("%s: expected %s, got %s (matched %v)", r.Case.Name, r.Case.Expect, r.Actual(), r.Matched)
+	}
+}
+
+// ParseSuite reads a suite from YAML and checks that every case is complete.
+func ParseSuite(content []byte) (*Suite, error) {
+	var s Suite
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid policy test suite: %w", err)
+	}
+	var errs []error
+	for i, c := range s.Cases {
+		if c.Name == "" {
+			s.Cases[i].Name = fmt.Sprintf("case #%d", i+1)
+		}
+		if c.Action == "" || c.Resource == "" {
+			errs = append(errs, fmt.Errorf("%s: action and resource are required", s.Cases[i].Name))
+		}
+		if c.Expect != ExpectAllow && c.Expect != ExpectDeny {
+			errs = append(errs, fmt.Errorf("%s: expect must be %q or %q, got %q", s.Cases[i].Name, ExpectAllow, ExpectDeny, c.Expect))
+		}
+	}
+	return &s, errors.Join(errs...)
+}
+
+// LoadSuite reads a suite from a file.
+func LoadSuite(path string) (*Suite, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSuite(content)
+}
+
+// NewEnforcer creates an in-memory enforcer for m holding policies and group assignments,
+// and nothing else: the rules m was loaded with are not copied. Changes to it are neither
+// persisted nor published.
+func NewEnforcer(m model.Model, policies, groups [][]string) (*casbin.Enforcer, error) {
+	m = m.Copy()
+	m.ClearPolicy()
+	e, err := casbin.NewEnforcer(m)
+	if err != nil {
+		return nil, err
+	}
+	attributes.Register(e)
+	if err := priority.Use(e); err != nil {
+		return nil, err
+	}
+	if len(policies) > 0 {
+		ok, err := e.AddPolicies(policies)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load policies: %w", err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("failed to load policies: duplicate policy")
+		}
+	}
+	if len(groups) > 0 {
+		ok, err := e.AddGroupingPolicies(groups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load group assignments: %w", err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("failed to load group assignments: duplicate group assignment")
+		}
+	}
+	return e, nil
+}
+
+// Run evaluates every case of the suite against e.
+func Run(e *casbin.Enforcer, s *Suite) []Result {
+	results := make([]Result, 0, len(s.Cases))
+	for _, c := range s.Cases {
+		// Attributes are normalized like the checker does for metadata read from NATS KV
+		uuid, _ := c.Player["uuid"].(string)
+		if uuid == "" {
+			uuid, _ = c.Player["player_uuid"].(string)
+		}
+		name, _ := c.Player["name"].(string)
+		serverName, _ := c.Server["name"].(string)
+		player := attributes.Player(uuid, name, c.Player)
+		server := attributes.Server(serverName, c.Server)
+		requestCtx := make(map[string]any, len(c.Context))
+		for k, v := range c.Context {
+			if n, ok := v.(int); ok {
+				v = float64(n) // Expressions compare numbers as float64, as decoded from JSON
+			}
+			requestCtx[k] = v
+		}
+		allowed, matched, err := e.EnforceEx(player, server, c.Action, c.Resource, requestCtx)
+		results = append(results, Result{Case: c, Allowed: allowed, Matched: matched, Err: err})
+	}
+	return results
+}
+
+// Failures returns the results that did not pass.
+func Failures(results []Result) []Result {
+	var failed []Result
+	for _, r := range results {
+		if !r.Passed() {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// RunFiles loads a model, a policy file (see package policyfile) and a suite, and reports
+// every failing case as a test error. It is meant to be called from Go tests.
+func RunFiles(t testing.TB, modelPath, policyPath, suitePath string) {
+	t.Helper()
+
+	m, err := model.NewModelFromFile(modelPath)
+	if err != nil {
+		t.Fatalf("loading model %s: %v", modelPath, err)
+	}
+	content, err := os.ReadFile(policyPath)
+	if err != nil {
+		t.Fatalf("reading policies: %v", err)
+	}
+	f, err := policyfile.Parse(content, "")
+	if err != nil {
+		t.Fatalf("parsing policies %s: %v", policyPath, err)
+	}
+	suite, err := LoadSuite(suitePath)
+	if err != nil {
+		t.Fatalf("loading suite %s: %v", suitePath, err)
+	}
+	e, err := NewEnforcer(m, f.PolicyRules(), f.GroupRules())
+	if err != nil {
+		t.Fatalf("creating enforcer: %v", err)
+	}
+
+	for _, r := range Run(e, suite) {
+		if !r.Passed() {
+			t.Errorf("%s", r)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package policytest
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v2/model"
+)
+
+var (
+	lobbyAllow = []string{"lobby-everyone", "connect", "server:lobby", "true", "true", "allow", "0"}
+	lobbyDeny  = []string{"lobby-closed", "connect", "server:lobby", "true", "true", "deny", "10"}
+)
+
+const lobbySuite = `
+cases:
+  - name: players can join the lobby
+    action: connect
+    resource: server:lobby
+    expect: allow
+`
+
+// liveModel returns the checker's model holding rules, as the model of the running enforcer does.
+func liveModel(t *testing.T, rules ...[]string) model.Model {
+	t.Helper()
+	m, err := model.NewModelFromFile("../model.conf")
+	if err != nil {
+		t.Fatalf("loading model: %v", err)
+	}
+	for _, rule := range rules {
+		m.AddPolicy("p", "p", rule)
+	}
+	return m
+}
+
+func TestNewEnforcerUsesOnlyProposedRules(t *testing.T) {
+	suite, err := ParseSuite([]byte(lobbySuite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		proposed [][]string
+		wantPass bool
+	}{
+		{"unchanged", [][]string{lobbyAllow}, true},
+		{"allow removed", nil, false},
+		{"higher priority deny added", [][]string{lobbyAllow, lobbyDeny}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEnforcer(liveModel(t, lobbyAllow), tt.proposed, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			failed := Failures(Run(e, suite))
+			if passed := len(failed) == 0; passed != tt.wantPass {
+				t.Errorf("suite passed = %v, want %v (%v)", passed, tt.wantPass, failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package priority implements the policy effect of the permissions-checker model,
+// highest_priority(p.effect):
+//
+//   - among the policies matching a request, the one with the highest priority decides;
+//   - at equal priority, deny overrides allow;
+//   - when no policy matches, the request is denied.
+//
+// Priorities are integers (see validation.MinPriority and validation.MaxPriority); a matching
+// policy with an unparsable priority makes the enforcement fail instead of being ignored.
+//
+// The conflict rules are covered by the policy test suite in testdata, run by TestConflicts.
+package priority
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/effector"
+	"github.com/casbin/casbin/v2/model"
+)
+
+// Expr is the model's policy_effect as seen by the effector (dots are escaped by Casbin).
+const Expr = "highest_priority(p_effect)"
+
+// Effector merges the matching policies of a request by priority. Casbin only knows the
+// built-in effect expressions, so the checker's enforcers must use it (see Use).
+type Effector struct {
+	model         func() model.Model
+	priorityIndex int
+	effectIndex   int
+}
+
+// Use installs an Effector on e if its model uses highest_priority(p.effect), and does nothing otherwise.
+func Use(e *casbin.Enforcer) error {
+	m := e.GetModel()
+	if m["e"]["e"] == nil || m["e"]["e"].Value != Expr {
+		return nil
+	}
+	priorityIndex, err := m.GetFieldIndex("p", "priority")
+	if err != nil {
+		return fmt.Errorf("highest_priority effect: %w", err)
+	}
+	effectIndex, err := m.GetFieldIndex("p", "effect")
+	if err != nil {
+		return fmt.Errorf("highest_priority effect: %w", err)
+	}
+	// The enforcer replaces its model when policies are reloaded, so look it up on every decision.
+	e.SetEffector(&Effector{model: e.GetModel, priorityIndex: priorityIndex, effectIndex: effectIndex})
+	return nil
+}
+
+// MergeEffects implements effector.Effector. It waits for every policy to be matched, then
+// returns the decision and the index of the deciding policy (-1 when none matched).
+func (eff *Effector) MergeEffects(expr string, effects []effector.Effect, matches []float64, policyIndex int, policyLength int) (effector.Effect, int, error) {
+	if expr != Expr {
+		return effector.Deny, -1, fmt.Errorf("unsupported effect: %s", expr)
+	}
+	if policyIndex < policyLength-1 {
+		return effector.Indeterminate, -1, nil
+	}
+
+	policies := eff.model()["p"]["p"].Policy
+	decision, decisive, highest := effector.Deny, -1, 0
+	for i := 0; i < policyLength && i < len(matches) && i < len(policies); i++ {
+		if matches[i] == 0 {
+			continue
+		}
+		rule := policies[i]
+		priority, err := strconv.Atoi(rule[eff.priorityIndex])
+		if err != nil {
+			return effector.Deny, -1, fmt.Errorf("policy %s has an invalid priority %q", rule[0], rule[eff.priorityIndex])
+		}
+		var effect effector.Effect
+		switch rule[eff.effectIndex] {
+		case "allow":
+			effect = effector.Allow
+		case "deny":
+			effect = effector.Deny
+		default:
+			continue
+		}
+		if decisive == -1 || priority > highest || (priority == highest && effect == effector.Deny && decision == effector.Allow) {
+			decision, decisive, highest = effect, i, priority
+		}
+	}
+	return decision, decisive, nil
+}
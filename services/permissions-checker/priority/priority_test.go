@@ -0,0 +1,11 @@
+package priority_test
+
+import (
+	"testing"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/policytest"
+)
+
+func TestConflicts(t *testing.T) {
+	policytest.RunFiles(t, "../model.conf", "testdata/conflicts.yaml", "testdata/conflicts_tests.yaml")
+}
@@ -0,0 +1,224 @@
+// Package validation statically checks policies before they are stored, so that a typo in a
+// condition expression is reported to the author instead of surfacing at enforcement time
+// as a silent deny.
+//
+// Condition expressions are evaluated by the model's eval() matcher: they may reference the
+// request (r.player.labels.vip, r.server.name, r.ctx.hour, r.action, r.resource; see package
+// attributes) and the model's functions, e.g. hasLabel(r.server, "server/group", "survival").
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/util"
+	"github.com/casbin/govaluate"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/policyfile"
+)
+
+// Policy fields, named after the PolicyRule message fields.
+const (
+	FieldID              = "id"
+	FieldAction          = "target_action"
+	FieldResource        = "target_resource"
+	FieldPlayerCondition = "player_condition_expression"
+	FieldServerCondition = "server_condition_expression"
+	FieldEffect          = "effect"
+	FieldPriority        = "priority"
+	FieldExpiresAt       = "expires_at"
+)
+
+// Accepted priority range. Higher priorities take precedence.
+const (
+	MinPriority = 0
+	MaxPriority = 10000
+)
+
+// FieldError reports an invalid field of a policy.
+type FieldError struct {
+	PolicyID string
+	Field    string
+	Message  string
+}
+
+func (e FieldError) Error() string {
+	if e.PolicyID == "" {
+		return fmt.Sprintf("%s: %s", e.Field, e.Message)
+	}
+	return fmt.Sprintf("policy %s: %s: %s", e.PolicyID, e.Field, e.Message)
+}
+
+// Errors is the list of problems found in one or more policies.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Err returns e as an error, or nil when there is no problem.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ByField returns the first message for each field, e.g. to display next to form inputs.
+func (e Errors) ByField() map[string]string {
+	fields := make(map[string]string, len(e))
+	for _, fe := range e {
+		if _, ok := fields[fe.Field]; !ok {
+			fields[fe.Field] = fe.Message
+		}
+	}
+	return fields
+}
+
+// indexAccessRegex matches r.player['key'] style accesses, which the expression engine does not support.
+var indexAccessRegex = regexp.MustCompile(`\b(r\.\w+)\s*\[\s*['"]([^'"]*)['"]\s*\]`)
+
+// Validator checks policies against a model.
+type Validator struct {
+	variables map[string]bool // Request and policy tokens, e.g. "r_player"
+	functions map[string]govaluate.ExpressionFunction
+	requests  []string // Request tokens in dotted form, for error messages
+}
+
+// New returns a Validator for the model m. functions are the custom functions registered on
+// the enforcer (in addition to Casbin's built-in ones), which expressions may call.
+func New(m model.Model, functions map[string]govaluate.ExpressionFunction) *Validator {
+	v := &Validator{variables: make(map[string]bool), functions: make(map[string]govaluate.ExpressionFunction)}
+	for _, sec := range []string{"r", "p"} {
+		if ast, ok := m[sec][sec]; ok {
+			for _, token := range ast.Tokens {
+				v.variables[token] = true
+				if sec == "r" {
+					v.requests = append(v.requests, unescape(token))
+				}
+			}
+		}
+	}
+	fm := model.LoadFunctionMap()
+	for name, fn := range fm.GetFunctions() {
+		v.functions[name] = fn
+	}
+	for name, fn := range functions {
+		v.functions[name] = fn
+	}
+	return v
+}
+
+// Policy validates a single policy.
+func (v *Validator) Policy(p policyfile.Policy) Errors {
+	var errs Errors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{PolicyID: p.ID, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(p.ID) == "" {
+		add(FieldID, "is required")
+	} else if strings.ContainsAny(p.ID, " \t\r\n,") {
+		add(FieldID, "must not contain whitespace or commas")
+	}
+	if strings.TrimSpace(p.Action) == "" {
+		add(FieldAction, "is required (use \"*\" to match every action)")
+	}
+	if strings.TrimSpace(p.Resource) == "" {
+		add(FieldResource, "is required (use \"*\" to match every resource)")
+	}
+	if err := v.Expression(p.PlayerCondition); err != nil {
+		add(FieldPlayerCondition, "%v", err)
+	}
+	if err := v.Expression(p.ServerCondition); err != nil {
+		add(FieldServerCondition, "%v", err)
+	}
+	if p.Effect != "allow" && p.Effect != "deny" {
+		add(FieldEffect, "must be \"allow\" or \"deny\", got %q", p.Effect)
+	}
+	if p.Priority < MinPriority || p.Priority > MaxPriority {
+		add(FieldPriority, "must be between %d and %d, got %d", MinPriority, MaxPriority, p.Priority)
+	}
+	return errs
+}
+
+// Policies validates several policies and reports duplicate IDs among them.
+func (v *Validator) Policies(policies []policyfile.Policy) Errors {
+	var errs Errors
+	seen := make(map[string]bool, len(policies))
+	for _, p := range policies {
+		errs = append(errs, v.Policy(p)...)
+		if p.ID == "" {
+			continue
+		}
+		if seen[p.ID] {
+			errs = append(errs, FieldError{PolicyID: p.ID, Field: FieldID, Message: "is used by several policies"})
+		}
+		seen[p.ID] = true
+	}
+	return errs
+}
+
+// Expression checks that expr parses, only references request/policy variables and known
+// functions, and evaluates to a boolean when it is constant.
+func (v *Validator) Expression(expr string) error {
+	if strings.TrimSpace(expr) == "" {
+		return errors.New("is required (use \"true\" to match everything)")
+	}
+	if m := indexAccessRegex.FindStringSubmatch(expr); m != nil {
+		return fmt.Errorf("index access %s is not supported, use %s.%s", m[0], m[1], m[2])
+	}
+
+	parsed, err := govaluate.NewEvaluableExpressionWithFunctions(util.EscapeAssertion(expr), v.functions)
+	if err != nil {
+		return fmt.Errorf("invalid expression: %v", err)
+	}
+
+	constant := true
+	for _, token := range parsed.Tokens() {
+		var name string
+		switch token.Kind {
+		case govaluate.FUNCTION:
+			constant = false
+			continue
+		case govaluate.VARIABLE:
+			name, _ = token.Value.(string)
+		case govaluate.ACCESSOR:
+			if path, ok := token.Value.([]string); ok && len(path) > 0 {
+				name = path[0]
+			}
+		default:
+			continue
+		}
+		if !v.variables[name] {
+			return fmt.Errorf("unknown variable %q (expected one of %s)", unescape(name), strings.Join(v.requests, ", "))
+		}
+		constant = false
+	}
+
+	if constant {
+		result, err := parsed.Evaluate(nil)
+		if err != nil {
+			return fmt.Errorf("cannot be evaluated: %v", err)
+		}
+		if _, ok := result.(bool); !ok {
+			return fmt.Errorf("must evaluate to a boolean, got %v", result)
+		}
+	}
+	return nil
+}
+
+// unescape reverts util.EscapeAssertion for display, e.g. "r_player" to "r.player".
+func unescape(name string) string {
+	if strings.HasPrefix(name, "r_") || strings.HasPrefix(name, "p_") {
+		return strings.Replace(name, "_", ".", 1)
+	}
+	return name
+}
@@ -0,0 +1,85 @@
+// services/permissions-editor/audit_handlers.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bafbi/minecraft-network/services/permissions-checker/audit"
+	"github.com/bafbi/minecraft-network/services/permissions-editor/templates"
+)
+
+// parseAuditQuery reads decision filters from the query string:
+// subject, object, action, result (allow|deny), source, since (duration, e.g. "1h") and limit.
+func parseAuditQuery(r *http.Request) (audit.Query, error) {
+	values := r.URL.Query()
+	q := audit.Query{
+		Subject: values.Get("subject"),
+		Object:  values.Get("object"),
+		Action:  values.Get("action"),
+		Result:  values.Get("result"),
+		Source:  values.Get("source"),
+		Limit:   100,
+	}
+	if v := values.Get("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return q, err
+		}
+		q.Since = time.Now().Add(-d)
+	}
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return q, err
+		}
+		q.Limit = min(max(limit, 1), 1000)
+	}
+	return q, nil
+}
+
+func (s *AppState) queryDecisions(r *http.Request) (audit.Query, []audit.Decision, error) {
+	q, err := parseAuditQuery(r)
+	if err != nil {
+		return q, nil, err
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	decisions, err := audit.QueryDecisions(ctx, s.JS, s.AuditStream, q)
+	return q, decisions, err
+}
+
+// auditPageHandler renders the decision browser, filtered by the query string.
+func (s *AppState) auditPageHandler(w http.ResponseWriter, r *http.Request) {
+	q, decisions, err := s.queryDecisions(r)
+	if err != nil {
+		http.Error(w, "Failed to query decisions: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Filter submissions only replace the results table
+	if r.Header.Get("HX-Target") == "audit-results" {
+		render(w, r, templates.AuditResults(decisions))
+		return
+	}
+	render(w, r, templates.AuditLog(r.URL.Query(), q, decisions))
+}
+
+// auditAPIHandler returns the matching decisions as JSON.
+func (s *AppState) auditAPIHandler(w http.ResponseWriter, r *http.Request) {
+	_, decisions, err := s.queryDecisions(r)
+	if err != nil {
+		http.Error(w, "Failed to query decisions: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if decisions == nil {
+		decisions = []audit.Decision{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(decisions); err != nil {
+		http.Error(w, "Failed to encode decisions: "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	_ "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -23,6 +24,56 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// ImportMode selects how imported rules are merged with the current ones.
+type ImportMode int32
+
+const (
+	ImportMode_IMPORT_MODE_ADD     ImportMode = 0 // Add missing rules; existing policies with the same id are kept
+	ImportMode_IMPORT_MODE_REPLACE ImportMode = 1 // Add missing rules; existing policies with the same id are replaced
+	ImportMode_IMPORT_MODE_PRUNE   ImportMode = 2 // Make the current rules equal to the file
+)
+
+// Enum value maps for ImportMode.
+var (
+	ImportMode_name = map[int32]string{
+		0: "IMPORT_MODE_ADD",
+		1: "IMPORT_MODE_REPLACE",
+		2: "IMPORT_MODE_PRUNE",
+	}
+	ImportMode_value = map[string]int32{
+		"IMPORT_MODE_ADD":     0,
+		"IMPORT_MODE_REPLACE": 1,
+		"IMPORT_MODE_PRUNE":   2,
+	}
+)
+
+func (x ImportMode) Enum() *ImportMode {
+	p := new(ImportMode)
+	*p = x
+	return p
+}
+
+func (x ImportMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImportMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_auth_proto_enumTypes[0].Descriptor()
+}
+
+func (ImportMode) Type() protoreflect.EnumType {
+	return &file_proto_auth_proto_enumTypes[0]
+}
+
+func (x ImportMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImportMode.Descriptor instead.
+func (ImportMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{0}
+}
+
 // AuthRequest represents a permission check request.
 // It now only contains identifiers for the PDP to fetch metadata.
 type AuthRequest struct {
@@ -35,7 +86,10 @@ type AuthRequest struct {
 	// The action being performed (e.g., "connect", "command:/kick", "command:/ban")
 	Action string `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
 	// The resource the action is being performed on (e.g., "server:survival", "command:kick")
-	Resource      string `protobuf:"bytes,5,opt,name=resource,proto3" json:"resource,omitempty"`
+	Resource string `protobuf:"bytes,5,opt,name=resource,proto3" json:"resource,omitempty"`
+	// Optional caller-chosen identifier echoed back in the AuthResponse,
+	// used to correlate responses of batched and streamed checks.
+	RequestId     string `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -105,11 +159,19 @@ func (x *AuthRequest) GetResource() string {
 	return ""
 }
 
+func (x *AuthRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
 // AuthResponse contains the result of a permission check.
 type AuthResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Allowed       bool                   `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"` // Optional message for debugging/reason
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                      // Optional message for debugging/reason
+	RequestId     string                 `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // Copied from AuthRequest.request_id
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -158,23 +220,127 @@ func (x *AuthResponse) GetMessage() string {
 	return ""
 }
 
+func (x *AuthResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+// BatchAuthRequest groups several permission checks evaluated in a single round trip
+// (e.g. every command for tab-completion filtering, every server for a server menu).
+type BatchAuthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      []*AuthRequest         `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchAuthRequest) Reset() {
+	*x = BatchAuthRequest{}
+	mi := &file_proto_auth_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchAuthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchAuthRequest) ProtoMessage() {}
+
+func (x *BatchAuthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchAuthRequest.ProtoReflect.Descriptor instead.
+func (*BatchAuthRequest) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchAuthRequest) GetRequests() []*AuthRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// BatchAuthResponse contains one AuthResponse per request, in request order.
+type BatchAuthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Responses     []*AuthResponse        `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchAuthResponse) Reset() {
+	*x = BatchAuthResponse{}
+	mi := &file_proto_auth_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchAuthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchAuthResponse) ProtoMessage() {}
+
+func (x *BatchAuthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchAuthResponse.ProtoReflect.Descriptor instead.
+func (*BatchAuthResponse) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchAuthResponse) GetResponses() []*AuthResponse {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
 // PolicyRule (remains the same as metadata is pulled by PDP now)
 type PolicyRule struct {
 	state                     protoimpl.MessageState `protogen:"open.v1"`
 	Id                        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	TargetAction              string                 `protobuf:"bytes,2,opt,name=target_action,json=targetAction,proto3" json:"target_action,omitempty"`
 	TargetResource            string                 `protobuf:"bytes,3,opt,name=target_resource,json=targetResource,proto3" json:"target_resource,omitempty"`
-	PlayerConditionExpression string                 `protobuf:"bytes,4,opt,name=player_condition_expression,json=playerConditionExpression,proto3" json:"player_condition_expression,omitempty"` // e.g., "r.player['role'] == 'admin'"
-	ServerConditionExpression string                 `protobuf:"bytes,5,opt,name=server_condition_expression,json=serverConditionExpression,proto3" json:"server_condition_expression,omitempty"` // e.g., "r.server['current_players'] < r.server['max_player']"
+	PlayerConditionExpression string                 `protobuf:"bytes,4,opt,name=player_condition_expression,json=playerConditionExpression,proto3" json:"player_condition_expression,omitempty"` // e.g., "r.player.role == 'admin'"
+	ServerConditionExpression string                 `protobuf:"bytes,5,opt,name=server_condition_expression,json=serverConditionExpression,proto3" json:"server_condition_expression,omitempty"` // e.g., "r.server.current_players < r.server.max_players"
 	Effect                    string                 `protobuf:"bytes,6,opt,name=effect,proto3" json:"effect,omitempty"`                                                                          // "allow" or "deny"
-	Priority                  int32                  `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
-	unknownFields             protoimpl.UnknownFields
-	sizeCache                 protoimpl.SizeCache
+	Priority                  int32                  `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`                                                                     // 0 to 10000
+	// Time after which the rule is removed, for time-bound grants. Unset for permanent rules.
+	// Adding an existing rule again replaces its expiry (unset makes it permanent).
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Set by ListPolicies on a stored rule that cannot be used for decisions, e.g. one with an
+	// invalid priority. Such a rule is listed with a zero priority so that it can be fixed or removed.
+	Error         string `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PolicyRule) Reset() {
 	*x = PolicyRule{}
-	mi := &file_proto_auth_proto_msgTypes[2]
+	mi := &file_proto_auth_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -186,7 +352,7 @@ func (x *PolicyRule) String() string {
 func (*PolicyRule) ProtoMessage() {}
 
 func (x *PolicyRule) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_auth_proto_msgTypes[2]
+	mi := &file_proto_auth_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -199,7 +365,7 @@ func (x *PolicyRule) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PolicyRule.ProtoReflect.Descriptor instead.
 func (*PolicyRule) Descriptor() ([]byte, []int) {
-	return file_proto_auth_proto_rawDescGZIP(), []int{2}
+	return file_proto_auth_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *PolicyRule) GetId() string {
@@ -251,6 +417,81 @@ func (x *PolicyRule) GetPriority() int32 {
 	return 0
 }
 
+func (x *PolicyRule) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *PolicyRule) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// PolicyFieldError reports an invalid field of a PolicyRule, found before the rule is stored.
+type PolicyFieldError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Field         string                 `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"` // PolicyRule field name, e.g. "player_condition_expression"
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PolicyFieldError) Reset() {
+	*x = PolicyFieldError{}
+	mi := &file_proto_auth_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PolicyFieldError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyFieldError) ProtoMessage() {}
+
+func (x *PolicyFieldError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicyFieldError.ProtoReflect.Descriptor instead.
+func (*PolicyFieldError) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PolicyFieldError) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *PolicyFieldError) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *PolicyFieldError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 // PolicyManagementRequest for adding/removing policies
 type PolicyManagementRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -261,7 +502,7 @@ type PolicyManagementRequest struct {
 
 func (x *PolicyManagementRequest) Reset() {
 	*x = PolicyManagementRequest{}
-	mi := &file_proto_auth_proto_msgTypes[3]
+	mi := &file_proto_auth_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -273,7 +514,7 @@ func (x *PolicyManagementRequest) String() string {
 func (*PolicyManagementRequest) ProtoMessage() {}
 
 func (x *PolicyManagementRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_auth_proto_msgTypes[3]
+	mi := &file_proto_auth_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -286,7 +527,7 @@ func (x *PolicyManagementRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PolicyManagementRequest.ProtoReflect.Descriptor instead.
 func (*PolicyManagementRequest) Descriptor() ([]byte, []int) {
-	return file_proto_auth_proto_rawDescGZIP(), []int{3}
+	return file_proto_auth_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *PolicyManagementRequest) GetRules() []*PolicyRule {
@@ -301,13 +542,14 @@ type PolicyManagementResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	FieldErrors   []*PolicyFieldError    `protobuf:"bytes,3,rep,name=field_errors,json=fieldErrors,proto3" json:"field_errors,omitempty"` // Set when the rules failed validation
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PolicyManagementResponse) Reset() {
 	*x = PolicyManagementResponse{}
-	mi := &file_proto_auth_proto_msgTypes[4]
+	mi := &file_proto_auth_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -319,7 +561,7 @@ func (x *PolicyManagementResponse) String() string {
 func (*PolicyManagementResponse) ProtoMessage() {}
 
 func (x *PolicyManagementResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_auth_proto_msgTypes[4]
+	mi := &file_proto_auth_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -332,7 +574,7 @@ func (x *PolicyManagementResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PolicyManagementResponse.ProtoReflect.Descriptor instead.
 func (*PolicyManagementResponse) Descriptor() ([]byte, []int) {
-	return file_proto_auth_proto_rawDescGZIP(), []int{4}
+	return file_proto_auth_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *PolicyManagementResponse) GetSuccess() bool {
@@ -349,42 +591,1002 @@ func (x *PolicyManagementResponse) GetMessage() string {
 	return ""
 }
 
-var File_proto_auth_proto protoreflect.FileDescriptor
+func (x *PolicyManagementResponse) GetFieldErrors() []*PolicyFieldError {
+	if x != nil {
+		return x.FieldErrors
+	}
+	return nil
+}
 
-const file_proto_auth_proto_rawDesc = "" +
-	"\n" +
-	"\x10proto/auth.proto\x12\x04auth\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\"\xa4\x01\n" +
-	"\vAuthRequest\x12\x1f\n" +
-	"\vplayer_uuid\x18\x01 \x01(\tR\n" +
-	"playerUuid\x12\x1f\n" +
-	"\vplayer_name\x18\x02 \x01(\tR\n" +
-	"playerName\x12\x1f\n" +
-	"\vserver_name\x18\x03 \x01(\tR\n" +
-	"serverName\x12\x16\n" +
-	"\x06action\x18\x04 \x01(\tR\x06action\x12\x1a\n" +
-	"\bresource\x18\x05 \x01(\tR\bresource\"B\n" +
-	"\fAuthResponse\x12\x18\n" +
-	"\aallowed\x18\x01 \x01(\bR\aallowed\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\x9e\x02\n" +
-	"\n" +
-	"PolicyRule\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
-	"\rtarget_action\x18\x02 \x01(\tR\ftargetAction\x12'\n" +
-	"\x0ftarget_resource\x18\x03 \x01(\tR\x0etargetResource\x12>\n" +
-	"\x1bplayer_condition_expression\x18\x04 \x01(\tR\x19playerConditionExpression\x12>\n" +
-	"\x1bserver_condition_expression\x18\x05 \x01(\tR\x19serverConditionExpression\x12\x16\n" +
-	"\x06effect\x18\x06 \x01(\tR\x06effect\x12\x1a\n" +
-	"\bpriority\x18\a \x01(\x05R\bpriority\"A\n" +
-	"\x17PolicyManagementRequest\x12&\n" +
-	"\x05rules\x18\x01 \x03(\v2\x10.auth.PolicyRuleR\x05rules\"N\n" +
-	"\x18PolicyManagementResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage2\xa9\x02\n" +
+// PolicyVersion is a recorded snapshot of the policy set after a mutation.
+type PolicyVersion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`                                    // User or client that made the change
+	Operation     string                 `protobuf:"bytes,4,opt,name=operation,proto3" json:"operation,omitempty"`                              // "init", "add", "remove", "rollback", "import" or "expire"
+	Added         []*PolicyRule          `protobuf:"bytes,5,rep,name=added,proto3" json:"added,omitempty"`                                      // Rules added compared to the previous version
+	Removed       []*PolicyRule          `protobuf:"bytes,6,rep,name=removed,proto3" json:"removed,omitempty"`                                  // Rules removed compared to the previous version
+	PolicyCount   int32                  `protobuf:"varint,7,opt,name=policy_count,json=policyCount,proto3" json:"policy_count,omitempty"`      // Number of rules in this version
+	RolledBackTo  uint64                 `protobuf:"varint,8,opt,name=rolled_back_to,json=rolledBackTo,proto3" json:"rolled_back_to,omitempty"` // For "rollback": the version that was restored
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PolicyVersion) Reset() {
+	*x = PolicyVersion{}
+	mi := &file_proto_auth_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PolicyVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyVersion) ProtoMessage() {}
+
+func (x *PolicyVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicyVersion.ProtoReflect.Descriptor instead.
+func (*PolicyVersion) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PolicyVersion) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PolicyVersion) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *PolicyVersion) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *PolicyVersion) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *PolicyVersion) GetAdded() []*PolicyRule {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *PolicyVersion) GetRemoved() []*PolicyRule {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *PolicyVersion) GetPolicyCount() int32 {
+	if x != nil {
+		return x.PolicyCount
+	}
+	return 0
+}
+
+func (x *PolicyVersion) GetRolledBackTo() uint64 {
+	if x != nil {
+		return x.RolledBackTo
+	}
+	return 0
+}
+
+type ListPolicyVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`                                      // Maximum number of versions, newest first (default 50)
+	BeforeVersion uint64                 `protobuf:"varint,2,opt,name=before_version,json=beforeVersion,proto3" json:"before_version,omitempty"` // Only versions older than this one (for paging); 0 for the latest
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPolicyVersionsRequest) Reset() {
+	*x = ListPolicyVersionsRequest{}
+	mi := &file_proto_auth_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPolicyVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPolicyVersionsRequest) ProtoMessage() {}
+
+func (x *ListPolicyVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPolicyVersionsRequest.ProtoReflect.Descriptor instead.
+func (*ListPolicyVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListPolicyVersionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListPolicyVersionsRequest) GetBeforeVersion() uint64 {
+	if x != nil {
+		return x.BeforeVersion
+	}
+	return 0
+}
+
+type ListPolicyVersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*PolicyVersion       `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPolicyVersionsResponse) Reset() {
+	*x = ListPolicyVersionsResponse{}
+	mi := &file_proto_auth_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPolicyVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPolicyVersionsResponse) ProtoMessage() {}
+
+func (x *ListPolicyVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPolicyVersionsResponse.ProtoReflect.Descriptor instead.
+func (*ListPolicyVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListPolicyVersionsResponse) GetVersions() []*PolicyVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type RollbackPolicyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`             // Version to restore
+	DryRun        bool                   `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Only compute the changes, do not apply them
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackPolicyRequest) Reset() {
+	*x = RollbackPolicyRequest{}
+	mi := &file_proto_auth_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackPolicyRequest) ProtoMessage() {}
+
+func (x *RollbackPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackPolicyRequest.ProtoReflect.Descriptor instead.
+func (*RollbackPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RollbackPolicyRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RollbackPolicyRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type RollbackPolicyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Added         []*PolicyRule          `protobuf:"bytes,3,rep,name=added,proto3" json:"added,omitempty"`     // Rules (re-)added by the rollback
+	Removed       []*PolicyRule          `protobuf:"bytes,4,rep,name=removed,proto3" json:"removed,omitempty"` // Rules removed by the rollback
+	Version       *PolicyVersion         `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"` // Version recorded for the rollback (empty for dry runs)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackPolicyResponse) Reset() {
+	*x = RollbackPolicyResponse{}
+	mi := &file_proto_auth_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackPolicyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackPolicyResponse) ProtoMessage() {}
+
+func (x *RollbackPolicyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackPolicyResponse.ProtoReflect.Descriptor instead.
+func (*RollbackPolicyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RollbackPolicyResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RollbackPolicyResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RollbackPolicyResponse) GetAdded() []*PolicyRule {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *RollbackPolicyResponse) GetRemoved() []*PolicyRule {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *RollbackPolicyResponse) GetVersion() *PolicyVersion {
+	if x != nil {
+		return x.Version
+	}
+	return nil
+}
+
+// GroupAssignment is a Casbin grouping rule (g = member, group[, domain]).
+type GroupAssignment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Member        string                 `protobuf:"bytes,1,opt,name=member,proto3" json:"member,omitempty"`
+	Group         string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	Domain        string                 `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupAssignment) Reset() {
+	*x = GroupAssignment{}
+	mi := &file_proto_auth_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupAssignment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupAssignment) ProtoMessage() {}
+
+func (x *GroupAssignment) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupAssignment.ProtoReflect.Descriptor instead.
+func (*GroupAssignment) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GroupAssignment) GetMember() string {
+	if x != nil {
+		return x.Member
+	}
+	return ""
+}
+
+func (x *GroupAssignment) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *GroupAssignment) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type ImportPoliciesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"` // Policy file content
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`   // "yaml" or "csv"; detected from the content when empty
+	Mode          ImportMode             `protobuf:"varint,3,opt,name=mode,proto3,enum=auth.ImportMode" json:"mode,omitempty"`
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Only compute the changes, do not apply them
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportPoliciesRequest) Reset() {
+	*x = ImportPoliciesRequest{}
+	mi := &file_proto_auth_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportPoliciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportPoliciesRequest) ProtoMessage() {}
+
+func (x *ImportPoliciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportPoliciesRequest.ProtoReflect.Descriptor instead.
+func (*ImportPoliciesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ImportPoliciesRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ImportPoliciesRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ImportPoliciesRequest) GetMode() ImportMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ImportMode_IMPORT_MODE_ADD
+}
+
+func (x *ImportPoliciesRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type ImportPoliciesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Added         []*PolicyRule          `protobuf:"bytes,3,rep,name=added,proto3" json:"added,omitempty"`
+	Removed       []*PolicyRule          `protobuf:"bytes,4,rep,name=removed,proto3" json:"removed,omitempty"`
+	AddedGroups   []*GroupAssignment     `protobuf:"bytes,5,rep,name=added_groups,json=addedGroups,proto3" json:"added_groups,omitempty"`
+	RemovedGroups []*GroupAssignment     `protobuf:"bytes,6,rep,name=removed_groups,json=removedGroups,proto3" json:"removed_groups,omitempty"`
+	Conflicts     []string               `protobuf:"bytes,7,rep,name=conflicts,proto3" json:"conflicts,omitempty"`                        // IDs of policies kept because they differ from the file (IMPORT_MODE_ADD)
+	FieldErrors   []*PolicyFieldError    `protobuf:"bytes,8,rep,name=field_errors,json=fieldErrors,proto3" json:"field_errors,omitempty"` // Set when the file's policies failed validation
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportPoliciesResponse) Reset() {
+	*x = ImportPoliciesResponse{}
+	mi := &file_proto_auth_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportPoliciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportPoliciesResponse) ProtoMessage() {}
+
+func (x *ImportPoliciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportPoliciesResponse.ProtoReflect.Descriptor instead.
+func (*ImportPoliciesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ImportPoliciesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ImportPoliciesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ImportPoliciesResponse) GetAdded() []*PolicyRule {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *ImportPoliciesResponse) GetRemoved() []*PolicyRule {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *ImportPoliciesResponse) GetAddedGroups() []*GroupAssignment {
+	if x != nil {
+		return x.AddedGroups
+	}
+	return nil
+}
+
+func (x *ImportPoliciesResponse) GetRemovedGroups() []*GroupAssignment {
+	if x != nil {
+		return x.RemovedGroups
+	}
+	return nil
+}
+
+func (x *ImportPoliciesResponse) GetConflicts() []string {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+func (x *ImportPoliciesResponse) GetFieldErrors() []*PolicyFieldError {
+	if x != nil {
+		return x.FieldErrors
+	}
+	return nil
+}
+
+type ExportPoliciesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"` // "yaml" (default) or "csv"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportPoliciesRequest) Reset() {
+	*x = ExportPoliciesRequest{}
+	mi := &file_proto_auth_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportPoliciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportPoliciesRequest) ProtoMessage() {}
+
+func (x *ExportPoliciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportPoliciesRequest.ProtoReflect.Descriptor instead.
+func (*ExportPoliciesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExportPoliciesRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type ExportPoliciesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportPoliciesResponse) Reset() {
+	*x = ExportPoliciesResponse{}
+	mi := &file_proto_auth_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportPoliciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportPoliciesResponse) ProtoMessage() {}
+
+func (x *ExportPoliciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportPoliciesResponse.ProtoReflect.Descriptor instead.
+func (*ExportPoliciesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ExportPoliciesResponse) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ExportPoliciesResponse) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type ValidatePoliciesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Add           []*PolicyRule          `protobuf:"bytes,1,rep,name=add,proto3" json:"add,omitempty"`       // Rules the change would add
+	Remove        []*PolicyRule          `protobuf:"bytes,2,rep,name=remove,proto3" json:"remove,omitempty"` // Rules the change would remove
+	Suite         []byte                 `protobuf:"bytes,3,opt,name=suite,proto3" json:"suite,omitempty"`   // Policy test suite (YAML); the suite configured on the service when empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidatePoliciesRequest) Reset() {
+	*x = ValidatePoliciesRequest{}
+	mi := &file_proto_auth_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidatePoliciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidatePoliciesRequest) ProtoMessage() {}
+
+func (x *ValidatePoliciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidatePoliciesRequest.ProtoReflect.Descriptor instead.
+func (*ValidatePoliciesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ValidatePoliciesRequest) GetAdd() []*PolicyRule {
+	if x != nil {
+		return x.Add
+	}
+	return nil
+}
+
+func (x *ValidatePoliciesRequest) GetRemove() []*PolicyRule {
+	if x != nil {
+		return x.Remove
+	}
+	return nil
+}
+
+func (x *ValidatePoliciesRequest) GetSuite() []byte {
+	if x != nil {
+		return x.Suite
+	}
+	return nil
+}
+
+// PolicyTestResult is the outcome of a single policy test case.
+type PolicyTestResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Passed        bool                   `protobuf:"varint,2,opt,name=passed,proto3" json:"passed,omitempty"`
+	Expected      string                 `protobuf:"bytes,3,opt,name=expected,proto3" json:"expected,omitempty"` // "allow" or "deny"
+	Actual        string                 `protobuf:"bytes,4,opt,name=actual,proto3" json:"actual,omitempty"`     // "allow" or "deny"
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`       // Enforcement error, if any
+	MatchedPolicy []string               `protobuf:"bytes,6,rep,name=matched_policy,json=matchedPolicy,proto3" json:"matched_policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PolicyTestResult) Reset() {
+	*x = PolicyTestResult{}
+	mi := &file_proto_auth_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PolicyTestResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyTestResult) ProtoMessage() {}
+
+func (x *PolicyTestResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicyTestResult.ProtoReflect.Descriptor instead.
+func (*PolicyTestResult) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PolicyTestResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PolicyTestResult) GetPassed() bool {
+	if x != nil {
+		return x.Passed
+	}
+	return false
+}
+
+func (x *PolicyTestResult) GetExpected() string {
+	if x != nil {
+		return x.Expected
+	}
+	return ""
+}
+
+func (x *PolicyTestResult) GetActual() string {
+	if x != nil {
+		return x.Actual
+	}
+	return ""
+}
+
+func (x *PolicyTestResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PolicyTestResult) GetMatchedPolicy() []string {
+	if x != nil {
+		return x.MatchedPolicy
+	}
+	return nil
+}
+
+type ValidatePoliciesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Passed        bool                   `protobuf:"varint,1,opt,name=passed,proto3" json:"passed,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Results       []*PolicyTestResult    `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	FieldErrors   []*PolicyFieldError    `protobuf:"bytes,4,rep,name=field_errors,json=fieldErrors,proto3" json:"field_errors,omitempty"` // Validation errors of the added rules
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidatePoliciesResponse) Reset() {
+	*x = ValidatePoliciesResponse{}
+	mi := &file_proto_auth_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidatePoliciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidatePoliciesResponse) ProtoMessage() {}
+
+func (x *ValidatePoliciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidatePoliciesResponse.ProtoReflect.Descriptor instead.
+func (*ValidatePoliciesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_auth_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ValidatePoliciesResponse) GetPassed() bool {
+	if x != nil {
+		return x.Passed
+	}
+	return false
+}
+
+func (x *ValidatePoliciesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ValidatePoliciesResponse) GetResults() []*PolicyTestResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *ValidatePoliciesResponse) GetFieldErrors() []*PolicyFieldError {
+	if x != nil {
+		return x.FieldErrors
+	}
+	return nil
+}
+
+var File_proto_auth_proto protoreflect.FileDescriptor
+
+const file_proto_auth_proto_rawDesc = "" +
+	"\n" +
+	"\x10proto/auth.proto\x12\x04auth\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc3\x01\n" +
+	"\vAuthRequest\x12\x1f\n" +
+	"\vplayer_uuid\x18\x01 \x01(\tR\n" +
+	"playerUuid\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12\x1f\n" +
+	"\vserver_name\x18\x03 \x01(\tR\n" +
+	"serverName\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x1a\n" +
+	"\bresource\x18\x05 \x01(\tR\bresource\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x06 \x01(\tR\trequestId\"a\n" +
+	"\fAuthResponse\x12\x18\n" +
+	"\aallowed\x18\x01 \x01(\bR\aallowed\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x03 \x01(\tR\trequestId\"A\n" +
+	"\x10BatchAuthRequest\x12-\n" +
+	"\brequests\x18\x01 \x03(\v2\x11.auth.AuthRequestR\brequests\"E\n" +
+	"\x11BatchAuthResponse\x120\n" +
+	"\tresponses\x18\x01 \x03(\v2\x12.auth.AuthResponseR\tresponses\"\xef\x02\n" +
+	"\n" +
+	"PolicyRule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\rtarget_action\x18\x02 \x01(\tR\ftargetAction\x12'\n" +
+	"\x0ftarget_resource\x18\x03 \x01(\tR\x0etargetResource\x12>\n" +
+	"\x1bplayer_condition_expression\x18\x04 \x01(\tR\x19playerConditionExpression\x12>\n" +
+	"\x1bserver_condition_expression\x18\x05 \x01(\tR\x19serverConditionExpression\x12\x16\n" +
+	"\x06effect\x18\x06 \x01(\tR\x06effect\x12\x1a\n" +
+	"\bpriority\x18\a \x01(\x05R\bpriority\x129\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\"_\n" +
+	"\x10PolicyFieldError\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x12\x14\n" +
+	"\x05field\x18\x02 \x01(\tR\x05field\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"A\n" +
+	"\x17PolicyManagementRequest\x12&\n" +
+	"\x05rules\x18\x01 \x03(\v2\x10.auth.PolicyRuleR\x05rules\"\x89\x01\n" +
+	"\x18PolicyManagementResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x129\n" +
+	"\ffield_errors\x18\x03 \x03(\v2\x16.auth.PolicyFieldErrorR\vfieldErrors\"\xac\x02\n" +
+	"\rPolicyVersion\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x04R\aversion\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x1c\n" +
+	"\toperation\x18\x04 \x01(\tR\toperation\x12&\n" +
+	"\x05added\x18\x05 \x03(\v2\x10.auth.PolicyRuleR\x05added\x12*\n" +
+	"\aremoved\x18\x06 \x03(\v2\x10.auth.PolicyRuleR\aremoved\x12!\n" +
+	"\fpolicy_count\x18\a \x01(\x05R\vpolicyCount\x12$\n" +
+	"\x0erolled_back_to\x18\b \x01(\x04R\frolledBackTo\"X\n" +
+	"\x19ListPolicyVersionsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12%\n" +
+	"\x0ebefore_version\x18\x02 \x01(\x04R\rbeforeVersion\"M\n" +
+	"\x1aListPolicyVersionsResponse\x12/\n" +
+	"\bversions\x18\x01 \x03(\v2\x13.auth.PolicyVersionR\bversions\"J\n" +
+	"\x15RollbackPolicyRequest\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x04R\aversion\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"\xcf\x01\n" +
+	"\x16RollbackPolicyResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12&\n" +
+	"\x05added\x18\x03 \x03(\v2\x10.auth.PolicyRuleR\x05added\x12*\n" +
+	"\aremoved\x18\x04 \x03(\v2\x10.auth.PolicyRuleR\aremoved\x12-\n" +
+	"\aversion\x18\x05 \x01(\v2\x13.auth.PolicyVersionR\aversion\"W\n" +
+	"\x0fGroupAssignment\x12\x16\n" +
+	"\x06member\x18\x01 \x01(\tR\x06member\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x12\x16\n" +
+	"\x06domain\x18\x03 \x01(\tR\x06domain\"\x88\x01\n" +
+	"\x15ImportPoliciesRequest\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\fR\acontent\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12$\n" +
+	"\x04mode\x18\x03 \x01(\x0e2\x10.auth.ImportModeR\x04mode\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"\xf1\x02\n" +
+	"\x16ImportPoliciesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12&\n" +
+	"\x05added\x18\x03 \x03(\v2\x10.auth.PolicyRuleR\x05added\x12*\n" +
+	"\aremoved\x18\x04 \x03(\v2\x10.auth.PolicyRuleR\aremoved\x128\n" +
+	"\fadded_groups\x18\x05 \x03(\v2\x15.auth.GroupAssignmentR\vaddedGroups\x12<\n" +
+	"\x0eremoved_groups\x18\x06 \x03(\v2\x15.auth.GroupAssignmentR\rremovedGroups\x12\x1c\n" +
+	"\tconflicts\x18\a \x03(\tR\tconflicts\x129\n" +
+	"\ffield_errors\x18\b \x03(\v2\x16.auth.PolicyFieldErrorR\vfieldErrors\"/\n" +
+	"\x15ExportPoliciesRequest\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\"J\n" +
+	"\x16ExportPoliciesResponse\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\fR\acontent\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\"}\n" +
+	"\x17ValidatePoliciesRequest\x12\"\n" +
+	"\x03add\x18\x01 \x03(\v2\x10.auth.PolicyRuleR\x03add\x12(\n" +
+	"\x06remove\x18\x02 \x03(\v2\x10.auth.PolicyRuleR\x06remove\x12\x14\n" +
+	"\x05suite\x18\x03 \x01(\fR\x05suite\"\xaf\x01\n" +
+	"\x10PolicyTestResult\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06passed\x18\x02 \x01(\bR\x06passed\x12\x1a\n" +
+	"\bexpected\x18\x03 \x01(\tR\bexpected\x12\x16\n" +
+	"\x06actual\x18\x04 \x01(\tR\x06actual\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12%\n" +
+	"\x0ematched_policy\x18\x06 \x03(\tR\rmatchedPolicy\"\xb9\x01\n" +
+	"\x18ValidatePoliciesResponse\x12\x16\n" +
+	"\x06passed\x18\x01 \x01(\bR\x06passed\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
+	"\aresults\x18\x03 \x03(\v2\x16.auth.PolicyTestResultR\aresults\x129\n" +
+	"\ffield_errors\x18\x04 \x03(\v2\x16.auth.PolicyFieldErrorR\vfieldErrors*Q\n" +
+	"\n" +
+	"ImportMode\x12\x13\n" +
+	"\x0fIMPORT_MODE_ADD\x10\x00\x12\x17\n" +
+	"\x13IMPORT_MODE_REPLACE\x10\x01\x12\x15\n" +
+	"\x11IMPORT_MODE_PRUNE\x10\x022\xc5\x06\n" +
 	"\vAuthService\x128\n" +
-	"\x0fCheckPermission\x12\x11.auth.AuthRequest\x1a\x12.auth.AuthResponse\x12J\n" +
+	"\x0fCheckPermission\x12\x11.auth.AuthRequest\x1a\x12.auth.AuthResponse\x12C\n" +
+	"\x10CheckPermissions\x12\x16.auth.BatchAuthRequest\x1a\x17.auth.BatchAuthResponse\x12B\n" +
+	"\x15CheckPermissionStream\x12\x11.auth.AuthRequest\x1a\x12.auth.AuthResponse(\x010\x01\x12J\n" +
 	"\tAddPolicy\x12\x1d.auth.PolicyManagementRequest\x1a\x1e.auth.PolicyManagementResponse\x12M\n" +
 	"\fRemovePolicy\x12\x1d.auth.PolicyManagementRequest\x1a\x1e.auth.PolicyManagementResponse\x12E\n" +
-	"\fListPolicies\x12\x16.google.protobuf.Empty\x1a\x1d.auth.PolicyManagementRequestB\bZ\x06.;authb\x06proto3"
+	"\fListPolicies\x12\x16.google.protobuf.Empty\x1a\x1d.auth.PolicyManagementRequest\x12W\n" +
+	"\x12ListPolicyVersions\x12\x1f.auth.ListPolicyVersionsRequest\x1a .auth.ListPolicyVersionsResponse\x12K\n" +
+	"\x0eRollbackPolicy\x12\x1b.auth.RollbackPolicyRequest\x1a\x1c.auth.RollbackPolicyResponse\x12K\n" +
+	"\x0eImportPolicies\x12\x1b.auth.ImportPoliciesRequest\x1a\x1c.auth.ImportPoliciesResponse\x12K\n" +
+	"\x0eExportPolicies\x12\x1b.auth.ExportPoliciesRequest\x1a\x1c.auth.ExportPoliciesResponse\x12Q\n" +
+	"\x10ValidatePolicies\x12\x1d.auth.ValidatePoliciesRequest\x1a\x1e.auth.ValidatePoliciesResponseB\bZ\x06.;authb\x06proto3"
 
 var (
 	file_proto_auth_proto_rawDescOnce sync.Once
@@ -398,30 +1600,84 @@ func file_proto_auth_proto_rawDescGZIP() []byte {
 	return file_proto_auth_proto_rawDescData
 }
 
-var file_proto_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_auth_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_proto_auth_proto_goTypes = []any{
-	(*AuthRequest)(nil),              // 0: auth.AuthRequest
-	(*AuthResponse)(nil),             // 1: auth.AuthResponse
-	(*PolicyRule)(nil),               // 2: auth.PolicyRule
-	(*PolicyManagementRequest)(nil),  // 3: auth.PolicyManagementRequest
-	(*PolicyManagementResponse)(nil), // 4: auth.PolicyManagementResponse
-	(*emptypb.Empty)(nil),            // 5: google.protobuf.Empty
+	(ImportMode)(0),                    // 0: auth.ImportMode
+	(*AuthRequest)(nil),                // 1: auth.AuthRequest
+	(*AuthResponse)(nil),               // 2: auth.AuthResponse
+	(*BatchAuthRequest)(nil),           // 3: auth.BatchAuthRequest
+	(*BatchAuthResponse)(nil),          // 4: auth.BatchAuthResponse
+	(*PolicyRule)(nil),                 // 5: auth.PolicyRule
+	(*PolicyFieldError)(nil),           // 6: auth.PolicyFieldError
+	(*PolicyManagementRequest)(nil),    // 7: auth.PolicyManagementRequest
+	(*PolicyManagementResponse)(nil),   // 8: auth.PolicyManagementResponse
+	(*PolicyVersion)(nil),              // 9: auth.PolicyVersion
+	(*ListPolicyVersionsRequest)(nil),  // 10: auth.ListPolicyVersionsRequest
+	(*ListPolicyVersionsResponse)(nil), // 11: auth.ListPolicyVersionsResponse
+	(*RollbackPolicyRequest)(nil),      // 12: auth.RollbackPolicyRequest
+	(*RollbackPolicyResponse)(nil),     // 13: auth.RollbackPolicyResponse
+	(*GroupAssignment)(nil),            // 14: auth.GroupAssignment
+	(*ImportPoliciesRequest)(nil),      // 15: auth.ImportPoliciesRequest
+	(*ImportPoliciesResponse)(nil),     // 16: auth.ImportPoliciesResponse
+	(*ExportPoliciesRequest)(nil),      // 17: auth.ExportPoliciesRequest
+	(*ExportPoliciesResponse)(nil),     // 18: auth.ExportPoliciesResponse
+	(*ValidatePoliciesRequest)(nil),    // 19: auth.ValidatePoliciesRequest
+	(*PolicyTestResult)(nil),           // 20: auth.PolicyTestResult
+	(*ValidatePoliciesResponse)(nil),   // 21: auth.ValidatePoliciesResponse
+	(*timestamppb.Timestamp)(nil),      // 22: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),              // 23: google.protobuf.Empty
 }
 var file_proto_auth_proto_depIdxs = []int32{
-	2, // 0: auth.PolicyManagementRequest.rules:type_name -> auth.PolicyRule
-	0, // 1: auth.AuthService.CheckPermission:input_type -> auth.AuthRequest
-	3, // 2: auth.AuthService.AddPolicy:input_type -> auth.PolicyManagementRequest
-	3, // 3: auth.AuthService.RemovePolicy:input_type -> auth.PolicyManagementRequest
-	5, // 4: auth.AuthService.ListPolicies:input_type -> google.protobuf.Empty
-	1, // 5: auth.AuthService.CheckPermission:output_type -> auth.AuthResponse
-	4, // 6: auth.AuthService.AddPolicy:output_type -> auth.PolicyManagementResponse
-	4, // 7: auth.AuthService.RemovePolicy:output_type -> auth.PolicyManagementResponse
-	3, // 8: auth.AuthService.ListPolicies:output_type -> auth.PolicyManagementRequest
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1,  // 0: auth.BatchAuthRequest.requests:type_name -> auth.AuthRequest
+	2,  // 1: auth.BatchAuthResponse.responses:type_name -> auth.AuthResponse
+	22, // 2: auth.PolicyRule.expires_at:type_name -> google.protobuf.Timestamp
+	5,  // 3: auth.PolicyManagementRequest.rules:type_name -> auth.PolicyRule
+	6,  // 4: auth.PolicyManagementResponse.field_errors:type_name -> auth.PolicyFieldError
+	22, // 5: auth.PolicyVersion.time:type_name -> google.protobuf.Timestamp
+	5,  // 6: auth.PolicyVersion.added:type_name -> auth.PolicyRule
+	5,  // 7: auth.PolicyVersion.removed:type_name -> auth.PolicyRule
+	9,  // 8: auth.ListPolicyVersionsResponse.versions:type_name -> auth.PolicyVersion
+	5,  // 9: auth.RollbackPolicyResponse.added:type_name -> auth.PolicyRule
+	5,  // 10: auth.RollbackPolicyResponse.removed:type_name -> auth.PolicyRule
+	9,  // 11: auth.RollbackPolicyResponse.version:type_name -> auth.PolicyVersion
+	0,  // 12: auth.ImportPoliciesRequest.mode:type_name -> auth.ImportMode
+	5,  // 13: auth.ImportPoliciesResponse.added:type_name -> auth.PolicyRule
+	5,  // 14: auth.ImportPoliciesResponse.removed:type_name -> auth.PolicyRule
+	14, // 15: auth.ImportPoliciesResponse.added_groups:type_name -> auth.GroupAssignment
+	14, // 16: auth.ImportPoliciesResponse.removed_groups:type_name -> auth.GroupAssignment
+	6,  // 17: auth.ImportPoliciesResponse.field_errors:type_name -> auth.PolicyFieldError
+	5,  // 18: auth.ValidatePoliciesRequest.add:type_name -> auth.PolicyRule
+	5,  // 19: auth.ValidatePoliciesRequest.remove:type_name -> auth.PolicyRule
+	20, // 20: auth.ValidatePoliciesResponse.results:type_name -> auth.PolicyTestResult
+	6,  // 21: auth.ValidatePoliciesResponse.field_errors:type_name -> auth.PolicyFieldError
+	1,  // 22: auth.AuthService.CheckPermission:input_type -> auth.AuthRequest
+	3,  // 23: auth.AuthService.CheckPermissions:input_type -> auth.BatchAuthRequest
+	1,  // 24: auth.AuthService.CheckPermissionStream:input_type -> auth.AuthRequest
+	7,  // 25: auth.AuthService.AddPolicy:input_type -> auth.PolicyManagementRequest
+	7,  // 26: auth.AuthService.RemovePolicy:input_type -> auth.PolicyManagementRequest
+	23, // 27: auth.AuthService.ListPolicies:input_type -> google.protobuf.Empty
+	10, // 28: auth.AuthService.ListPolicyVersions:input_type -> auth.ListPolicyVersionsRequest
+	12, // 29: auth.AuthService.RollbackPolicy:input_type -> auth.RollbackPolicyRequest
+	15, // 30: auth.AuthService.ImportPolicies:input_type -> auth.ImportPoliciesRequest
+	17, // 31: auth.AuthService.ExportPolicies:input_type -> auth.ExportPoliciesRequest
+	19, // 32: auth.AuthService.ValidatePolicies:input_type -> auth.ValidatePoliciesRequest
+	2,  // 33: auth.AuthService.CheckPermission:output_type -> auth.AuthResponse
+	4,  // 34: auth.AuthService.CheckPermissions:output_type -> auth.BatchAuthResponse
+	2,  // 35: auth.AuthService.CheckPermissionStream:output_type -> auth.AuthResponse
+	8,  // 36: auth.AuthService.AddPolicy:output_type -> auth.PolicyManagementResponse
+	8,  // 37: auth.AuthService.RemovePolicy:output_type -> auth.PolicyManagementResponse
+	7,  // 38: auth.AuthService.ListPolicies:output_type -> auth.PolicyManagementRequest
+	11, // 39: auth.AuthService.ListPolicyVersions:output_type -> auth.ListPolicyVersionsResponse
+	13, // 40: auth.AuthService.RollbackPolicy:output_type -> auth.RollbackPolicyResponse
+	16, // 41: auth.AuthService.ImportPolicies:output_type -> auth.ImportPoliciesResponse
+	18, // 42: auth.AuthService.ExportPolicies:output_type -> auth.ExportPoliciesResponse
+	21, // 43: auth.AuthService.ValidatePolicies:output_type -> auth.ValidatePoliciesResponse
+	33, // [33:44] is the sub-list for method output_type
+	22, // [22:33] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_proto_auth_proto_init() }
@@ -434,13 +1690,14 @@ func file_proto_auth_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_auth_proto_rawDesc), len(file_proto_auth_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   5,
+			NumEnums:      1,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_auth_proto_goTypes,
 		DependencyIndexes: file_proto_auth_proto_depIdxs,
+		EnumInfos:         file_proto_auth_proto_enumTypes,
 		MessageInfos:      file_proto_auth_proto_msgTypes,
 	}.Build()
 	File_proto_auth_proto = out.File
@@ -20,10 +20,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AuthService_CheckPermission_FullMethodName = "/auth.AuthService/CheckPermission"
-	AuthService_AddPolicy_FullMethodName       = "/auth.AuthService/AddPolicy"
-	AuthService_RemovePolicy_FullMethodName    = "/auth.AuthService/RemovePolicy"
-	AuthService_ListPolicies_FullMethodName    = "/auth.AuthService/ListPolicies"
+	AuthService_CheckPermission_FullMethodName       = "/auth.AuthService/CheckPermission"
+	AuthService_CheckPermissions_FullMethodName      = "/auth.AuthService/CheckPermissions"
+	AuthService_CheckPermissionStream_FullMethodName = "/auth.AuthService/CheckPermissionStream"
+	AuthService_AddPolicy_FullMethodName             = "/auth.AuthService/AddPolicy"
+	AuthService_RemovePolicy_FullMethodName          = "/auth.AuthService/RemovePolicy"
+	AuthService_ListPolicies_FullMethodName          = "/auth.AuthService/ListPolicies"
+	AuthService_ListPolicyVersions_FullMethodName    = "/auth.AuthService/ListPolicyVersions"
+	AuthService_RollbackPolicy_FullMethodName        = "/auth.AuthService/RollbackPolicy"
+	AuthService_ImportPolicies_FullMethodName        = "/auth.AuthService/ImportPolicies"
+	AuthService_ExportPolicies_FullMethodName        = "/auth.AuthService/ExportPolicies"
+	AuthService_ValidatePolicies_FullMethodName      = "/auth.AuthService/ValidatePolicies"
 )
 
 // AuthServiceClient is the client API for AuthService service.
@@ -33,9 +40,25 @@ const (
 // AuthService provides permission checking and policy management.
 type AuthServiceClient interface {
 	CheckPermission(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	CheckPermissions(ctx context.Context, in *BatchAuthRequest, opts ...grpc.CallOption) (*BatchAuthResponse, error)
+	// CheckPermissionStream answers each request on the stream as it arrives.
+	CheckPermissionStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AuthRequest, AuthResponse], error)
 	AddPolicy(ctx context.Context, in *PolicyManagementRequest, opts ...grpc.CallOption) (*PolicyManagementResponse, error)
 	RemovePolicy(ctx context.Context, in *PolicyManagementRequest, opts ...grpc.CallOption) (*PolicyManagementResponse, error)
 	ListPolicies(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PolicyManagementRequest, error)
+	// ListPolicyVersions returns the recorded policy versions, newest first.
+	ListPolicyVersions(ctx context.Context, in *ListPolicyVersionsRequest, opts ...grpc.CallOption) (*ListPolicyVersionsResponse, error)
+	// RollbackPolicy restores the policy set of a previous version, recording a new version.
+	RollbackPolicy(ctx context.Context, in *RollbackPolicyRequest, opts ...grpc.CallOption) (*RollbackPolicyResponse, error)
+	// ImportPolicies merges a declarative policy file into the current rules.
+	ImportPolicies(ctx context.Context, in *ImportPoliciesRequest, opts ...grpc.CallOption) (*ImportPoliciesResponse, error)
+	// ExportPolicies writes the current rules as a declarative policy file.
+	ExportPolicies(ctx context.Context, in *ExportPoliciesRequest, opts ...grpc.CallOption) (*ExportPoliciesResponse, error)
+	// ValidatePolicies checks the added rules and runs a policy test suite against the current
+	// rules with the change applied.
+	// Changes made through AddPolicy, RemovePolicy, ImportPolicies and RollbackPolicy are
+	// rejected when they fail the suite configured on the service.
+	ValidatePolicies(ctx context.Context, in *ValidatePoliciesRequest, opts ...grpc.CallOption) (*ValidatePoliciesResponse, error)
 }
 
 type authServiceClient struct {
@@ -56,6 +79,29 @@ func (c *authServiceClient) CheckPermission(ctx context.Context, in *AuthRequest
 	return out, nil
 }
 
+func (c *authServiceClient) CheckPermissions(ctx context.Context, in *BatchAuthRequest, opts ...grpc.CallOption) (*BatchAuthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchAuthResponse)
+	err := c.cc.Invoke(ctx, AuthService_CheckPermissions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) CheckPermissionStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AuthRequest, AuthResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AuthService_ServiceDesc.Streams[0], AuthService_CheckPermissionStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AuthRequest, AuthResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuthService_CheckPermissionStreamClient = grpc.BidiStreamingClient[AuthRequest, AuthResponse]
+
 func (c *authServiceClient) AddPolicy(ctx context.Context, in *PolicyManagementRequest, opts ...grpc.CallOption) (*PolicyManagementResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PolicyManagementResponse)
@@ -86,6 +132,56 @@ func (c *authServiceClient) ListPolicies(ctx context.Context, in *emptypb.Empty,
 	return out, nil
 }
 
+func (c *authServiceClient) ListPolicyVersions(ctx context.Context, in *ListPolicyVersionsRequest, opts ...grpc.CallOption) (*ListPolicyVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPolicyVersionsResponse)
+	err := c.cc.Invoke(ctx, AuthService_ListPolicyVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) RollbackPolicy(ctx context.Context, in *RollbackPolicyRequest, opts ...grpc.CallOption) (*RollbackPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RollbackPolicyResponse)
+	err := c.cc.Invoke(ctx, AuthService_RollbackPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) ImportPolicies(ctx context.Context, in *ImportPoliciesRequest, opts ...grpc.CallOption) (*ImportPoliciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportPoliciesResponse)
+	err := c.cc.Invoke(ctx, AuthService_ImportPolicies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) ExportPolicies(ctx context.Context, in *ExportPoliciesRequest, opts ...grpc.CallOption) (*ExportPoliciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportPoliciesResponse)
+	err := c.cc.Invoke(ctx, AuthService_ExportPolicies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) ValidatePolicies(ctx context.Context, in *ValidatePoliciesRequest, opts ...grpc.CallOption) (*ValidatePoliciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidatePoliciesResponse)
+	err := c.cc.Invoke(ctx, AuthService_ValidatePolicies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AuthServiceServer is the server API for AuthService service.
 // All implementations must embed UnimplementedAuthServiceServer
 // for forward compatibility.
@@ -93,9 +189,25 @@ func (c *authServiceClient) ListPolicies(ctx context.Context, in *emptypb.Empty,
 // AuthService provides permission checking and policy management.
 type AuthServiceServer interface {
 	CheckPermission(context.Context, *AuthRequest) (*AuthResponse, error)
+	CheckPermissions(context.Context, *BatchAuthRequest) (*BatchAuthResponse, error)
+	// CheckPermissionStream answers each request on the stream as it arrives.
+	CheckPermissionStream(grpc.BidiStreamingServer[AuthRequest, AuthResponse]) error
 	AddPolicy(context.Context, *PolicyManagementRequest) (*PolicyManagementResponse, error)
 	RemovePolicy(context.Context, *PolicyManagementRequest) (*PolicyManagementResponse, error)
 	ListPolicies(context.Context, *emptypb.Empty) (*PolicyManagementRequest, error)
+	// ListPolicyVersions returns the recorded policy versions, newest first.
+	ListPolicyVersions(context.Context, *ListPolicyVersionsRequest) (*ListPolicyVersionsResponse, error)
+	// RollbackPolicy restores the policy set of a previous version, recording a new version.
+	RollbackPolicy(context.Context, *RollbackPolicyRequest) (*RollbackPolicyResponse, error)
+	// ImportPolicies merges a declarative policy file into the current rules.
+	ImportPolicies(context.Context, *ImportPoliciesRequest) (*ImportPoliciesResponse, error)
+	// ExportPolicies writes the current rules as a declarative policy file.
+	ExportPolicies(context.Context, *ExportPoliciesRequest) (*ExportPoliciesResponse, error)
+	// ValidatePolicies checks the added rules and runs a policy test suite against the current
+	// rules with the change applied.
+	// Changes made through AddPolicy, RemovePolicy, ImportPolicies and RollbackPolicy are
+	// rejected when they fail the suite configured on the service.
+	ValidatePolicies(context.Context, *ValidatePoliciesRequest) (*ValidatePoliciesResponse, error)
 	mustEmbedUnimplementedAuthServiceServer()
 }
 
@@ -109,6 +221,12 @@ type UnimplementedAuthServiceServer struct{}
 func (UnimplementedAuthServiceServer) CheckPermission(context.Context, *AuthRequest) (*AuthResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckPermission not implemented")
 }
+func (UnimplementedAuthServiceServer) CheckPermissions(context.Context, *BatchAuthRequest) (*BatchAuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckPermissions not implemented")
+}
+func (UnimplementedAuthServiceServer) CheckPermissionStream(grpc.BidiStreamingServer[AuthRequest, AuthResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method CheckPermissionStream not implemented")
+}
 func (UnimplementedAuthServiceServer) AddPolicy(context.Context, *PolicyManagementRequest) (*PolicyManagementResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddPolicy not implemented")
 }
@@ -118,6 +236,21 @@ func (UnimplementedAuthServiceServer) RemovePolicy(context.Context, *PolicyManag
 func (UnimplementedAuthServiceServer) ListPolicies(context.Context, *emptypb.Empty) (*PolicyManagementRequest, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListPolicies not implemented")
 }
+func (UnimplementedAuthServiceServer) ListPolicyVersions(context.Context, *ListPolicyVersionsRequest) (*ListPolicyVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPolicyVersions not implemented")
+}
+func (UnimplementedAuthServiceServer) RollbackPolicy(context.Context, *RollbackPolicyRequest) (*RollbackPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackPolicy not implemented")
+}
+func (UnimplementedAuthServiceServer) ImportPolicies(context.Context, *ImportPoliciesRequest) (*ImportPoliciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportPolicies not implemented")
+}
+func (UnimplementedAuthServiceServer) ExportPolicies(context.Context, *ExportPoliciesRequest) (*ExportPoliciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportPolicies not implemented")
+}
+func (UnimplementedAuthServiceServer) ValidatePolicies(context.Context, *ValidatePoliciesRequest) (*ValidatePoliciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidatePolicies not implemented")
+}
 func (UnimplementedAuthServiceServer) mustEmbedUnimplementedAuthServiceServer() {}
 func (UnimplementedAuthServiceServer) testEmbeddedByValue()                     {}
 
@@ -157,6 +290,31 @@ func _AuthService_CheckPermission_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AuthService_CheckPermissions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchAuthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).CheckPermissions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_CheckPermissions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).CheckPermissions(ctx, req.(*BatchAuthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_CheckPermissionStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AuthServiceServer).CheckPermissionStream(&grpc.GenericServerStream[AuthRequest, AuthResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuthService_CheckPermissionStreamServer = grpc.BidiStreamingServer[AuthRequest, AuthResponse]
+
 func _AuthService_AddPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PolicyManagementRequest)
 	if err := dec(in); err != nil {
@@ -211,6 +369,96 @@ func _AuthService_ListPolicies_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AuthService_ListPolicyVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPolicyVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ListPolicyVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_ListPolicyVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ListPolicyVersions(ctx, req.(*ListPolicyVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_RollbackPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).RollbackPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_RollbackPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).RollbackPolicy(ctx, req.(*RollbackPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_ImportPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportPoliciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ImportPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_ImportPolicies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ImportPolicies(ctx, req.(*ImportPoliciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_ExportPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportPoliciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ExportPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_ExportPolicies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ExportPolicies(ctx, req.(*ExportPoliciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_ValidatePolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidatePoliciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ValidatePolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_ValidatePolicies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ValidatePolicies(ctx, req.(*ValidatePoliciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AuthService_ServiceDesc is the grpc.ServiceDesc for AuthService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -222,6 +470,10 @@ var AuthService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CheckPermission",
 			Handler:    _AuthService_CheckPermission_Handler,
 		},
+		{
+			MethodName: "CheckPermissions",
+			Handler:    _AuthService_CheckPermissions_Handler,
+		},
 		{
 			MethodName: "AddPolicy",
 			Handler:    _AuthService_AddPolicy_Handler,
@@ -234,7 +486,34 @@ var AuthService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListPolicies",
 			Handler:    _AuthService_ListPolicies_Handler,
 		},
+		{
+			MethodName: "ListPolicyVersions",
+			Handler:    _AuthService_ListPolicyVersions_Handler,
+		},
+		{
+			MethodName: "RollbackPolicy",
+			Handler:    _AuthService_RollbackPolicy_Handler,
+		},
+		{
+			MethodName: "ImportPolicies",
+			Handler:    _AuthService_ImportPolicies_Handler,
+		},
+		{
+			MethodName: "ExportPolicies",
+			Handler:    _AuthService_ExportPolicies_Handler,
+		},
+		{
+			MethodName: "ValidatePolicies",
+			Handler:    _AuthService_ValidatePolicies_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CheckPermissionStream",
+			Handler:       _AuthService_CheckPermissionStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/auth.proto",
 }
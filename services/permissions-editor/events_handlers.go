@@ -0,0 +1,120 @@
+// services/permissions-editor/events_handlers.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/bafbi/minecraft-network/services/permissions-editor/live"
+	"github.com/bafbi/minecraft-network/services/permissions-editor/metadata"
+	"github.com/bafbi/minecraft-network/services/permissions-editor/templates"
+)
+
+// syncTimeout bounds the wait for the initial metadata when the editor has just started.
+const syncTimeout = 5 * time.Second
+
+// waitForMetadata waits for the live metadata to be loaded, writing the error response when it
+// is not in time.
+func (s *AppState) waitForMetadata(w http.ResponseWriter, r *http.Request) bool {
+	ctx, cancel := context.WithTimeout(r.Context(), syncTimeout)
+	defer cancel()
+	if err := s.Live.WaitSynced(ctx); err != nil {
+		http.Error(w, "Metadata is still loading from NATS KV, try again shortly", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// playerEventsHandler streams the rows of the players list that change, and tells the list to
+// reload when players are added or removed.
+func (s *AppState) playerEventsHandler(w http.ResponseWriter, r *http.Request) {
+	s.streamChanges(w, r, func(c live.Change) bool { return c.Kind == metadata.Player },
+		func(c live.Change) (string, templ.Component) {
+			if o, ok := s.Live.Object(c.Kind, c.Name); ok && !c.Created && !c.Deleted {
+				return "player", templates.PlayerRow(o, "true")
+			}
+			return "players-changed", nil
+		})
+}
+
+// serverEventsHandler streams the rows of the servers list that change, and tells the list to
+// reload when servers are added or removed.
+func (s *AppState) serverEventsHandler(w http.ResponseWriter, r *http.Request) {
+	s.streamChanges(w, r, func(c live.Change) bool { return c.Kind == metadata.Server },
+		func(c live.Change) (string, templ.Component) {
+			if o, ok := s.Live.Object(c.Kind, c.Name); ok && !c.Created && !c.Deleted {
+				return "server", templates.ServerRow(o, "true")
+			}
+			return "servers-changed", nil
+		})
+}
+
+func (s *AppState) playerMetadataEventsHandler(w http.ResponseWriter, r *http.Request) {
+	s.metadataEvents(w, r, metadata.Player, chi.URLParam(r, "uuid"))
+}
+
+func (s *AppState) serverMetadataEventsHandler(w http.ResponseWriter, r *http.Request) {
+	s.metadataEvents(w, r, metadata.Server, chi.URLParam(r, "name"))
+}
+
+// metadataEvents streams changes of the metadata of the object name of kind made after the
+// revision shown by the editor.
+func (s *AppState) metadataEvents(w http.ResponseWriter, r *http.Request, kind metadata.Kind, name string) {
+	revision, _ := strconv.ParseUint(r.URL.Query().Get("revision"), 10, 64)
+	s.streamChanges(w, r,
+		func(c live.Change) bool {
+			return c.Kind == kind && c.Name == name && (c.Deleted || c.Revision > revision)
+		},
+		func(c live.Change) (string, templ.Component) {
+			return "changed", templates.MetadataChanged(metadata.Editor{Kind: kind, Name: name, Doc: c.Doc}, c.Deleted)
+		})
+}
+
+// streamChanges sends the changes matching filter as the events returned by event, until the
+// client goes away. Events without a component carry no data.
+func (s *AppState) streamChanges(w http.ResponseWriter, r *http.Request, filter func(live.Change) bool, event func(live.Change) (string, templ.Component)) {
+	changes, cancel := s.Live.Subscribe(filter)
+	defer cancel()
+
+	stream, err := live.NewStream(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	keepAlive := time.NewTicker(live.KeepAliveInterval)
+	defer keepAlive.Stop()
+
+	var buf bytes.Buffer
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if err := stream.KeepAlive(); err != nil {
+				return
+			}
+		case change, ok := <-changes:
+			if !ok {
+				return // Fell behind, the browser reconnects
+			}
+			name, component := event(change)
+			buf.Reset()
+			if component != nil {
+				if err := component.Render(r.Context(), &buf); err != nil {
+					slog.ErrorContext(r.Context(), "Failed to render event", "event", name, "error", err)
+					continue
+				}
+			}
+			if err := stream.Send(name, buf.String()); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -2,12 +2,15 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +22,8 @@ import (
 	// IMPORTANT: Corrected module name
 	authpb "github.com/bafbi/minecraft-network/services/permissions-checker/auth"
 	// IMPORTANT: Corrected module name
+	"github.com/bafbi/minecraft-network/services/permissions-editor/live"
+	"github.com/bafbi/minecraft-network/services/permissions-editor/metadata"
 	"github.com/bafbi/minecraft-network/services/permissions-editor/templates"
 )
 
@@ -37,18 +42,16 @@ func readJSONBody(r *http.Request, target interface{}) error {
 }
 
 func (s *AppState) listPlayersHandler(w http.ResponseWriter, r *http.Request) {
-	keys, err := s.NATSKV.Keys(s.PlayerMetadataPrefix + ">")
-	if err != nil {
-		http.Error(w, "Failed to list player keys: "+err.Error(), http.StatusInternalServerError)
+	if !s.waitForMetadata(w, r) {
 		return
 	}
-
-	playerUUIDs := make([]string, 0, len(keys))
-	for _, key := range keys {
-		playerUUIDs = append(playerUUIDs, strings.TrimPrefix(key, s.PlayerMetadataPrefix))
+	results, searchErr := s.searchMetadata(r, metadata.Player)
+	// Searches, sorting and paging only replace the results
+	if r.Header.Get("HX-Target") == "player-results" {
+		render(w, r, templates.PlayerResults(results, searchErr))
+		return
 	}
-
-	render(w, r, templates.PlayersList(playerUUIDs))
+	render(w, r, templates.PlayersList(results, searchErr))
 }
 
 func (s *AppState) getPlayerDetailHandler(w http.ResponseWriter, r *http.Request) {
@@ -58,23 +61,20 @@ func (s *AppState) getPlayerDetailHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	entry, err := s.NATSKV.Get(s.PlayerMetadataPrefix + uuid)
-	if err != nil {
-		if err == nats.ErrKeyNotFound {
-			http.Error(w, "Player not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to get player metadata: "+err.Error(), http.StatusInternalServerError)
-		}
+	editor, ok := s.loadMetadataEditor(w, metadata.Player, s.PlayerMetadataPrefix, uuid)
+	if !ok {
 		return
 	}
+	render(w, r, templates.PlayerDetail(editor))
+}
 
-	var metadata map[string]interface{}
-	if err := json.Unmarshal(entry.Value(), &metadata); err != nil {
-		http.Error(w, "Failed to unmarshal player metadata: "+err.Error(), http.StatusInternalServerError)
+// getPlayerMetadataHandler renders the metadata editor alone, when reloading it.
+func (s *AppState) getPlayerMetadataHandler(w http.ResponseWriter, r *http.Request) {
+	editor, ok := s.loadMetadataEditor(w, metadata.Player, s.PlayerMetadataPrefix, chi.URLParam(r, "uuid"))
+	if !ok {
 		return
 	}
-
-	render(w, r, templates.PlayerDetail(uuid, metadata))
+	render(w, r, templates.MetadataEditor(editor))
 }
 
 func (s *AppState) updatePlayerMetadataHandler(w http.ResponseWriter, r *http.Request) {
@@ -84,64 +84,29 @@ func (s *AppState) updatePlayerMetadataHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	r.ParseForm()
-	updatedMeta := make(map[string]interface{})
-	metadataInput := r.FormValue("metadata_input")
-
-	lines := strings.Split(metadataInput, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			valStr := strings.TrimSpace(parts[1])
-
-			if b, err := parseBool(valStr); err == nil {
-				updatedMeta[key] = b
-			} else if i, err := parseInt(valStr); err == nil {
-				updatedMeta[key] = i
-			} else if f, err := parseFloat(valStr); err == nil {
-				updatedMeta[key] = f
-			} else if strings.Contains(valStr, ",") {
-				updatedMeta[key] = strings.Split(valStr, ",")
-			} else {
-				updatedMeta[key] = valStr
-			}
-		} else {
-			log.Printf("Skipping malformed metadata line: %s", line)
-		}
-	}
+	s.saveMetadata(w, r, metadata.Player, s.PlayerMetadataPrefix, uuid)
+}
 
-	updatedBytes, err := json.Marshal(updatedMeta)
-	if err != nil {
-		http.Error(w, "Failed to marshal updated metadata: "+err.Error(), http.StatusInternalServerError)
+func (s *AppState) listServersHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.waitForMetadata(w, r) {
 		return
 	}
-
-	if _, err := s.NATSKV.Put(context.Background(), s.PlayerMetadataPrefix+uuid, updatedBytes); err != nil {
-		http.Error(w, "Failed to update player metadata in NATS KV: "+err.Error(), http.StatusInternalServerError)
+	results, searchErr := s.searchMetadata(r, metadata.Server)
+	if r.Header.Get("HX-Target") == "server-results" {
+		render(w, r, templates.ServerResults(results, searchErr))
 		return
 	}
-
-	http.Redirect(w, r, fmt.Sprintf("/player/%s", uuid), http.StatusSeeOther)
+	render(w, r, templates.ServersList(results, searchErr))
 }
 
-func (s *AppState) listServersHandler(w http.ResponseWriter, r *http.Request) {
-	keys, err := s.NATSKV.Keys(s.ServerMetadataPrefix + ">")
+// searchMetadata returns the page of objects of kind matching the query string, or why the query
+// is not valid.
+func (s *AppState) searchMetadata(r *http.Request, kind metadata.Kind) (live.Result, string) {
+	q, err := live.ParseQuery(kind, r.URL.Query())
 	if err != nil {
-		http.Error(w, "Failed to list server keys: "+err.Error(), http.StatusInternalServerError)
-		return
+		return live.Result{Query: q}, err.Error()
 	}
-
-	serverNames := make([]string, 0, len(keys))
-	for _, key := range keys {
-		serverNames = append(serverNames, strings.TrimPrefix(key, s.ServerMetadataPrefix))
-	}
-
-	render(w, r, templates.ServersList(serverNames))
+	return s.Live.Search(q), ""
 }
 
 func (s *AppState) getServerDetailHandler(w http.ResponseWriter, r *http.Request) {
@@ -151,23 +116,20 @@ func (s *AppState) getServerDetailHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	entry, err := s.NATSKV.Get(s.ServerMetadataPrefix + name)
-	if err != nil {
-		if err == nats.ErrKeyNotFound {
-			http.Error(w, "Server not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to get server metadata: "+err.Error(), http.StatusInternalServerError)
-		}
+	editor, ok := s.loadMetadataEditor(w, metadata.Server, s.ServerMetadataPrefix, name)
+	if !ok {
 		return
 	}
+	render(w, r, templates.ServerDetail(editor))
+}
 
-	var metadata map[string]interface{}
-	if err := json.Unmarshal(entry.Value(), &metadata); err != nil {
-		http.Error(w, "Failed to unmarshal server metadata: "+err.Error(), http.StatusInternalServerError)
+// getServerMetadataHandler renders the metadata editor alone, when reloading it.
+func (s *AppState) getServerMetadataHandler(w http.ResponseWriter, r *http.Request) {
+	editor, ok := s.loadMetadataEditor(w, metadata.Server, s.ServerMetadataPrefix, chi.URLParam(r, "name"))
+	if !ok {
 		return
 	}
-
-	render(w, r, templates.ServerDetail(name, metadata))
+	render(w, r, templates.MetadataEditor(editor))
 }
 
 func (s *AppState) updateServerMetadataHandler(w http.ResponseWriter, r *http.Request) {
@@ -177,49 +139,90 @@ func (s *AppState) updateServerMetadataHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	r.ParseForm()
-	updatedMeta := make(map[string]interface{})
-	metadataInput := r.FormValue("metadata_input")
-
-	lines := strings.Split(metadataInput, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			valStr := strings.TrimSpace(parts[1])
-
-			if b, err := parseBool(valStr); err == nil {
-				updatedMeta[key] = b
-			} else if i, err := parseInt(valStr); err == nil {
-				updatedMeta[key] = i
-			} else if f, err := parseFloat(valStr); err == nil {
-				updatedMeta[key] = f
-			} else if strings.Contains(valStr, ",") {
-				updatedMeta[key] = strings.Split(valStr, ",")
-			} else {
-				updatedMeta[key] = valStr
-			}
+	s.saveMetadata(w, r, metadata.Server, s.ServerMetadataPrefix, name)
+}
+
+// loadMetadataEditor reads the metadata of the object name of kind, writing the error response
+// when it cannot.
+func (s *AppState) loadMetadataEditor(w http.ResponseWriter, kind metadata.Kind, prefix, name string) (metadata.Editor, bool) {
+	entry, err := s.NATSKV.Get(prefix + name)
+	if err != nil {
+		if err == nats.ErrKeyNotFound {
+			http.Error(w, fmt.Sprintf("%s%s not found", strings.ToUpper(string(kind[:1])), kind[1:]), http.StatusNotFound)
 		} else {
-			log.Printf("Skipping malformed metadata line: %s", line)
+			http.Error(w, fmt.Sprintf("Failed to get %s metadata: %s", kind, err.Error()), http.StatusInternalServerError)
 		}
+		return metadata.Editor{}, false
+	}
+
+	doc, err := metadata.Parse(entry.Value(), entry.Revision())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to parse %s metadata: %s", kind, err.Error()), http.StatusInternalServerError)
+		return metadata.Editor{}, false
+	}
+	return metadata.Editor{Kind: kind, Name: name, Doc: doc, Stored: indentJSON(entry.Value())}, true
+}
+
+// saveMetadata applies the submitted editor form to the metadata of the object name of kind.
+// The form is only saved if the metadata has not changed since it was loaded: the proxies update
+// it as players move around, and another user may be editing it as well.
+func (s *AppState) saveMetadata(w http.ResponseWriter, r *http.Request, kind metadata.Kind, prefix, name string) {
+	r.ParseForm()
+	revision, err := strconv.ParseUint(r.FormValue(metadata.RevisionField), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid metadata revision: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	editor, ok := s.loadMetadataEditor(w, kind, prefix, name)
+	if !ok {
+		return
+	}
+	if editor.Doc.Revision != revision {
+		editor.Conflict = true
+		render(w, r, templates.MetadataEditor(editor))
+		return
 	}
 
-	updatedBytes, err := json.Marshal(updatedMeta)
+	edited, errs := editor.Doc.Edited(kind, r.Form)
+	if len(errs) > 0 {
+		editor.Doc, editor.Errors = edited, errs
+		render(w, r, templates.MetadataEditor(editor))
+		return
+	}
+	data, err := edited.Marshal()
 	if err != nil {
-		http.Error(w, "Failed to marshal updated metadata: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to marshal %s metadata: %s", kind, err.Error()), http.StatusInternalServerError)
 		return
 	}
 
-	if _, err := s.NATSKV.Put(s.ServerMetadataPrefix+name, updatedBytes); err != nil {
-		http.Error(w, "Failed to update server metadata in NATS KV: "+err.Error(), http.StatusInternalServerError)
+	newRevision, err := s.NATSKV.Update(prefix+name, data, revision)
+	var apiErr *nats.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode == nats.JSErrCodeStreamWrongLastSequence {
+		// Changed between reading and writing it
+		if editor, ok = s.loadMetadataEditor(w, kind, prefix, name); ok {
+			editor.Conflict = true
+			render(w, r, templates.MetadataEditor(editor))
+		}
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update %s metadata in NATS KV: %s", kind, err.Error()), http.StatusInternalServerError)
 		return
 	}
+	slog.InfoContext(r.Context(), "Updated metadata", "kind", kind, "name", name, "revision", newRevision)
 
-	http.Redirect(w, r, fmt.Sprintf("/server/%s", name), http.StatusSeeOther)
+	edited.Revision = newRevision
+	render(w, r, templates.MetadataEditor(metadata.Editor{Kind: kind, Name: name, Doc: edited, Stored: indentJSON(data), Saved: true}))
+}
+
+// indentJSON formats a stored JSON document for display.
+func indentJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return string(data)
+	}
+	return buf.String()
 }
 
 func (s *AppState) listPoliciesHandler(w http.ResponseWriter, r *http.Request) {
@@ -238,12 +241,6 @@ func (s *AppState) listPoliciesHandler(w http.ResponseWriter, r *http.Request) {
 func (s *AppState) addPolicyHandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
 
-	priority, err := parseInt(r.FormValue("priority"))
-	if err != nil {
-		http.Error(w, "Invalid priority: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
 	newPolicy := &authpb.PolicyRule{
 		Id:                        r.FormValue("id"),
 		TargetAction:              r.FormValue("targetAction"),
@@ -251,23 +248,40 @@ func (s *AppState) addPolicyHandler(w http.ResponseWriter, r *http.Request) {
 		PlayerConditionExpression: r.FormValue("playerConditionExpression"),
 		ServerConditionExpression: r.FormValue("serverConditionExpression"),
 		Effect:                    r.FormValue("effect"),
-		Priority:                  int32(priority),
 	}
 
-	if newPolicy.Id == "" || newPolicy.TargetAction == "" || newPolicy.TargetResource == "" || newPolicy.Effect == "" {
-		http.Error(w, "All fields (ID, Target Action, Target Resource, Effect) are required.", http.StatusBadRequest)
+	priority, err := parseInt(r.FormValue("priority"))
+	if err != nil {
+		renderPolicyFormErrors(w, r, newPolicy, map[string]string{"priority": "must be a whole number"})
+		return
+	}
+	newPolicy.Priority = int32(priority)
+	if newPolicy.ExpiresAt, err = templates.ParsePolicyExpiresAt(r.FormValue("expiresAt")); err != nil {
+		renderPolicyFormErrors(w, r, newPolicy, map[string]string{"expires_at": "must be a date and time"})
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
+	// The checker validates the rule against its model and reports errors per field
 	resp, err := s.AuthClient.AddPolicy(ctx, &authpb.PolicyManagementRequest{Rules: []*authpb.PolicyRule{newPolicy}})
 	if err != nil {
 		http.Error(w, "Failed to add policy via gRPC: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if fieldErrors := resp.GetFieldErrors(); len(fieldErrors) > 0 {
+		errs := make(map[string]string, len(fieldErrors))
+		for _, fe := range fieldErrors {
+			if _, ok := errs[fe.GetField()]; !ok {
+				errs[fe.GetField()] = fe.GetMessage()
+			}
+		}
+		renderPolicyFormErrors(w, r, newPolicy, errs)
+		return
+	}
+
 	if !resp.GetSuccess() {
 		http.Error(w, "Failed to add policy: "+resp.GetMessage(), http.StatusInternalServerError)
 		return
@@ -276,6 +290,14 @@ func (s *AppState) addPolicyHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/policies", http.StatusSeeOther)
 }
 
+// renderPolicyFormErrors re-renders the policy form in place with the submitted values and
+// the validation errors next to their fields.
+func renderPolicyFormErrors(w http.ResponseWriter, r *http.Request, policy *authpb.PolicyRule, errs map[string]string) {
+	w.Header().Set("HX-Retarget", "#policy-form")
+	w.Header().Set("HX-Reswap", "outerHTML")
+	render(w, r, templates.PolicyForm(policy, errs))
+}
+
 func (s *AppState) deletePolicyHandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
 
@@ -312,14 +334,45 @@ func (s *AppState) deletePolicyHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func parseBool(s string) (bool, error) {
-	if s == "true" {
-		return true, nil
+func (s *AppState) policyHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := s.AuthClient.ListPolicyVersions(ctx, &authpb.ListPolicyVersionsRequest{Limit: 50})
+	if err != nil {
+		http.Error(w, "Failed to list policy versions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, templates.PolicyHistory(resp.GetVersions()))
+}
+
+func (s *AppState) rollbackPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.ParseUint(chi.URLParam(r, "version"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid version: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	dryRun := r.Method == http.MethodGet
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := s.AuthClient.RollbackPolicy(ctx, &authpb.RollbackPolicyRequest{Version: version, DryRun: dryRun})
+	if err != nil {
+		http.Error(w, "Failed to roll back policies via gRPC: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
-	if s == "false" {
-		return false, nil
+	if !resp.GetSuccess() {
+		http.Error(w, "Failed to roll back policies: "+resp.GetMessage(), http.StatusInternalServerError)
+		return
+	}
+
+	if dryRun {
+		render(w, r, templates.RollbackPreview(version, resp))
+		return
 	}
-	return false, fmt.Errorf("invalid boolean: %s", s)
+	s.policyHistoryHandler(w, r)
 }
 
 func parseInt(s string) (int, error) {
@@ -327,9 +380,3 @@ func parseInt(s string) (int, error) {
 	_, err := fmt.Sscanf(s, "%d", &i)
 	return i, err
 }
-
-func parseFloat(s string) (float64, error) {
-	var f float64
-	_, err := fmt.Sscanf(s, "%f", &f)
-	return f, err
-}
@@ -0,0 +1,275 @@
+package live
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bafbi/minecraft-network/services/permissions-editor/metadata"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// Sort columns of the players and servers lists, the first being the default.
+var sortColumns = map[metadata.Kind][]string{
+	metadata.Player: {"name", "uuid", "status", "server"},
+	metadata.Server: {"name", "type", "players"},
+}
+
+// Query searches the players or servers.
+type Query struct {
+	Kind     metadata.Kind
+	Search   string // Player name or UUID prefix, server name
+	Selector string // Label selector, see metadata.ParseSelector
+	Status   string // Players: "online", "offline" or any
+	Server   string // Players: the server they are connected to
+	Sort     string
+	Desc     bool
+	Page     int // From 1
+	PageSize int
+
+	selector metadata.Selector
+}
+
+// ParseQuery reads a query for kind from the query string: q, selector, status, server, sort,
+// desc, page and size.
+func ParseQuery(kind metadata.Kind, values url.Values) (Query, error) {
+	q := Query{
+		Kind:     kind,
+		Search:   strings.TrimSpace(values.Get("q")),
+		Selector: strings.TrimSpace(values.Get("selector")),
+		Status:   values.Get("status"),
+		Server:   strings.TrimSpace(values.Get("server")),
+		Sort:     values.Get("sort"),
+		Desc:     values.Get("desc") == "true",
+		Page:     1,
+		PageSize: DefaultPageSize,
+	}
+	var err error
+	if q.selector, err = metadata.ParseSelector(q.Selector); err != nil {
+		return q, err
+	}
+	switch q.Status {
+	case "", "online", "offline":
+	default:
+		return q, fmt.Errorf("invalid status %q, expected online or offline", q.Status)
+	}
+	if q.Sort == "" {
+		q.Sort = sortColumns[kind][0]
+	} else if !q.Sortable(q.Sort) {
+		return q, fmt.Errorf("cannot sort by %q", q.Sort)
+	}
+	if v := values.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil {
+			return q, fmt.Errorf("invalid page: %w", err)
+		}
+		q.Page = max(page, 1)
+	}
+	if v := values.Get("size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return q, fmt.Errorf("invalid page size: %w", err)
+		}
+		q.PageSize = min(max(size, 1), MaxPageSize)
+	}
+	return q, nil
+}
+
+// Sortable reports whether the objects of the query's kind can be sorted by column.
+func (q Query) Sortable(column string) bool {
+	for _, c := range sortColumns[q.Kind] {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Encode returns the query string of q, without its defaults.
+func (q Query) Encode() string {
+	values := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	set("q", q.Search)
+	set("selector", q.Selector)
+	set("status", q.Status)
+	set("server", q.Server)
+	if q.Sort != sortColumns[q.Kind][0] {
+		set("sort", q.Sort)
+	}
+	if q.Desc {
+		set("desc", "true")
+	}
+	if q.Page > 1 {
+		set("page", strconv.Itoa(q.Page))
+	}
+	if q.PageSize != DefaultPageSize {
+		set("size", strconv.Itoa(q.PageSize))
+	}
+	return values.Encode()
+}
+
+// WithPage returns q for another page.
+func (q Query) WithPage(page int) Query {
+	q.Page = page
+	return q
+}
+
+// SortedBy returns q sorted by column from the first page, reversing the order when it is
+// already sorted by column.
+func (q Query) SortedBy(column string) Query {
+	q.Desc = q.Sort == column && !q.Desc
+	q.Sort = column
+	q.Page = 1
+	return q
+}
+
+// Result is a page of search results.
+type Result struct {
+	Query   Query
+	Objects []Object
+	Total   int // Matching objects on all pages
+	Pages   int
+}
+
+// Search returns the page of the objects matching q.
+func (h *Hub) Search(q Query) Result {
+	search := strings.ToLower(q.Search)
+	h.mu.RLock()
+	var matches []Object
+	for _, o := range h.objects[q.Kind] {
+		if q.Kind == metadata.Server {
+			o.Players = h.online[o.Name]
+		}
+		if q.matches(o, search) {
+			matches = append(matches, o)
+		}
+	}
+	h.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if q.Desc {
+			return q.less(matches[j], matches[i])
+		}
+		return q.less(matches[i], matches[j])
+	})
+
+	result := Result{Query: q, Total: len(matches), Pages: max((len(matches)+q.PageSize-1)/q.PageSize, 1)}
+	result.Query.Page = min(q.Page, result.Pages)
+	start := (result.Query.Page - 1) * q.PageSize
+	result.Objects = matches[start:min(start+q.PageSize, len(matches))]
+	return result
+}
+
+// Object returns the current object name of kind.
+func (h *Hub) Object(kind metadata.Kind, name string) (Object, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	o, ok := h.objects[kind][name]
+	if kind == metadata.Server {
+		o.Players = h.online[name]
+	}
+	return o, ok
+}
+
+func (q Query) matches(o Object, search string) bool {
+	if search != "" {
+		switch q.Kind {
+		case metadata.Player:
+			if !strings.Contains(strings.ToLower(o.PlayerName), search) && !strings.HasPrefix(strings.ToLower(o.Name), search) {
+				return false
+			}
+		default:
+			if !strings.Contains(strings.ToLower(o.Name), search) {
+				return false
+			}
+		}
+	}
+	if len(q.selector) > 0 && (o.Doc == nil || !q.selector.Matches(o.Doc.Labels)) {
+		return false
+	}
+	if q.Status != "" && o.Online != (q.Status == "online") {
+		return false
+	}
+	if q.Server != "" && (!o.Online || o.Location != q.Server) {
+		return false
+	}
+	return true
+}
+
+// less orders objects by the sort column, then by name.
+func (q Query) less(a, b Object) bool {
+	var ka, kb string
+	switch q.Sort {
+	case "name":
+		if q.Kind == metadata.Player {
+			ka, kb = strings.ToLower(a.PlayerName), strings.ToLower(b.PlayerName)
+		}
+	case "status":
+		if a.Online != b.Online {
+			return a.Online
+		}
+	case "server":
+		ka, kb = a.Location, b.Location
+	case "type":
+		ka, kb = label(a.Doc, "type"), label(b.Doc, "type")
+	case "players":
+		if a.Players != b.Players {
+			return a.Players < b.Players
+		}
+	}
+	if ka != kb {
+		return ka < kb
+	}
+	return a.Name < b.Name
+}
+
+// newObject indexes the metadata of an object.
+func newObject(name string, doc *metadata.Document) Object {
+	o := Object{Name: name, Doc: doc}
+	if doc != nil {
+		o.PlayerName = doc.Annotations["player/name"]
+		o.Online = doc.Annotations["player/online"] == "true"
+		o.Location = doc.Annotations["network/location"]
+	}
+	return o
+}
+
+// set stores the object o of kind; h.mu must be held.
+func (h *Hub) set(kind metadata.Kind, o Object) {
+	h.remove(kind, o.Name)
+	h.objects[kind][o.Name] = o
+	if kind == metadata.Player && o.Online && o.Location != "" {
+		h.online[o.Location]++
+	}
+}
+
+// remove removes the object name of kind; h.mu must be held.
+func (h *Hub) remove(kind metadata.Kind, name string) {
+	o, ok := h.objects[kind][name]
+	if !ok {
+		return
+	}
+	delete(h.objects[kind], name)
+	if kind == metadata.Player && o.Online && o.Location != "" {
+		if h.online[o.Location]--; h.online[o.Location] <= 0 {
+			delete(h.online, o.Location)
+		}
+	}
+}
+
+func label(doc *metadata.Document, key string) string {
+	if doc == nil {
+		return ""
+	}
+	return doc.Labels[key]
+}
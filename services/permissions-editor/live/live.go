@@ -0,0 +1,225 @@
+// Package live follows the metadata KV bucket, for pages of the editor that update as proxies
+// change player and server metadata.
+//
+// A Hub watches the bucket, keeps the current metadata of every object and passes each change on
+// to its subscribers, typically server-sent event streams (see Stream). It indexes the names,
+// status and location of players for the searches of the players and servers lists (see Search).
+package live
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/bafbi/minecraft-network/services/permissions-editor/metadata"
+)
+
+// subscriberBuffer is the number of changes a subscriber may lag behind before it is dropped.
+const subscriberBuffer = 256
+
+// Change is a change of the metadata of an object.
+type Change struct {
+	Kind     metadata.Kind
+	Name     string             // Player UUID or server name
+	Doc      *metadata.Document // Nil when deleted or not valid metadata
+	Revision uint64
+	Created  bool // The object had no metadata before
+	Deleted  bool
+}
+
+// Object is the current metadata of an object.
+type Object struct {
+	Name string
+	Doc  *metadata.Document // Nil when not valid metadata
+
+	// Indexed from the annotations of players
+	PlayerName string
+	Online     bool
+	Location   string
+
+	Players int // Online players of a server, set in search results
+}
+
+// Hub watches the metadata of the objects whose KV keys start with the prefixes.
+type Hub struct {
+	kv       nats.KeyValue
+	prefixes map[metadata.Kind]string
+
+	mu      sync.RWMutex
+	objects map[metadata.Kind]map[string]Object
+	online  map[string]int // Online players by location
+	subs    map[*subscriber]struct{}
+	synced  chan struct{} // Closed once the initial values have been loaded
+}
+
+type subscriber struct {
+	ch     chan Change
+	filter func(Change) bool
+}
+
+// NewHub returns a hub for the metadata in kv; prefixes gives the key prefix of each kind.
+func NewHub(kv nats.KeyValue, prefixes map[metadata.Kind]string) *Hub {
+	objects := make(map[metadata.Kind]map[string]Object, len(prefixes))
+	for kind := range prefixes {
+		objects[kind] = make(map[string]Object)
+	}
+	return &Hub{
+		kv:       kv,
+		prefixes: prefixes,
+		objects:  objects,
+		online:   make(map[string]int),
+		subs:     make(map[*subscriber]struct{}),
+		synced:   make(chan struct{}),
+	}
+}
+
+// Run watches the bucket until ctx is cancelled, starting over when the watch fails.
+func (h *Hub) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		err := h.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("Metadata watch stopped, restarting", "delay", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, 30*time.Second)
+	}
+}
+
+func (h *Hub) watch(ctx context.Context) error {
+	watcher, err := h.kv.WatchAll(nats.Context(ctx))
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	// The watch starts with the current values, then a nil entry. After a restart, objects deleted
+	// while it was down are not in the initial values: they are found by comparing with seen.
+	initial := true
+	seen := make(map[string]bool)
+	for entry := range watcher.Updates() {
+		if entry == nil {
+			h.endInitialValues(seen)
+			initial = false
+			continue
+		}
+		if initial {
+			seen[entry.Key()] = true
+		}
+		h.apply(entry)
+	}
+	return ctx.Err()
+}
+
+func (h *Hub) apply(entry nats.KeyValueEntry) {
+	kind, name, ok := h.parseKey(entry.Key())
+	if !ok {
+		return
+	}
+	change := Change{Kind: kind, Name: name, Revision: entry.Revision()}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	current, existed := h.objects[kind][name]
+	switch entry.Operation() {
+	case nats.KeyValueDelete, nats.KeyValuePurge:
+		if !existed {
+			return
+		}
+		h.remove(kind, name)
+		change.Deleted = true
+	default:
+		if existed && current.Doc != nil && current.Doc.Revision >= entry.Revision() {
+			return // Already known, the watch was restarted
+		}
+		doc, err := metadata.Parse(entry.Value(), entry.Revision())
+		if err != nil {
+			slog.Warn("Invalid metadata", "kind", kind, "name", name, "revision", entry.Revision(), "error", err)
+		}
+		h.set(kind, newObject(name, doc))
+		change.Doc = doc
+		change.Created = !existed
+	}
+	h.publish(change)
+}
+
+// endInitialValues drops the objects that were not in the initial values of the watch.
+func (h *Hub) endInitialValues(seen map[string]bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for kind, objects := range h.objects {
+		for name := range objects {
+			if !seen[h.prefixes[kind]+name] {
+				h.remove(kind, name)
+				h.publish(Change{Kind: kind, Name: name, Deleted: true})
+			}
+		}
+	}
+	select {
+	case <-h.synced:
+	default:
+		close(h.synced)
+		slog.Info("Loaded the metadata of the KV bucket, following its changes")
+	}
+}
+
+func (h *Hub) parseKey(key string) (metadata.Kind, string, bool) {
+	for kind, prefix := range h.prefixes {
+		if name, ok := strings.CutPrefix(key, prefix); ok && name != "" {
+			return kind, name, true
+		}
+	}
+	return "", "", false
+}
+
+// publish passes change on to the subscribers; h.mu must be held.
+func (h *Hub) publish(change Change) {
+	for sub := range h.subs {
+		if sub.filter != nil && !sub.filter(change) {
+			continue
+		}
+		select {
+		case sub.ch <- change:
+		default:
+			// Too slow: closing the channel ends its stream, which the browser opens again
+			delete(h.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe returns the changes for which filter (when not nil) returns true, until cancel is
+// called. The channel is closed when the subscriber falls too far behind.
+func (h *Hub) Subscribe(filter func(Change) bool) (changes <-chan Change, cancel func()) {
+	sub := &subscriber{ch: make(chan Change, subscriberBuffer), filter: filter}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[sub]; ok {
+			delete(h.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// WaitSynced waits until the initial values have been loaded, or ctx is done.
+func (h *Hub) WaitSynced(ctx context.Context) error {
+	select {
+	case <-h.synced:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,54 @@
+package live
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// KeepAliveInterval is how often streams send a comment, so that idle connections are not closed
+// by proxies in between.
+const KeepAliveInterval = 25 * time.Second
+
+// Stream writes server-sent events.
+type Stream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewStream starts an event stream response on w.
+func NewStream(w http.ResponseWriter) (*Stream, error) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable buffering by nginx
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return nil, fmt.Errorf("streaming not supported: %w", err)
+	}
+	return &Stream{w: w, rc: rc}, nil
+}
+
+// Send sends an event named event, of which htmx swaps data (usually HTML) into the element with
+// a matching sse-swap attribute.
+func (s *Stream) Send(event, data string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "event: %s\n", event)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	if _, err := s.w.Write([]byte(b.String())); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// KeepAlive sends a comment, ignored by the browser.
+func (s *Stream) KeepAlive() error {
+	if _, err := s.w.Write([]byte(": keep-alive\n\n")); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
@@ -2,32 +2,66 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/a-h/templ"
 	"github.com/nats-io/nats.go"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/bafbi/minecraft-network/pkg/observability"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/audit"
 	authpb "github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-checker/grpcauth"
+	"github.com/bafbi/minecraft-network/services/permissions-editor/live"
+	"github.com/bafbi/minecraft-network/services/permissions-editor/metadata"
 	"github.com/bafbi/minecraft-network/services/permissions-editor/templates"
+	"github.com/bafbi/minecraft-network/services/permissions-editor/webauth"
 )
 
 // AppState holds common dependencies for handlers
 type AppState struct {
 	AuthClient           authpb.AuthServiceClient
 	NATSKV               nats.KeyValue
+	JS                   nats.JetStreamContext
+	AuditStream          string // JetStream stream holding permission decisions
 	PlayerMetadataPrefix string
 	ServerMetadataPrefix string
+	Live                 *live.Hub // Current metadata, following the KV bucket
 }
 
 func main() {
+	logger := observability.SetupLogging("permissions-editor")
+	// Requests to the checker continue the trace of the HTTP request that made them
+	shutdownTracing, err := observability.SetupTracing(context.Background(), "permissions-editor", logger)
+	if err != nil {
+		fatal("Error setting up tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Error flushing traces", "error", err)
+		}
+	}()
+
+	// Users sign in with OIDC or the static admin account, see the webauth package for the settings
+	authConfig, err := webauth.ConfigFromEnv()
+	if err != nil {
+		fatal("Invalid authentication settings", err)
+	}
+	authenticator, err := webauth.New(authConfig, templates.Login)
+	if err != nil {
+		fatal("Error setting up authentication", err)
+	}
+
 	natsAddr := os.Getenv("NATS_ADDR")
 	if natsAddr == "" {
 		natsAddr = "nats://localhost:4222"
@@ -42,65 +76,123 @@ func main() {
 
 	nc, err := nats.Connect(natsAddr, natsOpts...)
 	if err != nil {
-		log.Fatalf("Error connecting to NATS: %v", err)
+		fatal("Error connecting to NATS", err)
 	}
 	defer nc.Close()
-	log.Println("Connected to NATS!")
+	slog.Info("Connected to NATS", "url", nc.ConnectedUrlRedacted())
 
 	js, err := nc.JetStream()
 	if err != nil {
-		log.Fatalf("Error getting JetStream context: %v", err)
+		fatal("Error getting JetStream context", err)
 	}
 
 	kv, err := js.KeyValue("metadata")
 	if err != nil {
-		log.Fatalf("Error getting KV bucket 'metadata': %v", err)
+		fatal("Error getting KV bucket 'metadata'", err)
+	}
+	slog.Info("Connected to NATS KV bucket", "bucket", "metadata")
+
+	auditStream := os.Getenv("AUDIT_STREAM")
+	if auditStream == "" {
+		auditStream = audit.DefaultStream
 	}
-	log.Println("Connected to NATS KV 'metadata' bucket!")
 
 	grpcAddr := os.Getenv("GRPC_ADDR")
 	if grpcAddr == "" {
 		grpcAddr = "localhost:50051"
 	}
 
-	conn, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// TLS and the editor's credentials (an admin identity) come from CHECKER_TLS_CA, CHECKER_TLS_CERT,
+	// CHECKER_TLS_KEY, CHECKER_TLS_SERVER_NAME, CHECKER_API_KEY and CHECKER_TOKEN
+	dialOpts, err := grpcauth.ClientConfigFromEnv("CHECKER_").DialOptions()
+	if err != nil {
+		fatal("Invalid permissions-checker client credentials", err)
+	}
+	// Policy changes are recorded under the name of the signed-in user
+	dialOpts = append(dialOpts, observability.GRPCDialOption(), grpc.WithChainUnaryInterceptor(webauth.ActorInterceptor()))
+	conn, err := grpc.Dial(grpcAddr, dialOpts...)
 	if err != nil {
-		log.Fatalf("did not connect to gRPC service: %v", err)
+		fatal("Could not connect to the permissions-checker", err)
 	}
 	defer conn.Close()
 	authClient := authpb.NewAuthServiceClient(conn)
-	log.Println("Connected to gRPC Permissions-Checker Service!")
+	slog.Info("Connected to the permissions-checker", "addr", grpcAddr)
 
 	appState := &AppState{
 		AuthClient:           authClient,
 		NATSKV:               kv,
+		JS:                   js,
+		AuditStream:          auditStream,
 		PlayerMetadataPrefix: "player.metadata.",
 		ServerMetadataPrefix: "server.metadata.",
 	}
+	appState.Live = live.NewHub(kv, map[metadata.Kind]string{
+		metadata.Player: appState.PlayerMetadataPrefix,
+		metadata.Server: appState.ServerMetadataPrefix,
+	})
+	go appState.Live.Run(context.Background())
 
 	r := chi.NewRouter()
+	r.Use(observability.HTTPMiddleware("/static/", "/events/"))
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 
 	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("./static"))))
 
-	r.Get("/", templ.Handler(templates.Base()))
-	r.Get("/players", appState.listPlayersHandler)
-	r.Get("/player/{uuid}", appState.getPlayerDetailHandler)
-	r.Post("/player/{uuid}", appState.updatePlayerMetadataHandler)
-	r.Get("/servers", appState.listServersHandler)
-	r.Get("/server/{name}", appState.getServerDetailHandler)
-	r.Post("/server/{name}", appState.updateServerMetadataHandler)
-	r.Get("/policies", appState.listPoliciesHandler)
-	r.Post("/policies", appState.addPolicyHandler)
-	r.Delete("/policies", appState.deletePolicyHandler)
-
-	log.Println("Web server starting on :8080")
+	authenticator.Routes(r)
+
+	r.Group(func(r chi.Router) {
+		r.Use(authenticator.Middleware)
+
+		// Browsing, including rollback previews, is open to every role
+		r.Group(func(r chi.Router) {
+			r.Use(authenticator.Require(webauth.RoleViewer))
+			r.Handle("/", templ.Handler(templates.Base()))
+			r.Get("/players", appState.listPlayersHandler)
+			r.Get("/player/{uuid}", appState.getPlayerDetailHandler)
+			r.Get("/player/{uuid}/metadata", appState.getPlayerMetadataHandler)
+			r.Get("/servers", appState.listServersHandler)
+			r.Get("/server/{name}", appState.getServerDetailHandler)
+			r.Get("/server/{name}/metadata", appState.getServerMetadataHandler)
+			r.Get("/policies", appState.listPoliciesHandler)
+			r.Get("/policies/history", appState.policyHistoryHandler)
+			r.Get("/policies/history/{version}/rollback", appState.rollbackPolicyHandler)
+			r.Get("/audit", appState.auditPageHandler)
+			r.Get("/api/audit/decisions", appState.auditAPIHandler)
+
+			// Server-sent events updating the pages above
+			r.Get("/events/players", appState.playerEventsHandler)
+			r.Get("/events/servers", appState.serverEventsHandler)
+			r.Get("/events/player/{uuid}", appState.playerMetadataEventsHandler)
+			r.Get("/events/server/{name}", appState.serverMetadataEventsHandler)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authenticator.Require(webauth.RoleMetadataEditor))
+			r.Post("/player/{uuid}", appState.updatePlayerMetadataHandler)
+			r.Post("/server/{name}", appState.updateServerMetadataHandler)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authenticator.Require(webauth.RolePolicyAdmin))
+			r.Post("/policies", appState.addPolicyHandler)
+			r.Delete("/policies", appState.deletePolicyHandler)
+			r.Post("/policies/history/{version}/rollback", appState.rollbackPolicyHandler)
+		})
+	})
+
+	slog.Info("Web server starting", "addr", ":8080")
 	if err := http.ListenAndServe(":8080", r); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+		fatal("Server failed to start", err)
 	}
 }
 
+// fatal logs err and exits, for failures that prevent the editor from running.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
 func render(w http.ResponseWriter, r *http.Request, component templ.Component) {
 	err := component.Render(r.Context(), w)
 	if err != nil {
@@ -0,0 +1,144 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Names of the fields of the editor form. Labels and free-form annotations are submitted as
+// parallel lists of keys and values, known annotations as AnnotationPrefix followed by their key.
+const (
+	LabelKeyField        = "label_key"
+	LabelValueField      = "label_value"
+	AnnotationKeyField   = "annotation_key"
+	AnnotationValueField = "annotation_value"
+	AnnotationPrefix     = "annotation."
+	RevisionField        = "revision"
+)
+
+const (
+	maxKeyLength   = 253
+	maxLabelLength = 256
+)
+
+// Edited returns a copy of d with the labels and annotations submitted in form, and the problems
+// found in form. Annotations managed by the proxy keep their value.
+func (d *Document) Edited(kind Kind, form url.Values) (*Document, []string) {
+	edited := *d
+	edited.Labels = make(map[string]string)
+	edited.Annotations = make(map[string]string)
+	var errs []string
+
+	labelKeys, labelValues := form[LabelKeyField], form[LabelValueField]
+	for i, key := range labelKeys {
+		key = strings.TrimSpace(key)
+		value := strings.TrimSpace(at(labelValues, i))
+		if key == "" && value == "" {
+			continue // Blank row
+		}
+		if err := validKey(key); err != nil {
+			errs = append(errs, fmt.Sprintf("Label %q: %v", key, err))
+			continue
+		}
+		if _, dup := edited.Labels[key]; dup {
+			errs = append(errs, fmt.Sprintf("Label %q is set twice", key))
+			continue
+		}
+		if len(value) > maxLabelLength || strings.ContainsAny(value, "\r\n") {
+			errs = append(errs, fmt.Sprintf("Label %q: values must be a single line of at most %d characters", key, maxLabelLength))
+			continue
+		}
+		edited.Labels[key] = value
+	}
+
+	for _, field := range Annotations(kind) {
+		if field.Managed {
+			if value, ok := d.Annotations[field.Key]; ok {
+				edited.Annotations[field.Key] = value
+			}
+			continue
+		}
+		value, err := fieldValue(field, form.Get(AnnotationPrefix+field.Key))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Annotation %q: %v", field.Key, err))
+			continue
+		}
+		if value != "" {
+			edited.Annotations[field.Key] = value
+		}
+	}
+
+	annotKeys, annotValues := form[AnnotationKeyField], form[AnnotationValueField]
+	for i, key := range annotKeys {
+		key = strings.TrimSpace(key)
+		value := at(annotValues, i)
+		if key == "" && strings.TrimSpace(value) == "" {
+			continue
+		}
+		if err := validKey(key); err != nil {
+			errs = append(errs, fmt.Sprintf("Annotation %q: %v", key, err))
+			continue
+		}
+		if _, known := annotationField(kind, key); known {
+			errs = append(errs, fmt.Sprintf("Annotation %q has its own field above", key))
+			continue
+		}
+		if _, dup := edited.Annotations[key]; dup {
+			errs = append(errs, fmt.Sprintf("Annotation %q is set twice", key))
+			continue
+		}
+		edited.Annotations[key] = value
+	}
+	return &edited, errs
+}
+
+// fieldValue converts the input of a known annotation to its stored value, empty to unset it.
+func fieldValue(field Field, input string) (string, error) {
+	switch field.Type {
+	case TypeBool:
+		if input != "" && input != "true" && input != "false" {
+			return "", fmt.Errorf("must be true or false")
+		}
+		return input, nil
+	case TypeList:
+		var values []string
+		for _, line := range strings.Split(input, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				values = append(values, line)
+			}
+		}
+		if len(values) == 0 {
+			return "", nil
+		}
+		data, err := json.Marshal(values)
+		return string(data), err
+	default:
+		return strings.TrimSpace(input), nil
+	}
+}
+
+// validKey accepts keys such as "vip" or "server/group".
+func validKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("the key is empty")
+	}
+	if len(key) > maxKeyLength {
+		return fmt.Errorf("keys are at most %d characters", maxKeyLength)
+	}
+	for _, r := range key {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("keys cannot contain spaces")
+		}
+	}
+	return nil
+}
+
+func at(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+// Package metadata edits player and server metadata as stored in NATS KV by the proxy (see
+// servers/proxy_gate/util/metadata): {"Labels": {...}, "Annotations": {...}}, all values strings,
+// list annotations being JSON-encoded.
+//
+// Documents are edited in place: other top-level fields, such as the flat values written by older
+// versions of the editor, and non-string values within the labels and annotations are written
+// back unchanged.
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Field names of the proxy's Metadata struct. The lower-case forms are read as well.
+const (
+	labelsField      = "Labels"
+	annotationsField = "Annotations"
+)
+
+// Document is a metadata KV entry.
+type Document struct {
+	Labels      map[string]string
+	Annotations map[string]string
+	Revision    uint64 // KV revision the document was read at
+
+	labelsKey      string                     // Key the labels were read from
+	annotationsKey string                     // Key the annotations were read from
+	other          map[string]json.RawMessage // Other top-level fields
+	otherLabels    map[string]json.RawMessage // Non-string labels
+	otherAnnots    map[string]json.RawMessage // Non-string annotations
+}
+
+// Parse reads the document stored at revision.
+func Parse(data []byte, revision uint64) (*Document, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("metadata is not a JSON object: %w", err)
+	}
+	d := &Document{
+		Labels:         make(map[string]string),
+		Annotations:    make(map[string]string),
+		Revision:       revision,
+		labelsKey:      labelsField,
+		annotationsKey: annotationsField,
+		other:          make(map[string]json.RawMessage),
+	}
+	for key, value := range raw {
+		switch {
+		case key == labelsField || key == strings.ToLower(labelsField):
+			d.labelsKey = key
+			if err := splitStrings(value, d.Labels, &d.otherLabels); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+		case key == annotationsField || key == strings.ToLower(annotationsField):
+			d.annotationsKey = key
+			if err := splitStrings(value, d.Annotations, &d.otherAnnots); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+		default:
+			d.other[key] = value
+		}
+	}
+	return d, nil
+}
+
+// splitStrings reads the JSON object value into strings, and its other values into other.
+func splitStrings(value json.RawMessage, strs map[string]string, other *map[string]json.RawMessage) error {
+	if string(value) == "null" {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return err
+	}
+	for key, v := range fields {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			strs[key] = s
+			continue
+		}
+		if *other == nil {
+			*other = make(map[string]json.RawMessage)
+		}
+		(*other)[key] = v
+	}
+	return nil
+}
+
+// Marshal encodes the document for storage.
+func (d *Document) Marshal() ([]byte, error) {
+	out := make(map[string]any, len(d.other)+2)
+	for key, value := range d.other {
+		out[key] = value
+	}
+	out[d.labelsKey] = mergeStrings(d.Labels, d.otherLabels)
+	out[d.annotationsKey] = mergeStrings(d.Annotations, d.otherAnnots)
+	return json.Marshal(out)
+}
+
+func mergeStrings(strs map[string]string, other map[string]json.RawMessage) map[string]any {
+	merged := make(map[string]any, len(strs)+len(other))
+	for key, value := range other {
+		merged[key] = value
+	}
+	for key, value := range strs {
+		merged[key] = value
+	}
+	return merged
+}
+
+// Preserved are the top-level fields and values kept as they are, for display.
+type Preserved struct {
+	Key   string
+	Value string // JSON
+}
+
+// PreservedFields lists the values the editor does not edit, sorted by key.
+func (d *Document) PreservedFields() []Preserved {
+	var fields []Preserved
+	for key, value := range d.other {
+		fields = append(fields, Preserved{Key: key, Value: string(value)})
+	}
+	for key, value := range d.otherLabels {
+		fields = append(fields, Preserved{Key: d.labelsKey + "." + key, Value: string(value)})
+	}
+	for key, value := range d.otherAnnots {
+		fields = append(fields, Preserved{Key: d.annotationsKey + "." + key, Value: string(value)})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields
+}
+
+// SortedKeys returns the keys of m in order.
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Editor is what the metadata editor of an object shows.
+type Editor struct {
+	Kind     Kind
+	Name     string // Player UUID or server name
+	Doc      *Document
+	Stored   string   // Stored document, indented
+	Errors   []string // Problems of the submitted form, which was not saved
+	Conflict bool     // The form was not saved: the document changed since it was loaded
+	Saved    bool
+}
+
+// URL is where the editor loads and saves the metadata.
+func (e Editor) URL() string {
+	return "/" + string(e.Kind) + "/" + url.PathEscape(e.Name)
+}
+
+// EventsURL streams the changes of the metadata since the revision shown.
+func (e Editor) EventsURL() string {
+	return fmt.Sprintf("/events%s?revision=%d", e.URL(), e.Doc.Revision)
+}
+
+// OtherAnnotations returns the keys of the annotations without a field of their own.
+func (e Editor) OtherAnnotations() []string {
+	var keys []string
+	for _, key := range SortedKeys(e.Doc.Annotations) {
+		if _, known := annotationField(e.Kind, key); !known {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
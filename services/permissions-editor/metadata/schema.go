@@ -0,0 +1,86 @@
+package metadata
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Kind is the kind of object metadata belongs to.
+type Kind string
+
+const (
+	Player Kind = "player"
+	Server Kind = "server"
+)
+
+// FieldType is the type of the value of an annotation, all being stored as strings.
+type FieldType string
+
+const (
+	TypeString FieldType = "string"
+	TypeBool   FieldType = "bool" // "true" or "false"
+	TypeList   FieldType = "list" // JSON-encoded []string
+)
+
+// Field is a label or annotation known to the proxy or the permissions-checker.
+type Field struct {
+	Key         string
+	Type        FieldType
+	Description string
+	Managed     bool // Written by the proxy, not editable
+}
+
+var playerAnnotations = []Field{
+	{Key: "player/name", Type: TypeString, Description: "Last known name of the player", Managed: true},
+	{Key: "player/online", Type: TypeBool, Description: "Whether the player is connected to the network", Managed: true},
+	{Key: "network/location", Type: TypeString, Description: "Server the player is connected to", Managed: true},
+	{Key: "permissions/groups", Type: TypeList, Description: "Groups the player is a member of, one per line"},
+	{Key: "chat/pub-layers", Type: TypeList, Description: "Chat layers the player's messages are sent to, one per line"},
+	{Key: "chat/sub-layers", Type: TypeList, Description: "Chat layers the player receives messages from, one per line"},
+	{Key: "network/debug", Type: TypeBool, Description: "Show network debug information to the player"},
+}
+
+var serverAnnotations = []Field{
+	{Key: "server/address", Type: TypeString, Description: "Address the proxy connects to, host:port"},
+	{Key: "chat/pub-layers", Type: TypeList, Description: "Chat layers messages sent on the server go to, one per line"},
+	{Key: "chat/sub-layers", Type: TypeList, Description: "Chat layers players on the server receive messages from, one per line"},
+}
+
+var serverLabels = []Field{
+	{Key: "type", Type: TypeString, Description: `Server type; players are sent to "lobby" servers on join`},
+	{Key: "server/group", Type: TypeString, Description: "Server group, the domain of group assignments"},
+}
+
+// Annotations returns the annotations known for kind.
+func Annotations(kind Kind) []Field {
+	if kind == Server {
+		return serverAnnotations
+	}
+	return playerAnnotations
+}
+
+// Labels returns the labels known for kind, suggested when adding labels.
+func Labels(kind Kind) []Field {
+	if kind == Server {
+		return serverLabels
+	}
+	return nil
+}
+
+func annotationField(kind Kind, key string) (Field, bool) {
+	for _, f := range Annotations(kind) {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// ListLines returns a list annotation one value per line, or the raw value when it is not a list.
+func ListLines(value string) string {
+	var values []string
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return value
+	}
+	return strings.Join(values, "\n")
+}
@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Selector selects objects by their labels, written like Kubernetes equality-based selectors:
+// "type=lobby,server/group!=survival,vip,!banned" requires each of the comma-separated terms.
+type Selector []Requirement
+
+// Requirement is a term of a selector.
+type Requirement struct {
+	Key      string
+	Value    string
+	Operator string // "=", "!=", "exists" or "!exists"
+}
+
+// ParseSelector parses s, the empty selector selecting everything.
+func ParseSelector(s string) (Selector, error) {
+	var sel Selector
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		var req Requirement
+		switch {
+		case strings.Contains(term, "!="):
+			key, value, _ := strings.Cut(term, "!=")
+			req = Requirement{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value), Operator: "!="}
+		case strings.Contains(term, "="):
+			key, value, _ := strings.Cut(strings.Replace(term, "==", "=", 1), "=")
+			req = Requirement{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value), Operator: "="}
+		case strings.HasPrefix(term, "!"):
+			req = Requirement{Key: strings.TrimSpace(term[1:]), Operator: "!exists"}
+		default:
+			req = Requirement{Key: term, Operator: "exists"}
+		}
+		if err := validKey(req.Key); err != nil {
+			return nil, fmt.Errorf("invalid selector term %q: %v", term, err)
+		}
+		sel = append(sel, req)
+	}
+	return sel, nil
+}
+
+// Matches reports whether labels meet every requirement of s.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, ok := labels[req.Key]
+		switch req.Operator {
+		case "=":
+			if !ok || value != req.Value {
+				return false
+			}
+		case "!=":
+			if ok && value == req.Value {
+				return false
+			}
+		case "exists":
+			if !ok {
+				return false
+			}
+		case "!exists":
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -2,12 +2,28 @@
 package templates
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	authpb "github.com/bafbi/minecraft-network/services/permissions-checker/auth"
+	"github.com/bafbi/minecraft-network/services/permissions-editor/live"
+	"github.com/bafbi/minecraft-network/services/permissions-editor/metadata"
+	"github.com/bafbi/minecraft-network/services/permissions-editor/webauth"
 )
 
+// CSRFHeaders returns the hx-headers sending the CSRF token of the signed-in user with every
+// htmx request.
+func CSRFHeaders(ctx context.Context) string {
+	headers, _ := json.Marshal(map[string]string{"X-CSRF-Token": webauth.CSRFToken(ctx)})
+	return string(headers)
+}
+
 func GetPolicyFieldString(policy *authpb.PolicyRule, fieldName string) string {
 	if policy == nil {
 		return ""
@@ -37,6 +53,37 @@ func GetPolicyPriorityString(policy *authpb.PolicyRule) string {
 	return "100"
 }
 
+// expiresAtInputLayout is the value format of datetime-local inputs.
+const expiresAtInputLayout = "2006-01-02T15:04"
+
+// GetPolicyExpiresAtString returns the expiry of a time-bound policy for the form input, in local time.
+func GetPolicyExpiresAtString(policy *authpb.PolicyRule) string {
+	if policy.GetExpiresAt() == nil {
+		return ""
+	}
+	return policy.GetExpiresAt().AsTime().Local().Format(expiresAtInputLayout)
+}
+
+// FormatPolicyExpiry returns when a policy expires, or "never" for permanent policies.
+func FormatPolicyExpiry(policy *authpb.PolicyRule) string {
+	if policy.GetExpiresAt() == nil {
+		return "never"
+	}
+	return policy.GetExpiresAt().AsTime().Local().Format("2006-01-02 15:04")
+}
+
+// ParsePolicyExpiresAt parses the expiry form input (local time); empty means permanent.
+func ParsePolicyExpiresAt(value string) (*timestamppb.Timestamp, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(expiresAtInputLayout, value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return timestamppb.New(t), nil
+}
+
 func GetPolicyConditionDefault(policy *authpb.PolicyRule, fieldName string) string {
 	val := GetPolicyFieldString(policy, fieldName)
 	if val == "" {
@@ -49,36 +96,55 @@ func IsSelected(currentEffect, optionValue string) bool {
 	return currentEffect == optionValue
 }
 
-func FormatMetadataForTextarea(metadata map[string]interface{}) string {
-	var sb strings.Builder
-	for k, v := range metadata {
-		switch val := v.(type) {
-		case string:
-			sb.WriteString(fmt.Sprintf("%s=%s\n", k, val))
-		case bool:
-			sb.WriteString(fmt.Sprintf("%s=%t\n", k, val))
-		case float64:
-			if float64(int(val)) == val {
-				sb.WriteString(fmt.Sprintf("%s=%d\n", k, int(val)))
-			} else {
-				sb.WriteString(fmt.Sprintf("%s=%f\n", k, val))
-			}
-		case []interface{}:
-			var s []string
-			for _, item := range val {
-				s = append(s, fmt.Sprintf("%v", item))
-			}
-			sb.WriteString(fmt.Sprintf("%s=%s\n", k, strings.Join(s, ",")))
-		default:
-			sb.WriteString(fmt.Sprintf("%s=%v\n", k, val))
+// GetPolicyFormButtonText returns the form title and button text. A policy re-rendered
+// with validation errors is still being added.
+func GetPolicyFormButtonText(policy *authpb.PolicyRule, errs map[string]string) string {
+	if policy != nil && errs == nil {
+		return "Update Policy"
+	}
+	return "Add Policy"
+}
+
+// PolicyRuleSummary formats a policy on one line for diffs.
+func PolicyRuleSummary(rule *authpb.PolicyRule) string {
+	return fmt.Sprintf("%s: %s %s on %s if player(%s) && server(%s) [priority %d]",
+		rule.GetId(), rule.GetEffect(), rule.GetTargetAction(), rule.GetTargetResource(),
+		rule.GetPlayerConditionExpression(), rule.GetServerConditionExpression(), rule.GetPriority())
+}
+
+// RowID returns the element ID of the list row of a player or server, made of characters that
+// are valid in CSS selectors.
+func RowID(kind metadata.Kind, name string) string {
+	var b strings.Builder
+	b.WriteString(string(kind) + "-")
+	for _, r := range name {
+		if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_') {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(&b, "_%x_", r)
 		}
 	}
-	return sb.String()
+	return b.String()
 }
 
-func GetPolicyFormButtonText(policy *authpb.PolicyRule) string {
-	if policy != nil {
-		return "Update Policy"
+func annotation(doc *metadata.Document, key string) string {
+	if doc == nil {
+		return ""
 	}
-	return "Add Policy"
+	return doc.Annotations[key]
+}
+
+func label(doc *metadata.Document, key string) string {
+	if doc == nil {
+		return ""
+	}
+	return doc.Labels[key]
+}
+
+// resultCount returns the number of players or servers matching a search.
+func resultCount(r live.Result) string {
+	if r.Total == 1 {
+		return fmt.Sprintf("1 %s", r.Query.Kind)
+	}
+	return fmt.Sprintf("%d %ss", r.Total, r.Query.Kind)
 }
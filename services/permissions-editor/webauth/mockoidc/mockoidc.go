@@ -0,0 +1,277 @@
+// Package mockoidc is a minimal OpenID Connect provider for developing and testing the editor
+// without an identity provider. It signs in whoever asks, with the user name and role they pick.
+//
+// It implements discovery, the authorization code flow with PKCE (S256) and RS256 ID tokens
+// carrying the picked role in a "roles" claim, with a key generated at startup.
+package mockoidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	codeTTL  = time.Minute
+	tokenTTL = time.Hour
+)
+
+// Provider serves the provider endpoints, relative to its issuer URL.
+type Provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	roles        []string
+	key          *rsa.PrivateKey
+	keyID        string
+	mux          *http.ServeMux
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+// grant is an authorization code waiting to be exchanged for tokens.
+type grant struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	username      string
+	role          string
+	expires       time.Time
+}
+
+// New returns a provider for the client clientID, offering roles on its sign-in page.
+func New(issuer, clientID, clientSecret string, roles []string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	p := &Provider{
+		issuer:       issuer,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		roles:        roles,
+		key:          key,
+		keyID:        randomString(8),
+		mux:          http.NewServeMux(),
+		codes:        make(map[string]grant),
+	}
+	p.mux.HandleFunc("GET /.well-known/openid-configuration", p.discoveryHandler)
+	p.mux.HandleFunc("GET /authorize", p.authorizeHandler)
+	p.mux.HandleFunc("POST /token", p.tokenHandler)
+	p.mux.HandleFunc("GET /jwks", p.jwksHandler)
+	return p, nil
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+func (p *Provider) discoveryHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{"openid", "profile"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+	})
+}
+
+var signInPage = template.Must(template.New("sign-in").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="UTF-8"/><title>Mock identity provider</title><link rel="stylesheet" href="/static/styles.css"/></head>
+<body class="bg-gray-100 font-sans">
+	<main class="container mx-auto mt-8 p-4">
+		<div class="bg-white p-6 rounded-lg shadow-md">
+			<h1 class="text-2xl font-bold mb-2">Mock identity provider</h1>
+			<p class="mb-4 text-gray-600">For development only: sign in as anyone, with any role.</p>
+			<form method="get" action="authorize">
+				{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}"/>
+				{{end}}<label class="block mb-2">User name <input class="border p-1" name="username" value="dev" required/></label>
+				<label class="block mb-4">Role <select class="border p-1" name="role">
+					{{range .Roles}}<option value="{{.}}">{{.}}</option>{{end}}
+					<option value="">(none)</option>
+				</select></label>
+				<button class="btn-blue" type="submit">Sign in</button>
+			</form>
+		</div>
+	</main>
+</body>
+</html>`))
+
+// authorizeHandler shows the sign-in form, then redirects back to the client with a code once it
+// has been submitted. The form is submitted with GET, like the authorization request itself.
+func (p *Provider) authorizeHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != p.clientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "only the code flow with an S256 code_challenge is supported", http.StatusBadRequest)
+		return
+	}
+
+	if !q.Has("username") {
+		params := make(map[string]string)
+		for _, name := range []string{"client_id", "redirect_uri", "response_type", "scope", "state", "nonce", "code_challenge", "code_challenge_method"} {
+			params[name] = q.Get(name)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = signInPage.Execute(w, map[string]any{"Params": params, "Roles": p.roles})
+		return
+	}
+
+	code := randomString(24)
+	p.mu.Lock()
+	for c, g := range p.codes {
+		if time.Now().After(g.expires) {
+			delete(p.codes, c)
+		}
+	}
+	p.codes[code] = grant{
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+		nonce:         q.Get("nonce"),
+		username:      q.Get("username"),
+		role:          q.Get("role"),
+		expires:       time.Now().Add(codeTTL),
+	}
+	p.mu.Unlock()
+
+	back := redirectURI.Query()
+	back.Set("code", code)
+	back.Set("state", q.Get("state"))
+	redirectURI.RawQuery = back.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (p *Provider) tokenHandler(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != p.clientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(p.clientSecret)) != 1 {
+		tokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	if r.PostFormValue("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	p.mu.Lock()
+	g, ok := p.codes[r.PostFormValue("code")]
+	delete(p.codes, r.PostFormValue("code")) // Codes are single-use
+	p.mu.Unlock()
+	if !ok || time.Now().After(g.expires) || g.redirectURI != r.PostFormValue("redirect_uri") {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+	challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != g.codeChallenge {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss":                p.issuer,
+		"sub":                g.username,
+		"aud":                p.clientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(tokenTTL).Unix(),
+		"nonce":              g.nonce,
+		"preferred_username": g.username,
+		"roles":              []string{},
+	}
+	if g.role != "" {
+		claims["roles"] = []string{g.role}
+	}
+	idToken, err := p.sign(claims)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(24),
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+func (p *Provider) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": p.keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// sign returns claims as a JWT signed with RS256.
+func (p *Provider) sign(claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": p.keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func tokenError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("mockoidc: failed to write response", "error", err)
+	}
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
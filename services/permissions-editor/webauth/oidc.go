@@ -0,0 +1,184 @@
+package webauth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// oidcFlowTTL bounds the time between starting to sign in and coming back from the provider.
+const oidcFlowTTL = 10 * time.Minute
+
+// oidcClient signs users in with the authorization code flow and PKCE.
+type oidcClient struct {
+	cfg OIDCConfig
+
+	mu       sync.Mutex
+	provider *oidc.Provider // Discovered on first use: the mock provider is served by the editor itself
+}
+
+func newOIDCClient(cfg OIDCConfig) *oidcClient {
+	return &oidcClient{cfg: cfg}
+}
+
+func (c *oidcClient) discover(ctx context.Context) (*oidc.Provider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.provider == nil {
+		provider, err := oidc.NewProvider(ctx, c.cfg.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover OIDC provider %s: %w", c.cfg.Issuer, err)
+		}
+		c.provider = provider
+	}
+	return c.provider, nil
+}
+
+func (c *oidcClient) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     c.cfg.ClientID,
+		ClientSecret: c.cfg.ClientSecret,
+		RedirectURL:  c.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "profile"},
+	}
+}
+
+// oidcFlow is kept in a signed cookie while the user signs in with the provider.
+type oidcFlow struct {
+	State    string    `json:"state"`
+	Nonce    string    `json:"nonce"`
+	Verifier string    `json:"verifier"`
+	Next     string    `json:"next"`
+	Expires  time.Time `json:"exp"`
+}
+
+func (a *Authenticator) oidcLoginHandler(w http.ResponseWriter, r *http.Request) {
+	provider, err := a.oidc.discover(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "OIDC provider discovery failed", "error", err)
+		http.Error(w, "The identity provider is unavailable, try again later", http.StatusBadGateway)
+		return
+	}
+	flow := oidcFlow{
+		State:    randomToken(16),
+		Nonce:    randomToken(16),
+		Verifier: oauth2.GenerateVerifier(),
+		Next:     safeNext(r.URL.Query().Get("next")),
+		Expires:  time.Now().Add(oidcFlowTTL),
+	}
+	value, err := a.signer.encode(flow)
+	if err != nil {
+		http.Error(w, "Failed to start signing in: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, a.cookie(oidcFlowCookie, value, oidcFlowTTL))
+	authURL := a.oidc.oauth2Config(provider).AuthCodeURL(flow.State, oidc.Nonce(flow.Nonce), oauth2.S256ChallengeOption(flow.Verifier))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (a *Authenticator) oidcCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	var flow oidcFlow
+	c, err := r.Cookie(oidcFlowCookie)
+	if err != nil || a.signer.decode(c.Value, &flow) != nil || time.Now().After(flow.Expires) {
+		a.renderLogin(w, r, "/", "Signing in took too long, please try again.", http.StatusBadRequest)
+		return
+	}
+	a.clearCookie(w, oidcFlowCookie)
+	query := r.URL.Query()
+	if query.Get("state") != flow.State {
+		a.renderLogin(w, r, flow.Next, "Signing in failed, please try again.", http.StatusBadRequest)
+		return
+	}
+	if e := query.Get("error"); e != "" {
+		slog.WarnContext(r.Context(), "OIDC provider refused to sign in", "error", e, "description", query.Get("error_description"))
+		a.renderLogin(w, r, flow.Next, "The identity provider refused to sign you in.", http.StatusUnauthorized)
+		return
+	}
+
+	s, err := a.oidcSession(r.Context(), query.Get("code"), flow)
+	if err != nil {
+		slog.WarnContext(r.Context(), "OIDC sign-in failed", "error", err)
+		a.renderLogin(w, r, flow.Next, "Signing in failed, please try again.", http.StatusUnauthorized)
+		return
+	}
+	if s.Role == "" {
+		slog.WarnContext(r.Context(), "Refused editor sign-in: no editor role in the roles claim", "actor", s.Actor(), "claim", a.cfg.OIDC.RolesClaim)
+		a.renderLogin(w, r, flow.Next, "Your account has no role in the editor, ask an administrator for access.", http.StatusForbidden)
+		return
+	}
+	if err := a.startSession(w, s); err != nil {
+		http.Error(w, "Failed to start session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "Editor user signed in", "actor", s.Actor(), "role", s.Role, "method", "oidc")
+	http.Redirect(w, r, flow.Next, http.StatusSeeOther)
+}
+
+// oidcSession exchanges code for an ID token and returns the session of its user. The role is
+// empty when the user has none.
+func (a *Authenticator) oidcSession(ctx context.Context, code string, flow oidcFlow) (*Session, error) {
+	provider, err := a.oidc.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	token, err := a.oidc.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("no id_token in token response")
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: a.cfg.OIDC.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if idToken.Nonce != flow.Nonce {
+		return nil, fmt.Errorf("ID token nonce mismatch")
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to read ID token claims: %w", err)
+	}
+	s := &Session{Subject: idToken.Subject, Method: "oidc"}
+	for _, claim := range []string{"preferred_username", "name", "email"} {
+		if name, ok := claims[claim].(string); ok && name != "" {
+			s.Name = name
+			break
+		}
+	}
+	if role, ok := highestRole(stringsClaim(claims[a.cfg.OIDC.RolesClaim])); ok {
+		s.Role = role
+	} else {
+		s.Role = a.cfg.OIDC.DefaultRole
+	}
+	return s, nil
+}
+
+// stringsClaim reads a claim holding a string, a space-separated list or a list of strings.
+func stringsClaim(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package webauth
+
+import "fmt"
+
+// Role is what a signed-in user may do in the editor. Each role includes the ones before it.
+type Role string
+
+const (
+	RoleViewer         Role = "viewer"          // Browse players, servers, policies, history and the audit log
+	RoleMetadataEditor Role = "metadata-editor" // Also edit player and server metadata
+	RolePolicyAdmin    Role = "policy-admin"    // Also add, delete and roll back policies
+)
+
+var roleRank = map[Role]int{RoleViewer: 1, RoleMetadataEditor: 2, RolePolicyAdmin: 3}
+
+// ParseRole returns the role named s.
+func ParseRole(s string) (Role, error) {
+	if _, ok := roleRank[Role(s)]; !ok {
+		return "", fmt.Errorf("unknown role %q (expected viewer, metadata-editor or policy-admin)", s)
+	}
+	return Role(s), nil
+}
+
+// Allows reports whether r includes required.
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] > 0 && roleRank[r] >= roleRank[required]
+}
+
+// highestRole returns the highest editor role among values, ignoring other values.
+func highestRole(values []string) (Role, bool) {
+	var best Role
+	for _, v := range values {
+		if rank, ok := roleRank[Role(v)]; ok && rank > roleRank[best] {
+			best = Role(v)
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,164 @@
+package webauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Session is the signed-in user. It is kept client-side in a cookie signed with the session
+// secret, so that every replica of the editor accepts it.
+type Session struct {
+	Subject   string    `json:"sub"`
+	Name      string    `json:"name"`
+	Role      Role      `json:"role"`
+	Method    string    `json:"method"` // "oidc", "static" or "anonymous"
+	CSRFToken string    `json:"csrf"`   // Required on every state-changing request
+	Expires   time.Time `json:"exp"`
+}
+
+// DisplayName is the name shown to the user.
+func (s *Session) DisplayName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Subject
+}
+
+// Actor identifies the user in the policy history of the checker.
+func (s *Session) Actor() string {
+	if s.Name != "" && s.Name != s.Subject {
+		return s.Name + " (" + s.Method + ":" + s.Subject + ")"
+	}
+	return s.Method + ":" + s.Subject
+}
+
+type contextKey struct{}
+
+// FromContext returns the session of the request ctx belongs to.
+func FromContext(ctx context.Context) (*Session, bool) {
+	s, ok := ctx.Value(contextKey{}).(*Session)
+	return s, ok
+}
+
+// Can reports whether the user of ctx has role, for templates hiding what the user may not do.
+func Can(ctx context.Context, role Role) bool {
+	s, ok := FromContext(ctx)
+	return ok && s.Role.Allows(role)
+}
+
+// CSRFToken returns the token forms and htmx requests of the user of ctx must send.
+func CSRFToken(ctx context.Context) string {
+	if s, ok := FromContext(ctx); ok {
+		return s.CSRFToken
+	}
+	return ""
+}
+
+var errInvalidCookie = errors.New("invalid or expired cookie")
+
+// signer encodes values as base64(JSON).base64(HMAC-SHA256).
+type signer struct {
+	key []byte
+}
+
+func (s signer) encode(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload)), nil
+}
+
+func (s signer) decode(value string, v any) error {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return errInvalidCookie
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.mac(payload)) {
+		return errInvalidCookie
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return errInvalidCookie
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (s signer) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// randomToken returns n random bytes, base64url-encoded.
+func randomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand does not fail on supported platforms
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// cookie returns a cookie named name, scoped to the whole editor.
+func (a *Authenticator) cookie(name, value string, maxAge time.Duration) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   a.cfg.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func (a *Authenticator) clearCookie(w http.ResponseWriter, name string) {
+	c := a.cookie(name, "", 0)
+	c.MaxAge = -1
+	http.SetCookie(w, c)
+}
+
+// startSession signs in the user of s.
+func (a *Authenticator) startSession(w http.ResponseWriter, s *Session) error {
+	s.CSRFToken = randomToken(32)
+	s.Expires = time.Now().Add(a.cfg.SessionTTL)
+	value, err := a.signer.encode(s)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, a.cookie(sessionCookie, value, a.cfg.SessionTTL))
+	return nil
+}
+
+// session returns the valid session of r, if any.
+func (a *Authenticator) session(r *http.Request) (*Session, bool) {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil, false
+	}
+	var s Session
+	if err := a.signer.decode(c.Value, &s); err != nil || time.Now().After(s.Expires) || !s.Role.Allows(RoleViewer) {
+		return nil, false
+	}
+	// Sessions of sign-in methods since disabled are no longer valid
+	var ok bool
+	switch s.Method {
+	case "anonymous":
+		ok = a.anonymous()
+	case "static":
+		ok = a.cfg.AdminUser != "" && s.Subject == a.cfg.AdminUser
+	case "oidc":
+		ok = a.oidc != nil
+	}
+	return &s, ok
+}
@@ -0,0 +1,377 @@
+// Package webauth signs users in to the permissions-editor and enforces their editor role.
+//
+// Users sign in with OpenID Connect or, as a fallback, with a static admin account:
+//
+//   - OIDC_ISSUER, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET and OIDC_REDIRECT_URL configure the
+//     provider. The role is read from the OIDC_ROLES_CLAIM claim of the ID token ("roles" by
+//     default, a string or a list of strings); users without an editor role get
+//     OIDC_DEFAULT_ROLE, or are refused when it is empty.
+//   - OIDC_MOCK=true serves a local provider under /mock-oidc instead, on which any user name and
+//     role can be picked. It is meant for development and tests only.
+//   - EDITOR_ADMIN_USER and EDITOR_ADMIN_PASSWORD define a policy-admin that signs in with a
+//     password.
+//
+// The editor refuses to start when none is configured, unless EDITOR_AUTH=none explicitly lets
+// every visitor in as an anonymous policy-admin, as before authentication existed (for local
+// development; a warning is logged at startup).
+//
+// Sessions are kept in a cookie signed with SESSION_SECRET (a random secret when unset, which
+// signs everyone out on restart and does not work with several replicas) and last SESSION_TTL
+// (12h by default). Every state-changing request must carry the CSRF token of the session, in the
+// X-CSRF-Token header (sent by htmx, see the base template) or the csrf_token form field.
+package webauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/bafbi/minecraft-network/services/permissions-editor/webauth/mockoidc"
+)
+
+const (
+	sessionCookie    = "editor_session"
+	loginCSRFCookie  = "editor_login_csrf"
+	oidcFlowCookie   = "editor_oidc_flow"
+	csrfHeader       = "X-CSRF-Token"
+	csrfFormField    = "csrf_token"
+	mockOIDCPath     = "/mock-oidc"
+	oidcCallbackPath = "/auth/oidc/callback"
+)
+
+// Config configures authentication, see the package documentation.
+type Config struct {
+	AdminUser     string
+	AdminPassword string
+	OIDC          OIDCConfig
+	MockOIDC      bool
+	PublicURL     string // Base URL of the editor, for the mock provider
+	SessionSecret []byte
+	SessionTTL    time.Duration
+	SecureCookies bool // Only send cookies over HTTPS
+	NoAuth        bool // Every visitor is an anonymous policy-admin (EDITOR_AUTH=none)
+}
+
+// OIDCConfig configures the OpenID Connect provider.
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	RolesClaim   string
+	DefaultRole  Role // Role of users without an editor role in their token, none when empty
+}
+
+func (c OIDCConfig) enabled() bool {
+	return c.Issuer != ""
+}
+
+// ConfigFromEnv reads the configuration from the environment.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		AdminUser:     os.Getenv("EDITOR_ADMIN_USER"),
+		AdminPassword: os.Getenv("EDITOR_ADMIN_PASSWORD"),
+		OIDC: OIDCConfig{
+			Issuer:       os.Getenv("OIDC_ISSUER"),
+			ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+			RolesClaim:   os.Getenv("OIDC_ROLES_CLAIM"),
+		},
+		MockOIDC:      os.Getenv("OIDC_MOCK") == "true",
+		PublicURL:     strings.TrimSuffix(os.Getenv("EDITOR_PUBLIC_URL"), "/"),
+		SessionSecret: []byte(os.Getenv("SESSION_SECRET")),
+		SessionTTL:    12 * time.Hour,
+	}
+	switch mode := os.Getenv("EDITOR_AUTH"); mode {
+	case "":
+	case "none":
+		cfg.NoAuth = true
+	default:
+		return cfg, fmt.Errorf("invalid EDITOR_AUTH %q, only \"none\" is supported", mode)
+	}
+	if (cfg.AdminUser == "") != (cfg.AdminPassword == "") {
+		return cfg, errors.New("EDITOR_ADMIN_USER and EDITOR_ADMIN_PASSWORD must be set together")
+	}
+	if role := os.Getenv("OIDC_DEFAULT_ROLE"); role != "" {
+		r, err := ParseRole(role)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid OIDC_DEFAULT_ROLE: %w", err)
+		}
+		cfg.OIDC.DefaultRole = r
+	}
+	if ttl := os.Getenv("SESSION_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("invalid SESSION_TTL %q", ttl)
+		}
+		cfg.SessionTTL = d
+	}
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = "http://localhost:8080"
+	}
+	if cfg.OIDC.RolesClaim == "" {
+		cfg.OIDC.RolesClaim = "roles"
+	}
+	if cfg.MockOIDC {
+		if cfg.OIDC.enabled() {
+			return cfg, errors.New("OIDC_MOCK cannot be combined with OIDC_ISSUER")
+		}
+		cfg.OIDC.Issuer = cfg.PublicURL + mockOIDCPath
+		cfg.OIDC.ClientID = "permissions-editor"
+		cfg.OIDC.ClientSecret = "mock-secret"
+	}
+	if cfg.OIDC.enabled() {
+		if cfg.OIDC.ClientID == "" {
+			return cfg, errors.New("OIDC_CLIENT_ID is required with OIDC_ISSUER")
+		}
+		if cfg.OIDC.RedirectURL == "" {
+			cfg.OIDC.RedirectURL = cfg.PublicURL + oidcCallbackPath
+		}
+	}
+	cfg.SecureCookies = strings.HasPrefix(cfg.PublicURL, "https://")
+	return cfg, nil
+}
+
+// LoginPage is what the login page shows.
+type LoginPage struct {
+	Next        string // Where to go once signed in
+	Error       string
+	CSRFToken   string
+	PasswordURL string // Where the password form posts, empty without a static admin
+	OIDCURL     string // Where to start signing in with OIDC, empty without a provider
+}
+
+// Authenticator signs users in and enforces their role.
+type Authenticator struct {
+	cfg       Config
+	signer    signer
+	oidc      *oidcClient
+	mock      *mockoidc.Provider
+	loginPage func(LoginPage) templ.Component
+}
+
+// New returns an authenticator rendering its login page with loginPage.
+func New(cfg Config, loginPage func(LoginPage) templ.Component) (*Authenticator, error) {
+	if len(cfg.SessionSecret) == 0 {
+		slog.Warn("SESSION_SECRET is not set; using a random secret, sessions will not survive restarts")
+		cfg.SessionSecret = []byte(randomToken(32))
+	}
+	a := &Authenticator{cfg: cfg, signer: signer{key: cfg.SessionSecret}, loginPage: loginPage}
+	if cfg.MockOIDC {
+		mock, err := mockoidc.New(cfg.OIDC.Issuer, cfg.OIDC.ClientID, cfg.OIDC.ClientSecret, []string{
+			string(RoleViewer), string(RoleMetadataEditor), string(RolePolicyAdmin),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to start the mock OIDC provider: %w", err)
+		}
+		a.mock = mock
+		slog.Warn("OIDC_MOCK is enabled; anyone can sign in with any role", "issuer", cfg.OIDC.Issuer)
+	}
+	if cfg.OIDC.enabled() {
+		a.oidc = newOIDCClient(cfg.OIDC)
+	}
+	signIn := a.oidc != nil || cfg.AdminUser != ""
+	switch {
+	case cfg.NoAuth && signIn:
+		return nil, errors.New("EDITOR_AUTH=none cannot be combined with an OIDC provider or EDITOR_ADMIN_USER")
+	case cfg.NoAuth:
+		slog.Warn("EDITOR_AUTH=none; every visitor of the editor is a policy-admin")
+	case !signIn:
+		return nil, errors.New("no way to sign in is configured: set OIDC_ISSUER (or OIDC_MOCK=true) or EDITOR_ADMIN_USER and EDITOR_ADMIN_PASSWORD, or EDITOR_AUTH=none to let every visitor in as a policy-admin")
+	}
+	return a, nil
+}
+
+// anonymous reports whether visitors are let in without signing in (EDITOR_AUTH=none).
+func (a *Authenticator) anonymous() bool {
+	return a.cfg.NoAuth
+}
+
+// Routes mounts the sign-in and sign-out endpoints on r, and the mock provider when enabled.
+// They must not be behind Middleware, which protects the other routes.
+func (a *Authenticator) Routes(r chi.Router) {
+	r.Get("/login", a.loginHandler)
+	r.Post("/login", a.passwordLoginHandler)
+	r.With(a.Middleware).Post("/logout", a.logoutHandler)
+	if a.oidc != nil {
+		r.Get("/auth/oidc/login", a.oidcLoginHandler)
+		r.Get(oidcCallbackPath, a.oidcCallbackHandler)
+	}
+	if a.mock != nil {
+		r.Mount(mockOIDCPath, http.StripPrefix(mockOIDCPath, a.mock))
+	}
+}
+
+// Middleware adds the session of the request to its context and rejects state-changing requests
+// without its CSRF token. Requests without a session go through, for Require to turn away.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, ok := a.session(r)
+		if !ok && a.anonymous() {
+			s = &Session{Subject: "anonymous", Role: RolePolicyAdmin, Method: "anonymous"}
+			if err := a.startSession(w, s); err != nil {
+				http.Error(w, "Failed to start session: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			ok = true
+		}
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !safeMethod(r.Method) && !validCSRFToken(r, s.CSRFToken) {
+			http.Error(w, "Missing or invalid CSRF token, reload the page", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, s)))
+	})
+}
+
+// Require turns away requests of users without role: to the login page when signed out, with
+// 403 Forbidden otherwise.
+func (a *Authenticator) Require(role Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s, ok := FromContext(r.Context())
+			if !ok {
+				loginURL := "/login?next=" + url.QueryEscape(nextURL(r))
+				if r.Header.Get("HX-Request") == "true" {
+					// htmx would swap the login page into the current one
+					w.Header().Set("HX-Redirect", loginURL)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				http.Redirect(w, r, loginURL, http.StatusSeeOther)
+				return
+			}
+			if !s.Role.Allows(role) {
+				http.Error(w, fmt.Sprintf("Forbidden: this requires the %s role, you are %s", role, s.Role), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ActorInterceptor names the signed-in user as the "x-actor" of calls to the permissions-checker,
+// which records it as the author of policy changes.
+func ActorInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if s, ok := FromContext(ctx); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-actor", s.Actor())
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (a *Authenticator) loginHandler(w http.ResponseWriter, r *http.Request) {
+	next := safeNext(r.URL.Query().Get("next"))
+	if _, ok := a.session(r); ok || a.anonymous() {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+	a.renderLogin(w, r, next, "", http.StatusOK)
+}
+
+func (a *Authenticator) renderLogin(w http.ResponseWriter, r *http.Request, next, message string, status int) {
+	token := randomToken(32)
+	http.SetCookie(w, a.cookie(loginCSRFCookie, token, time.Hour))
+	page := LoginPage{Next: next, Error: message, CSRFToken: token}
+	if a.cfg.AdminUser != "" {
+		page.PasswordURL = "/login"
+	}
+	if a.oidc != nil {
+		page.OIDCURL = "/auth/oidc/login?next=" + url.QueryEscape(next)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := a.loginPage(page).Render(r.Context(), w); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render login page", "error", err)
+	}
+}
+
+func (a *Authenticator) passwordLoginHandler(w http.ResponseWriter, r *http.Request) {
+	next := safeNext(r.FormValue("next"))
+	// Signed-out users have no session token: the form carries the token of its login cookie
+	c, err := r.Cookie(loginCSRFCookie)
+	if err != nil || !validCSRFToken(r, c.Value) {
+		a.renderLogin(w, r, next, "Your login form expired, please try again.", http.StatusForbidden)
+		return
+	}
+	if a.cfg.AdminUser == "" {
+		http.Error(w, "Password sign-in is not enabled", http.StatusNotFound)
+		return
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(r.FormValue("username")), []byte(a.cfg.AdminUser)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(r.FormValue("password")), []byte(a.cfg.AdminPassword)) == 1
+	if !userOK || !passwordOK {
+		slog.WarnContext(r.Context(), "Failed editor sign-in", "user", r.FormValue("username"), "remote_addr", r.RemoteAddr)
+		a.renderLogin(w, r, next, "Invalid user name or password.", http.StatusUnauthorized)
+		return
+	}
+	a.clearCookie(w, loginCSRFCookie)
+	s := &Session{Subject: a.cfg.AdminUser, Name: a.cfg.AdminUser, Role: RolePolicyAdmin, Method: "static"}
+	if err := a.startSession(w, s); err != nil {
+		http.Error(w, "Failed to start session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "Editor user signed in", "actor", s.Actor(), "role", s.Role, "method", "password")
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+func (a *Authenticator) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	a.clearCookie(w, sessionCookie)
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/login")
+		return
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func validCSRFToken(r *http.Request, expected string) bool {
+	token := r.Header.Get(csrfHeader)
+	if token == "" {
+		token = r.FormValue(csrfFormField)
+	}
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// nextURL is where to come back to after signing in. htmx requests fetch fragments, so they
+// come back to the page they were made from.
+func nextURL(r *http.Request) string {
+	if r.Header.Get("HX-Request") == "true" {
+		if current, err := url.Parse(r.Header.Get("HX-Current-URL")); err == nil && current.Path != "" {
+			return current.RequestURI()
+		}
+		return "/"
+	}
+	if r.Method != http.MethodGet {
+		return "/"
+	}
+	return r.URL.RequestURI()
+}
+
+// safeNext keeps redirects after sign-in on the editor.
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
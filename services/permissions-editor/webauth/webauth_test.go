@@ -0,0 +1,316 @@
+package webauth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
+)
+
+// testLoginPage renders the CSRF token of the login form, for tests to submit it.
+func testLoginPage(page LoginPage) templ.Component {
+	return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, page.CSRFToken)
+		return err
+	})
+}
+
+// newTestRouter protects stub handlers the way the editor protects its routes. GET /policies
+// writes the CSRF token of the session.
+func newTestRouter(a *Authenticator) http.Handler {
+	ok := func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, CSRFToken(r.Context())) }
+	r := chi.NewRouter()
+	a.Routes(r)
+	r.Group(func(r chi.Router) {
+		r.Use(a.Middleware)
+		r.Group(func(r chi.Router) {
+			r.Use(a.Require(RoleViewer))
+			r.Get("/policies", ok)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(a.Require(RoleMetadataEditor))
+			r.Post("/player/{uuid}", ok)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(a.Require(RolePolicyAdmin))
+			r.Post("/policies", ok)
+		})
+	})
+	return r
+}
+
+func newStaticAuthenticator(t *testing.T) *Authenticator {
+	t.Helper()
+	a, err := New(Config{AdminUser: "admin", AdminPassword: "secret", SessionSecret: []byte("test-secret"), SessionTTL: time.Hour}, testLoginPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// signIn starts a session of the static admin and returns its cookie and CSRF token.
+func signIn(t *testing.T, a *Authenticator) (*http.Cookie, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s := &Session{Subject: "admin", Name: "admin", Role: RolePolicyAdmin, Method: "static"}
+	if err := a.startSession(rec, s); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Result().Cookies()[0], s.CSRFToken
+}
+
+func TestNewRequiresSignIn(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"no sign-in method", Config{}, true},
+		{"static admin", Config{AdminUser: "admin", AdminPassword: "secret"}, false},
+		{"OIDC provider", Config{OIDC: OIDCConfig{Issuer: "https://idp.example", ClientID: "editor"}}, false},
+		{"explicitly without authentication", Config{NoAuth: true}, false},
+		{"without authentication and a static admin", Config{NoAuth: true, AdminUser: "admin", AdminPassword: "secret"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg, testLoginPage)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{"admin user without password", map[string]string{"EDITOR_ADMIN_USER": "admin"}, true},
+		{"unknown auth mode", map[string]string{"EDITOR_AUTH": "basic"}, true},
+		{"unknown default role", map[string]string{"OIDC_ISSUER": "https://idp.example", "OIDC_CLIENT_ID": "editor", "OIDC_DEFAULT_ROLE": "owner"}, true},
+		{"issuer without client", map[string]string{"OIDC_ISSUER": "https://idp.example"}, true},
+		{"mock with an issuer", map[string]string{"OIDC_MOCK": "true", "OIDC_ISSUER": "https://idp.example"}, true},
+		{"static admin", map[string]string{"EDITOR_ADMIN_USER": "admin", "EDITOR_ADMIN_PASSWORD": "secret"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"EDITOR_ADMIN_USER", "EDITOR_ADMIN_PASSWORD", "EDITOR_AUTH", "OIDC_ISSUER", "OIDC_CLIENT_ID", "OIDC_DEFAULT_ROLE", "OIDC_MOCK"} {
+				t.Setenv(name, tt.env[name])
+			}
+			_, err := ConfigFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConfigFromEnv error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCSRFMiddleware(t *testing.T) {
+	a := newStaticAuthenticator(t)
+	router := newTestRouter(a)
+	session, token := signIn(t, a)
+
+	tests := []struct {
+		name       string
+		method     string
+		header     string
+		form       string
+		wantStatus int
+	}{
+		{"safe method without token", http.MethodGet, "", "", http.StatusOK},
+		{"missing token", http.MethodPost, "", "", http.StatusForbidden},
+		{"mismatched header", http.MethodPost, "not-the-token", "", http.StatusForbidden},
+		{"mismatched form field", http.MethodPost, "", "not-the-token", http.StatusForbidden},
+		{"header", http.MethodPost, token, "", http.StatusOK},
+		{"form field", http.MethodPost, "", token, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			if tt.form != "" {
+				form.Set(csrfFormField, tt.form)
+			}
+			req := httptest.NewRequest(tt.method, "/policies", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.header != "" {
+				req.Header.Set(csrfHeader, tt.header)
+			}
+			req.AddCookie(session)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPasswordLoginCSRF(t *testing.T) {
+	a := newStaticAuthenticator(t)
+	router := newTestRouter(a)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	var loginCookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == loginCSRFCookie {
+			loginCookie = c
+		}
+	}
+	if loginCookie == nil || loginCookie.Value != rec.Body.String() {
+		t.Fatalf("login page did not set its CSRF token in the %s cookie", loginCSRFCookie)
+	}
+
+	tests := []struct {
+		name       string
+		cookie     bool
+		token      string
+		password   string
+		wantStatus int
+	}{
+		{"without the login cookie", false, loginCookie.Value, "secret", http.StatusForbidden},
+		{"missing token", true, "", "secret", http.StatusForbidden},
+		{"mismatched token", true, "not-the-token", "secret", http.StatusForbidden},
+		{"wrong password", true, loginCookie.Value, "wrong", http.StatusUnauthorized},
+		{"signed in", true, loginCookie.Value, "secret", http.StatusSeeOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"username": {"admin"}, "password": {tt.password}, csrfFormField: {tt.token}, "next": {"/policies"}}
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.cookie {
+				req.AddCookie(loginCookie)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			signedIn := false
+			for _, c := range rec.Result().Cookies() {
+				signedIn = signedIn || c.Name == sessionCookie && c.Value != ""
+			}
+			if signedIn != (tt.wantStatus == http.StatusSeeOther) {
+				t.Errorf("session cookie set = %v", signedIn)
+			}
+		})
+	}
+}
+
+// TestRoleRoutes signs users in with the mock OIDC provider and checks the routes their roles
+// claim gives access to.
+func TestRoleRoutes(t *testing.T) {
+	srv := httptest.NewUnstartedServer(nil)
+	t.Setenv("OIDC_MOCK", "true")
+	t.Setenv("EDITOR_PUBLIC_URL", "http://"+srv.Listener.Addr().String())
+	t.Setenv("SESSION_SECRET", "test-secret")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(cfg, testLoginPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Config.Handler = newTestRouter(a)
+	srv.Start()
+	defer srv.Close()
+
+	tests := []struct {
+		role                                string
+		wantBrowse, wantMetadata, wantAdmin int
+	}{
+		{string(RoleViewer), http.StatusOK, http.StatusForbidden, http.StatusForbidden},
+		{string(RoleMetadataEditor), http.StatusOK, http.StatusOK, http.StatusForbidden},
+		{string(RolePolicyAdmin), http.StatusOK, http.StatusOK, http.StatusOK},
+		{"", http.StatusSeeOther, http.StatusSeeOther, http.StatusSeeOther}, // No editor role: refused
+	}
+	for _, tt := range tests {
+		t.Run("role "+tt.role, func(t *testing.T) {
+			jar, _ := cookiejar.New(nil)
+			client := &http.Client{Jar: jar, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+			get := func(u string) *http.Response {
+				t.Helper()
+				resp, err := client.Get(u)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				return resp
+			}
+
+			// Sign in: the provider redirects back to the editor once a user and role are picked
+			authorize := get(srv.URL + "/auth/oidc/login?next=/policies").Header.Get("Location")
+			callback := get(authorize + "&" + url.Values{"username": {"alice"}, "role": {tt.role}}.Encode()).Header.Get("Location")
+			signedIn := get(callback)
+			if tt.role == "" {
+				if signedIn.StatusCode != http.StatusForbidden {
+					t.Fatalf("sign-in without a role: status = %d, want %d", signedIn.StatusCode, http.StatusForbidden)
+				}
+			} else if signedIn.StatusCode != http.StatusSeeOther || signedIn.Header.Get("Location") != "/policies" {
+				t.Fatalf("sign-in: status = %d to %q, want a redirect to /policies", signedIn.StatusCode, signedIn.Header.Get("Location"))
+			}
+
+			resp, err := client.Get(srv.URL + "/policies")
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantBrowse {
+				t.Errorf("GET /policies: status = %d, want %d", resp.StatusCode, tt.wantBrowse)
+			}
+			var token string
+			if resp.StatusCode == http.StatusOK {
+				token = string(body)
+			}
+
+			post := func(path string) int {
+				t.Helper()
+				req, _ := http.NewRequest(http.MethodPost, srv.URL+path, nil)
+				req.Header.Set(csrfHeader, token)
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				return resp.StatusCode
+			}
+			if got := post("/player/00000000-0000-0000-0000-000000000001"); got != tt.wantMetadata {
+				t.Errorf("POST /player: status = %d, want %d", got, tt.wantMetadata)
+			}
+			if got := post("/policies"); got != tt.wantAdmin {
+				t.Errorf("POST /policies: status = %d, want %d", got, tt.wantAdmin)
+			}
+		})
+	}
+}
+
+func TestHighestRole(t *testing.T) {
+	tests := []struct {
+		claim any
+		want  Role
+	}{
+		{[]any{"staff", "viewer", "policy-admin", "metadata-editor"}, RolePolicyAdmin},
+		{"staff metadata-editor", RoleMetadataEditor},
+		{[]any{"staff", 42}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		got, ok := highestRole(stringsClaim(tt.claim))
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("highestRole(%v) = %q, %v; want %q", tt.claim, got, ok, tt.want)
+		}
+	}
+}
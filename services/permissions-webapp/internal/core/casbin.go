@@ -2,15 +2,19 @@ package core
 
 import (
 	"fmt"
+	"log/slog"
 	// "os"
 
+	"github.com/bafbi/minecraft-network/pkg/policywatch"
 	"github.com/bafbi/minecraft-network/services/permissions-webapp/internal/config" // Your config package
 	"github.com/casbin/casbin/v2"
 	redisadapter "github.com/casbin/redis-adapter/v3"
 	// "github.com/go-logr/logr" // If you use a logger here
 )
 
-var Enforcer *casbin.Enforcer
+// Enforcer is shared by the HTTP handlers and the policy watcher, which applies the changes of
+// other replicas from its subscription, so it must be synchronized.
+var Enforcer *casbin.SyncedEnforcer
 
 func InitCasbin(cfg *config.AppConfig /*, logger logr.Logger*/) error {
 	// modelPath := os.Getenv("CASBIN_MODEL_PATH_WEBAPP") // Or get from cfg
@@ -34,7 +38,7 @@ func InitCasbin(cfg *config.AppConfig /*, logger logr.Logger*/) error {
 	// This model file should be the same one used by your Gate proxy.
 	// You can mount it via ConfigMap to the webapp pod as well.
 	var err error
-	Enforcer, err = casbin.NewEnforcer(cfg.CasbinModelPath, adapter)
+	Enforcer, err = casbin.NewSyncedEnforcer(cfg.CasbinModelPath, adapter)
 	if err != nil {
 		return fmt.Errorf("webapp: failed to create Casbin enforcer: %w", err)
 	}
@@ -44,5 +48,21 @@ func InitCasbin(cfg *config.AppConfig /*, logger logr.Logger*/) error {
 		return fmt.Errorf("webapp: failed to load Casbin policies: %w", err)
 	}
 	// logger.Info("Webapp Casbin enforcer initialized and policies loaded")
+
+	// Changes made through Enforcer are saved by the adapter and published to the other
+	// replicas and the proxy; their changes are applied here as they arrive.
+	if nc == nil {
+		return fmt.Errorf("webapp: NATS must be initialized before Casbin")
+	}
+	policyWatcher, err = policywatch.NewNATSWatcher(nc, CasbinPolicyUpdateSubject, policywatch.Options{Logger: slog.Default()})
+	if err != nil {
+		return fmt.Errorf("webapp: failed to create Casbin policy watcher: %w", err)
+	}
+	if err = Enforcer.SetWatcher(policyWatcher); err != nil {
+		return fmt.Errorf("webapp: failed to set Casbin policy watcher: %w", err)
+	}
+	if err = policyWatcher.SetUpdateCallback(policywatch.DefaultUpdateCallback(Enforcer, slog.Default())); err != nil {
+		return fmt.Errorf("webapp: failed to set Casbin policy watcher callback: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Time-bound rules are recorded as grants in a NATS KV bucket shared with the proxy and the
+// permissions-checker, whose reaper removes the rules once they expire
+// (see services/permissions-checker/grants). Same bucket and format as the proxy.
+const grantsBucket = "permission_grants"
+
+var grantsKV nats.KeyValue
+
+// Grant is the expiry of a single rule, in the format of the permissions-checker grants package.
+type Grant struct {
+	Subject   string    `json:"subject"`
+	Sec       string    `json:"sec"`
+	Ptype     string    `json:"ptype"`
+	Rule      []string  `json:"rule"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InitGrants binds to the grants bucket, creating it if it does not exist. NATS must be initialized.
+func InitGrants() error {
+	if nc == nil {
+		return fmt.Errorf("webapp: NATS must be initialized before grants")
+	}
+	js, err := nc.JetStream()
+	if err != nil {
+		return fmt.Errorf("webapp: failed to get JetStream context: %w", err)
+	}
+	kv, err := js.KeyValue(grantsBucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		slog.Info("NATS KV bucket not found, attempting to create", "bucket", grantsBucket)
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: grantsBucket, Description: "Expiry of time-bound permissions"})
+	}
+	if err != nil {
+		return fmt.Errorf("webapp: failed to get NATS KV bucket '%s': %w", grantsBucket, err)
+	}
+	grantsKV = kv
+	return nil
+}
+
+// GrantKey identifies the grant of a rule of this store.
+func GrantKey(sec, ptype string, rule []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(append([]string{CasbinPolicyUpdateSubject, sec, ptype}, rule...), "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// SetGrantExpiry makes a rule of this store expire at expiresAt.
+func SetGrantExpiry(sec, ptype string, rule []string, expiresAt time.Time) error {
+	if grantsKV == nil {
+		return errors.New("time-bound permissions are not available")
+	}
+	data, err := json.Marshal(Grant{
+		Subject:   CasbinPolicyUpdateSubject,
+		Sec:       sec,
+		Ptype:     ptype,
+		Rule:      rule,
+		ExpiresAt: expiresAt.UTC(),
+		Author:    "permissions-webapp",
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode grant: %w", err)
+	}
+	if _, err := grantsKV.Put(GrantKey(sec, ptype, rule), data); err != nil {
+		return fmt.Errorf("failed to store grant: %w", err)
+	}
+	return nil
+}
+
+// ClearGrantExpiry makes a rule of this store permanent. It is not an error if the rule has no expiry.
+func ClearGrantExpiry(sec, ptype string, rule []string) error {
+	if grantsKV == nil {
+		return nil
+	}
+	err := grantsKV.Delete(GrantKey(sec, ptype, rule))
+	if err != nil && !errors.Is(err, nats.ErrKeyNotFound) {
+		return fmt.Errorf("failed to delete grant: %w", err)
+	}
+	return nil
+}
+
+// GrantExpiries returns the expiry of the time-bound rules of this store by GrantKey.
+func GrantExpiries() (map[string]time.Time, error) {
+	expiries := make(map[string]time.Time)
+	if grantsKV == nil {
+		return expiries, nil
+	}
+	keys, err := grantsKV.Keys()
+	if errors.Is(err, nats.ErrNoKeysFound) {
+		return expiries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list grants: %w", err)
+	}
+	for _, key := range keys {
+		entry, err := grantsKV.Get(key)
+		if err != nil {
+			continue // Deleted since listed
+		}
+		var g Grant
+		if err := json.Unmarshal(entry.Value(), &g); err != nil || g.Subject != CasbinPolicyUpdateSubject {
+			continue
+		}
+		expiries[key] = g.ExpiresAt
+	}
+	return expiries, nil
+}
+
+// ParseGrantDuration parses the duration of a time-bound grant, e.g. "30m", "2h" or "7d".
+func ParseGrantDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q (e.g. 30m, 2h or 7d)", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Group membership rules of the permissions model (see deployment/network/common/permissions_model.conf
+// and servers/proxy_gate/plugins/network/permissions/groups.go):
+// "g" assigns a member to a group, "g2" does so within a server group (domain), and "p2" rules
+// make every player whose metadata matches an expression a member of a group.
+const (
+	GroupPType          = "g"
+	DomainGroupPType    = "g2"
+	MembershipRulePType = "p2"
+	// AnyDomain is the domain of membership rules that apply on every server group.
+	AnyDomain = "*"
+	// GroupPrefix is the prefix of group names, e.g. "group:vip".
+	GroupPrefix = "group:"
+)
+
+// HasPolicyType reports whether the loaded model defines the policy type ptype in section sec ("p" or "g").
+func HasPolicyType(sec, ptype string) bool {
+	if Enforcer == nil {
+		return false
+	}
+	lock := Enforcer.GetLock()
+	lock.RLock()
+	defer lock.RUnlock()
+	_, ok := Enforcer.GetModel()[sec][ptype]
+	return ok
+}
+
+// ValidateGroupAssignment checks a group assignment (member, group[, domain]) before it is stored
+// and returns the problems keyed by form field, or nil when it is valid.
+func ValidateGroupAssignment(member, group, domain string) map[string]string {
+	errs := make(map[string]string)
+	if strings.TrimSpace(member) == "" {
+		errs["member"] = "is required (a player UUID or a group)"
+	}
+	if err := validateGroupName(group); err != nil {
+		errs["group"] = err.Error()
+	} else if member == group {
+		errs["member"] = "a group cannot be a member of itself"
+	}
+	if domain == AnyDomain {
+		errs["domain"] = "leave empty to assign the group on every server group"
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ValidateMembershipRule checks a dynamic membership rule (member_eval_logic, grp, dom) before it
+// is stored and returns the problems keyed by form field, or nil when it is valid. Rules cannot
+// use inGroup, as membership is what they define.
+func ValidateMembershipRule(logic, group, domain string) map[string]string {
+	errs := make(map[string]string)
+	if err := validateExpression(logic, subjectVariables, metadataFunctionNames); err != nil {
+		errs["logic"] = err.Error()
+	}
+	if err := validateGroupName(group); err != nil {
+		errs["group"] = err.Error()
+	}
+	if strings.TrimSpace(domain) == "" {
+		errs["domain"] = fmt.Sprintf("is required (use %q for every server group)", AnyDomain)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateGroupName(group string) error {
+	if !strings.HasPrefix(group, GroupPrefix) || len(group) == len(GroupPrefix) {
+		return fmt.Errorf("must be a group name starting with %q, e.g. %svip", GroupPrefix, GroupPrefix)
+	}
+	return nil
+}
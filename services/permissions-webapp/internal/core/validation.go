@@ -0,0 +1,86 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/casbin/govaluate"
+)
+
+// The variables and functions below mirror what the proxy passes to the policy expressions
+// (servers/proxy_gate/plugins/network/permissions/custom_functions.go); keep them in sync.
+
+var metadataFunctionNames = []string{"label", "annotation", "hasLabel", "hasAnnotation"}
+
+// subjectVariables are available to sub_eval_logic, evaluated against the requesting player.
+var subjectVariables = []string{"uuid", "labels", "annotations"}
+
+// subjectFunctions are available to sub_eval_logic in addition to the metadata functions.
+var subjectFunctions = []string{"inGroup", "inGroupIn"}
+
+// objectVariables are available to obj_eval_logic, evaluated against the object (e.g. "server:lobby").
+var objectVariables = []string{"type", "name", "r_sub_uuid", "labels", "annotations"}
+
+// objectFunctions are available to obj_eval_logic in addition to the metadata functions.
+var objectFunctions = []string{"subjectInGroup"}
+
+// ValidatePolicy checks a policy (p = sub_eval_logic, obj_eval_logic, act, eft) before it is
+// stored and returns the problems keyed by form field, or nil when the policy is valid.
+func ValidatePolicy(subLogic, objLogic, action, effect string) map[string]string {
+	errs := make(map[string]string)
+	if err := validateExpression(subLogic, subjectVariables, slices.Concat(metadataFunctionNames, subjectFunctions)); err != nil {
+		errs["sub_logic"] = err.Error()
+	}
+	if err := validateExpression(objLogic, objectVariables, slices.Concat(metadataFunctionNames, objectFunctions)); err != nil {
+		errs["obj_logic"] = err.Error()
+	}
+	if strings.TrimSpace(action) == "" {
+		errs["action"] = `is required (use "*" to match every action)`
+	}
+	if effect != "allow" && effect != "deny" {
+		errs["effect"] = fmt.Sprintf(`must be "allow" or "deny", got %q`, effect)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateExpression checks that expr parses and only references the given variables and functions.
+func validateExpression(expr string, variables, functionNames []string) error {
+	if strings.TrimSpace(expr) == "" {
+		return errors.New(`is required (use "true" or "*" to match everything)`)
+	}
+	if expr == "*" {
+		return nil
+	}
+
+	functions := make(map[string]govaluate.ExpressionFunction, len(functionNames))
+	for _, name := range functionNames {
+		functions[name] = func(args ...interface{}) (interface{}, error) { return nil, nil }
+	}
+	parsed, err := govaluate.NewEvaluableExpressionWithFunctions(expr, functions)
+	if err != nil {
+		return fmt.Errorf("invalid expression: %v (available functions: %s)", err, strings.Join(slices.Sorted(slices.Values(functionNames)), ", "))
+	}
+
+	for _, token := range parsed.Tokens() {
+		var name string
+		switch token.Kind {
+		case govaluate.VARIABLE:
+			name, _ = token.Value.(string)
+		case govaluate.ACCESSOR:
+			if path, ok := token.Value.([]string); ok && len(path) > 0 {
+				name = path[0]
+			}
+		default:
+			continue
+		}
+		if !slices.Contains(variables, name) {
+			return fmt.Errorf("unknown variable %q (expected one of %s)", name, strings.Join(variables, ", "))
+		}
+	}
+	return nil
+}
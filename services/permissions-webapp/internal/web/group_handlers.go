@@ -0,0 +1,202 @@
+package web
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/bafbi/minecraft-network/services/permissions-webapp/internal/core"
+	"github.com/bafbi/minecraft-network/services/permissions-webapp/internal/web/components"
+	"github.com/bafbi/minecraft-network/services/permissions-webapp/internal/web/views"
+)
+
+func handleListGroups(w http.ResponseWriter, r *http.Request) {
+	if core.Enforcer == nil {
+		http.Error(w, "Casbin enforcer not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	expiries, err := core.GrantExpiries()
+	if err != nil {
+		// Assignments are still listed, as permanent ones
+		slog.ErrorContext(r.Context(), "Failed to list group assignment expiries", "error", err)
+	}
+
+	var assignments []components.GroupAssignment
+	for _, ptype := range []string{core.GroupPType, core.DomainGroupPType} {
+		if !core.HasPolicyType("g", ptype) {
+			continue
+		}
+		rules, err := core.Enforcer.GetNamedGroupingPolicy(ptype)
+		if err != nil {
+			http.Error(w, "Failed to get group assignments: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, g := range rules {
+			if len(g) < 2 {
+				continue
+			}
+			a := components.GroupAssignment{Member: g[0], Group: g[1], ExpiresAt: expiries[core.GrantKey("g", ptype, g)]}
+			if len(g) > 2 {
+				a.Domain = g[2]
+			}
+			assignments = append(assignments, a)
+		}
+	}
+
+	var rules []components.MembershipRule
+	if core.HasPolicyType("p", core.MembershipRulePType) {
+		raw, err := core.Enforcer.GetNamedPolicy(core.MembershipRulePType)
+		if err != nil {
+			http.Error(w, "Failed to get membership rules: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, p := range raw {
+			if len(p) >= 3 {
+				rules = append(rules, components.MembershipRule{Logic: p[0], Group: p[1], Domain: p[2]})
+			}
+		}
+	}
+
+	views.GroupsPage(assignments, rules).Render(r.Context(), w)
+}
+
+func handleAddGroupAssignment(w http.ResponseWriter, r *http.Request) {
+	if core.Enforcer == nil {
+		http.Error(w, "Casbin enforcer not initialized", http.StatusInternalServerError)
+		return
+	}
+	a := components.GroupAssignment{Member: r.FormValue("member"), Group: r.FormValue("group"), Domain: r.FormValue("domain"), Duration: r.FormValue("duration")}
+
+	errs := core.ValidateGroupAssignment(a.Member, a.Group, a.Domain)
+	if a.Duration != "" {
+		if d, err := core.ParseGrantDuration(a.Duration); err != nil {
+			if errs == nil {
+				errs = make(map[string]string)
+			}
+			errs["duration"] = err.Error()
+		} else {
+			a.ExpiresAt = time.Now().Add(d)
+		}
+	}
+	ptype, rule := groupAssignmentRule(a)
+	if errs == nil && !core.HasPolicyType("g", ptype) {
+		errs = map[string]string{"": fmt.Sprintf("the permissions model has no %q role definition", ptype)}
+	}
+	if errs != nil {
+		retargetForm(w, "#group-assignment-form-container")
+		views.AddGroupAssignmentForm(a, errs).Render(r.Context(), w)
+		return
+	}
+
+	// The expiry is recorded first, so that a time-bound assignment is never stored without it.
+	var err error
+	if a.ExpiresAt.IsZero() {
+		err = core.ClearGrantExpiry("g", ptype, rule)
+	} else {
+		err = core.SetGrantExpiry("g", ptype, rule, a.ExpiresAt)
+	}
+	if err != nil {
+		retargetForm(w, "#group-assignment-form-container")
+		views.AddGroupAssignmentForm(a, map[string]string{"duration": err.Error()}).Render(r.Context(), w)
+		return
+	}
+
+	// The adapter persists the rule and the watcher notifies the other replicas and the proxy.
+	added, err := core.Enforcer.AddNamedGroupingPolicy(ptype, rule)
+	if err != nil {
+		http.Error(w, "Failed to add group assignment: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !added {
+		retargetForm(w, "#group-assignment-form-container")
+		views.AddGroupAssignmentForm(a, map[string]string{"": "This assignment already exists; its expiry was updated."}).Render(r.Context(), w)
+		return
+	}
+	components.GroupAssignmentRow(a).Render(r.Context(), w)
+}
+
+func handleRemoveGroupAssignment(w http.ResponseWriter, r *http.Request) {
+	if core.Enforcer == nil {
+		http.Error(w, "Casbin enforcer not initialized", http.StatusInternalServerError)
+		return
+	}
+	a := components.GroupAssignment{Member: r.FormValue("member"), Group: r.FormValue("group"), Domain: r.FormValue("domain")}
+	ptype, rule := groupAssignmentRule(a)
+	if !core.HasPolicyType("g", ptype) {
+		http.Error(w, fmt.Sprintf("The permissions model has no %q role definition", ptype), http.StatusBadRequest)
+		return
+	}
+	if _, err := core.Enforcer.RemoveNamedGroupingPolicy(ptype, rule); err != nil {
+		http.Error(w, "Failed to remove group assignment: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := core.ClearGrantExpiry("g", ptype, rule); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to delete group assignment expiry", "ptype", ptype, "rule", rule, "error", err)
+	}
+	// An empty 200 OK removes the row (hx-target="closest tr" hx-swap="outerHTML").
+	w.WriteHeader(http.StatusOK)
+}
+
+func handleAddMembershipRule(w http.ResponseWriter, r *http.Request) {
+	if core.Enforcer == nil {
+		http.Error(w, "Casbin enforcer not initialized", http.StatusInternalServerError)
+		return
+	}
+	m := components.MembershipRule{Logic: r.FormValue("logic"), Group: r.FormValue("group"), Domain: r.FormValue("domain")}
+
+	errs := core.ValidateMembershipRule(m.Logic, m.Group, m.Domain)
+	if errs == nil && !core.HasPolicyType("p", core.MembershipRulePType) {
+		errs = map[string]string{"": fmt.Sprintf("the permissions model has no %q policy definition", core.MembershipRulePType)}
+	}
+	if errs != nil {
+		retargetForm(w, "#membership-rule-form-container")
+		views.AddMembershipRuleForm(m, errs).Render(r.Context(), w)
+		return
+	}
+
+	added, err := core.Enforcer.AddNamedPolicy(core.MembershipRulePType, m.Logic, m.Group, m.Domain)
+	if err != nil {
+		http.Error(w, "Failed to add membership rule: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !added {
+		retargetForm(w, "#membership-rule-form-container")
+		views.AddMembershipRuleForm(m, map[string]string{"": "This rule already exists."}).Render(r.Context(), w)
+		return
+	}
+	components.MembershipRuleRow(m).Render(r.Context(), w)
+}
+
+func handleRemoveMembershipRule(w http.ResponseWriter, r *http.Request) {
+	if core.Enforcer == nil {
+		http.Error(w, "Casbin enforcer not initialized", http.StatusInternalServerError)
+		return
+	}
+	if !core.HasPolicyType("p", core.MembershipRulePType) {
+		http.Error(w, fmt.Sprintf("The permissions model has no %q policy definition", core.MembershipRulePType), http.StatusBadRequest)
+		return
+	}
+	if _, err := core.Enforcer.RemoveNamedPolicy(core.MembershipRulePType, r.FormValue("logic"), r.FormValue("group"), r.FormValue("domain")); err != nil {
+		http.Error(w, "Failed to remove membership rule: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// groupAssignmentRule returns the role type and rule of a: "g" for global assignments,
+// "g2" for assignments within a server group.
+func groupAssignmentRule(a components.GroupAssignment) (string, []string) {
+	if a.Domain == "" {
+		return core.GroupPType, []string{a.Member, a.Group}
+	}
+	return core.DomainGroupPType, []string{a.Member, a.Group, a.Domain}
+}
+
+// retargetForm makes HTMX swap the response into the form container instead of the table,
+// to show validation errors next to the fields.
+func retargetForm(w http.ResponseWriter, container string) {
+	w.Header().Set("HX-Retarget", container)
+	w.Header().Set("HX-Reswap", "innerHTML")
+}
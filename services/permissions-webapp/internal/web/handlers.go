@@ -22,6 +22,11 @@ func RegisterRoutes(r *chi.Mux /*, log logr.Logger*/) {
 	r.Post("/policies", handleAddPolicy)
 	r.Post("/policies/remove", handleRemovePolicy) // Using POST for simplicity with hx-vals
 	r.Get("/policies/add-form", handleAddPolicyForm)
+	r.Get("/groups", handleListGroups)
+	r.Post("/groups/assignments", handleAddGroupAssignment)
+	r.Post("/groups/assignments/remove", handleRemoveGroupAssignment)
+	r.Post("/groups/rules", handleAddMembershipRule)
+	r.Post("/groups/rules/remove", handleRemoveMembershipRule)
 
 	// Serve static files (htmx.min.js)
 	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("./internal/static"))))
@@ -60,7 +65,7 @@ func handleListPolicies(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleAddPolicyForm(w http.ResponseWriter, r *http.Request) {
-	views.AddPolicyForm().Render(r.Context(), w)
+	views.AddPolicyForm(components.PolicyRule{}, nil).Render(r.Context(), w)
 }
 
 func handleAddPolicy(w http.ResponseWriter, r *http.Request) {
@@ -73,14 +78,10 @@ func handleAddPolicy(w http.ResponseWriter, r *http.Request) {
 	action := r.FormValue("action")
 	effect := r.FormValue("effect")
 
-	if subLogic == "" || objLogic == "" || action == "" || effect == "" {
-		// Send an error back, possibly as an HTMX out-of-band swap to a notification area
-		// For now, simple error
-		http.Error(w, "All policy fields are required", http.StatusBadRequest)
-		// You could also use OOB swap with HTMX:
-		// w.Header().Set("HX-Retarget", "#notifications")
-		// w.Header().Set("HX-Reswap", "innerHTML")
-		// fmt.Fprint(w, "<div class='error'>All fields required</div>")
+	if errs := core.ValidatePolicy(subLogic, objLogic, action, effect); errs != nil {
+		// Re-render the form with the errors next to their fields instead of appending a row
+		retargetForm(w, "#policy-form-container")
+		views.AddPolicyForm(components.PolicyRule{SubLogic: subLogic, ObjLogic: objLogic, Action: action, Effect: effect}, errs).Render(r.Context(), w)
 		return
 	}
 
@@ -100,11 +101,7 @@ func handleAddPolicy(w http.ResponseWriter, r *http.Request) {
 		return // Or an appropriate message
 	}
 
-	if err := core.Enforcer.SavePolicy(); err != nil {
-		http.Error(w, "Failed to save policy: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	core.PublishNatsPolicyUpdate() // You'll need this function in core/nats.go
+	// The adapter persists the rule and the watcher notifies the other replicas and the proxy.
 
 	// Return the new row as an HTML fragment for HTMX to append
 	components.PolicyRow(components.PolicyRule{SubLogic: subLogic, ObjLogic: objLogic, Action: action, Effect: effect}).Render(r.Context(), w)
@@ -135,11 +132,7 @@ func handleRemovePolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := core.Enforcer.SavePolicy(); err != nil {
-		http.Error(w, "Failed to save policy after removal: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	core.PublishNatsPolicyUpdate()
+	// The adapter persists the removal and the watcher notifies the other replicas and the proxy.
 
 	// HTMX expects an empty response on successful deletion if the target is removed
 	// Or, if you are replacing a section, return the new content for that section.
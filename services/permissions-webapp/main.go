@@ -1,10 +1,13 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/bafbi/minecraft-network/pkg/observability"
 	"github.com/bafbi/minecraft-network/services/permissions-webapp/internal/config"
 	"github.com/bafbi/minecraft-network/services/permissions-webapp/internal/core"
 	"github.com/bafbi/minecraft-network/services/permissions-webapp/internal/web"
@@ -13,22 +16,40 @@ import (
 )
 
 func main() {
+	logger := observability.SetupLogging("permissions-webapp")
+	shutdownTracing, err := observability.SetupTracing(context.Background(), "permissions-webapp", logger)
+	if err != nil {
+		fatal("Error setting up tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Error flushing traces", "error", err)
+		}
+	}()
 
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Error loading configuration: %v", err)
+		fatal("Error loading configuration", err)
 	}
 
 	if err := core.InitNats(cfg.NatsURL); err != nil {
-		log.Fatalf("Error initializing NATS: %v", err)
+		fatal("Error initializing NATS", err)
 	}
 	defer core.CloseNats()
 
 	if err := core.InitCasbin(cfg); err != nil { // Pass full cfg
-		log.Fatalf("Error initializing Casbin: %v", err)
+		fatal("Error initializing Casbin", err)
+	}
+
+	// Time-bound group assignments need the grants bucket; permanent ones work without it
+	if err := core.InitGrants(); err != nil {
+		slog.Error("Error initializing time-bound permission grants", "error", err)
 	}
 
 	r := chi.NewRouter()
+	r.Use(observability.HTTPMiddleware("/static/"))
 	r.Use(middleware.Logger)    // Basic request logging
 	r.Use(middleware.Recoverer) // Recover from panics
 
@@ -39,8 +60,14 @@ func main() {
 		port = "3001" // Default port for the webapp
 	}
 
-	log.Printf("Permissions webapp starting on port %s", port)
+	slog.Info("Permissions webapp starting", "port", port)
 	if err := http.ListenAndServe(":"+port, r); err != nil {
-		log.Fatalf("Error starting server: %v", err)
+		fatal("Error starting server", err)
 	}
 }
+
+// fatal logs err and exits, for failures that prevent the webapp from running.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}